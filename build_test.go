@@ -0,0 +1,118 @@
+package squadron_test
+
+import (
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"testing"
+
+	testingx "github.com/foomo/go/testing"
+	tagx "github.com/foomo/go/testing/tag"
+	"github.com/foomo/squadron"
+	"github.com/foomo/squadron/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDocker logs the tags of `buildx build` to `BUILD_LOG`, fails for the tag in `BUILD_FAIL` and
+// writes the test digest to the metadata file, images are missing if `IMAGE_MISSING` is set
+const fakeDocker = `#!/bin/sh
+if [ "$1" = "image" ]; then
+  test -z "$IMAGE_MISSING"
+  exit
+elif [ "$1" != "buildx" ]; then
+  exit 0
+fi
+while [ $# -gt 0 ]; do
+  case "$1" in
+  --tag) tag="$2" ;;
+  --metadata-file) metadata="$2" ;;
+  esac
+  shift
+done
+echo "build $tag" >> "$BUILD_LOG"
+[ "$tag" != "$BUILD_FAIL" ] || exit 1
+echo '{"containerimage.digest": "` + testDigest + `"}' > "$metadata"
+`
+
+func TestSquadron_Build(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+	t.Setenv("PROJECT_ROOT", ".")
+
+	bin := t.TempDir()
+	require.NoError(t, os.WriteFile(path.Join(bin, "docker"), []byte(fakeDocker), 0700)) //nolint:gosec
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("GIT_DIR", newGitRepo(t))
+	t.Setenv("SQUADRON_CACHE_DIR", t.TempDir())
+
+	var cwd string
+
+	ctx := t.Context()
+	require.NoError(t, util.ValidatePath(".", &cwd))
+
+	build := func(t *testing.T, fail string) (*squadron.Squadron, []string, error) {
+		t.Helper()
+
+		log := path.Join(t.TempDir(), "build.log")
+		t.Setenv("BUILD_LOG", log)
+		t.Setenv("BUILD_FAIL", fail)
+
+		sq := squadron.New(cwd, "", []string{path.Join("testdata", "buildhooks", "squadron.yaml")}, squadron.WithObserver(squadron.ObserverFunc(func(event squadron.Event) {})))
+		require.NoError(t, sq.MergeConfigFiles(ctx))
+		require.NoError(t, sq.RenderConfig(ctx))
+
+		_, err := sq.Build(ctx, nil, 1)
+
+		data, _ := os.ReadFile(log)
+
+		return sq, strings.FieldsFunc(string(data), func(r rune) bool { return r == '\n' }), err
+	}
+
+	t.Run("failed", func(t *testing.T) {
+		_, lines, err := build(t, "backend:latest")
+		require.Error(t, err)
+		assert.Equal(t, []string{"pre backend", "build backend:latest"}, lines)
+	})
+
+	t.Run("built", func(t *testing.T) {
+		sq, lines, err := build(t, "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"pre backend", "build backend:latest", "post backend"}, lines)
+		assert.Equal(t, map[string]string{"backend:latest": testDigest}, sq.Digests())
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		sq, lines, err := build(t, "")
+		require.NoError(t, err)
+		assert.Empty(t, lines)
+		assert.Equal(t, map[string]string{"backend:latest": testDigest}, sq.Digests())
+	})
+
+	t.Run("pruned", func(t *testing.T) {
+		t.Setenv("IMAGE_MISSING", "1")
+
+		_, lines, err := build(t, "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"pre backend", "build backend:latest", "post backend"}, lines)
+	})
+}
+
+// newGitRepo returns a git repository with an origin and a single commit
+func newGitRepo(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"remote", "add", "origin", "https://github.com/foomo/squadron.git"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "init"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	return dir
+}
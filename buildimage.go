@@ -0,0 +1,194 @@
+package squadron
+
+import (
+	"encoding/json"
+	"maps"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/foomo/squadron/config"
+	templatex "github.com/foomo/squadron/internal/template"
+	"github.com/pkg/errors"
+)
+
+// BuildImage is the image of a build exposed as `.Builds.<name>` to the templates
+type BuildImage struct {
+	// Image name of the first tag without the tag, e.g. `docker.mycompany.com/mycompany/backend`
+	Image string `json:"image" yaml:"image"`
+	// Tag of the first tag, e.g. `latest`
+	Tag string `json:"tag,omitempty" yaml:"tag,omitempty"`
+	// Digest of the built or pushed image, empty until the image has been built
+	Digest string `json:"digest,omitempty" yaml:"digest,omitempty"`
+	// Ref references the image by digest if known and by tag otherwise
+	Ref string `json:"ref" yaml:"ref"`
+}
+
+var pushDigestRegex = regexp.MustCompile(`digest: (sha256:[0-9a-f]{64})`)
+
+// ------------------------------------------------------------------------------------------------
+// ~ Constructor
+// ------------------------------------------------------------------------------------------------
+
+// NewBuildImage returns the image of the first of the given tags
+func NewBuildImage(tags []string, digest string) BuildImage {
+	var ret BuildImage
+
+	if len(tags) == 0 {
+		return ret
+	}
+
+	ret.Image = tags[0]
+	ret.Digest = digest
+
+	// the image name may contain a registry port but no tag after the last slash
+	if i := strings.LastIndex(tags[0], ":"); i > strings.LastIndex(tags[0], "/") {
+		ret.Image, ret.Tag = tags[0][:i], tags[0][i+1:]
+	}
+
+	switch {
+	case ret.Digest != "":
+		ret.Ref = ret.Image + "@" + ret.Digest
+	case ret.Tag != "":
+		ret.Ref = ret.Image + ":" + ret.Tag
+	default:
+		ret.Ref = ret.Image
+	}
+
+	return ret
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public methods
+// ------------------------------------------------------------------------------------------------
+
+// Digests returns the image digests captured from builds, bakes and pushes by tag
+func (sq *Squadron) Digests() map[string]string {
+	sq.lock.Lock()
+	defer sq.lock.Unlock()
+
+	return maps.Clone(sq.digests)
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private methods
+// ------------------------------------------------------------------------------------------------
+
+// setDigest records the digest of the given image tags
+func (sq *Squadron) setDigest(digest string, tags ...string) {
+	if digest == "" {
+		return
+	}
+
+	sq.lock.Lock()
+	defer sq.lock.Unlock()
+
+	if sq.digests == nil {
+		sq.digests = map[string]string{}
+	}
+
+	for _, tag := range tags {
+		sq.digests[tag] = digest
+	}
+}
+
+// buildImages returns the images of the given builds and bakes by name, builds taking precedence
+func (sq *Squadron) buildImages(builds map[string]config.Build, bakes map[string]config.BakeTarget) map[string]BuildImage {
+	sq.lock.Lock()
+	defer sq.lock.Unlock()
+
+	ret := map[string]BuildImage{}
+
+	for name, bake := range bakes {
+		if len(bake.Tags) > 0 {
+			ret[name] = NewBuildImage(bake.Tags, sq.digests[bake.Tags[0]])
+		}
+	}
+
+	for name, build := range builds {
+		if len(build.Tag) > 0 {
+			ret[name] = NewBuildImage(build.Tag, sq.digests[build.Tag[0]])
+		}
+	}
+
+	return ret
+}
+
+// withBuilds returns a copy of the template vars exposing the given images as `.Builds`
+func (sq *Squadron) withBuilds(tv templatex.Vars, images ...map[string]BuildImage) templatex.Vars {
+	builds := map[string]BuildImage{}
+	for _, value := range images {
+		maps.Copy(builds, value)
+	}
+
+	ret := maps.Clone(tv)
+	ret.Add("Builds", builds)
+
+	return ret
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private functions
+// ------------------------------------------------------------------------------------------------
+
+// readBuildMetadata returns the image digest of a buildx metadata file
+func readBuildMetadata(filename string) (string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read build metadata")
+	}
+
+	var metadata struct {
+		Digest string `json:"containerimage.digest"`
+	}
+
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return "", errors.Wrap(err, "failed to unmarshal build metadata")
+	}
+
+	return metadata.Digest, nil
+}
+
+// readBakeMetadata returns the image digests of a buildx bake metadata file by tag
+func readBakeMetadata(filename string) (map[string]string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read bake metadata")
+	}
+
+	var targets map[string]json.RawMessage
+	if err := json.Unmarshal(data, &targets); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal bake metadata")
+	}
+
+	ret := map[string]string{}
+
+	for _, value := range targets {
+		var metadata struct {
+			Digest string `json:"containerimage.digest"`
+			Name   string `json:"image.name"`
+		}
+
+		// skip entries like the build warnings
+		if err := json.Unmarshal(value, &metadata); err != nil || metadata.Digest == "" {
+			continue
+		}
+
+		for tag := range strings.SplitSeq(metadata.Name, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				ret[tag] = metadata.Digest
+			}
+		}
+	}
+
+	return ret, nil
+}
+
+// pushDigest returns the digest printed by `docker push`
+func pushDigest(out string) string {
+	if match := pushDigestRegex.FindStringSubmatch(out); len(match) > 1 {
+		return match[1]
+	}
+
+	return ""
+}
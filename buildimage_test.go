@@ -0,0 +1,84 @@
+package squadron_test
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	testingx "github.com/foomo/go/testing"
+	tagx "github.com/foomo/go/testing/tag"
+	"github.com/foomo/squadron"
+	"github.com/foomo/squadron/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func TestNewBuildImage(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	assert.Equal(t, squadron.BuildImage{
+		Image: "localhost:5000/app",
+		Tag:   "1.0",
+		Ref:   "localhost:5000/app:1.0",
+	}, squadron.NewBuildImage([]string{"localhost:5000/app:1.0", "app:latest"}, ""))
+
+	assert.Equal(t, squadron.BuildImage{
+		Image:  "localhost:5000/app",
+		Digest: testDigest,
+		Ref:    "localhost:5000/app@" + testDigest,
+	}, squadron.NewBuildImage([]string{"localhost:5000/app"}, testDigest))
+
+	assert.Equal(t, squadron.BuildImage{}, squadron.NewBuildImage(nil, testDigest))
+}
+
+func TestSquadron_RenderConfig_buildImages(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+	t.Setenv("PROJECT_ROOT", ".")
+
+	var cwd string
+
+	ctx := t.Context()
+	require.NoError(t, util.ValidatePath(".", &cwd))
+
+	files := []string{path.Join("testdata", "buildimages", "squadron.yaml")}
+	image := "docker.mycompany.com:5000/mycompany/backend"
+
+	t.Run("tag", func(t *testing.T) {
+		sq := squadron.New(cwd, "default", files)
+		require.NoError(t, sq.MergeConfigFiles(ctx))
+		require.NoError(t, sq.RenderConfig(ctx))
+
+		values := sq.Config().Squadrons["site"]["backend"].Values
+		assert.Equal(t, "docker.mycompany.com:5000/mycompany/base", values["base"])
+		assert.Equal(t, map[string]any{"repository": image, "tag": "1.0.0", "ref": image + ":1.0.0"}, values["image"])
+	})
+
+	t.Run("digests", func(t *testing.T) {
+		sq := squadron.New(cwd, "default", files, squadron.WithDigests(map[string]string{image + ":1.0.0": testDigest}))
+		require.NoError(t, sq.MergeConfigFiles(ctx))
+		require.NoError(t, sq.RenderConfig(ctx))
+
+		values := sq.Config().Squadrons["site"]["backend"].Values
+		assert.Equal(t, map[string]any{"repository": image, "tag": "1.0.0", "ref": image + "@" + testDigest}, values["image"])
+	})
+
+	t.Run("push", func(t *testing.T) {
+		bin := t.TempDir()
+		require.NoError(t, os.WriteFile(path.Join(bin, "docker"), []byte("#!/bin/sh\necho \"1.0.0: digest: "+testDigest+" size: 1234\"\n"), 0700)) //nolint:gosec
+		t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+		sq := squadron.New(cwd, "default", files, squadron.WithObserver(squadron.ObserverFunc(func(event squadron.Event) {})))
+		require.NoError(t, sq.MergeConfigFiles(ctx))
+		require.NoError(t, sq.RenderConfig(ctx))
+
+		_, err := sq.Push(ctx, nil, 1)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{image + ":1.0.0": testDigest}, sq.Digests())
+
+		values := sq.Config().Squadrons["site"]["backend"].Values
+		assert.Equal(t, map[string]any{"repository": image, "tag": "1.0.0", "ref": image + "@" + testDigest}, values["image"])
+		assert.Equal(t, []string{image + ":1.0.0"}, sq.Config().Squadrons["site"]["backend"].Builds["default"].Tag)
+	})
+}
@@ -0,0 +1,221 @@
+package squadron
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/foomo/squadron/dag"
+	"github.com/foomo/squadron/internal/util"
+	"github.com/pkg/errors"
+)
+
+// BuildHashLabel image label holding the content hash of builds with `skipUnchanged`
+const BuildHashLabel = "org.foomo.squadron.hash"
+
+// buildState records the content hashes and digests of the built images by tag
+type buildState struct {
+	lock     sync.Mutex
+	filename string
+	images   map[string]buildStateImage
+}
+
+// buildStateImage is the state of a built image
+type buildStateImage struct {
+	Hash   string `json:"hash"`
+	Digest string `json:"digest,omitempty"`
+}
+
+// buildHashes computes the content hashes of the builds of a graph including their dependencies
+type buildHashes struct {
+	sq     *Squadron
+	graph  *dag.Graph
+	lock   sync.Mutex
+	hashes map[string]string
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public functions
+// ------------------------------------------------------------------------------------------------
+
+// BuildStateFilename returns the file recording the content hashes and digests of the built images
+func BuildStateFilename() (string, error) {
+	if value := os.Getenv("SQUADRON_CACHE_DIR"); value != "" {
+		return path.Join(value, "builds.json"), nil
+	}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to determine cache dir")
+	}
+
+	return path.Join(dir, "squadron", "builds.json"), nil
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public methods
+// ------------------------------------------------------------------------------------------------
+
+// UnmarshalJSON also accepts the plain hashes of previous state files
+func (i *buildStateImage) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &i.Hash)
+	}
+
+	type wrapper buildStateImage
+
+	return json.Unmarshal(data, (*wrapper)(i))
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private methods
+// ------------------------------------------------------------------------------------------------
+
+// get returns the hash of the given build graph node
+func (h *buildHashes) get(id string) (string, error) {
+	h.lock.Lock()
+	value, ok := h.hashes[id]
+	h.lock.Unlock()
+
+	if ok {
+		return value, nil
+	}
+
+	dependencies := h.graph.Dependencies(id)
+	for i, dependency := range dependencies {
+		hash, err := h.get(dependency)
+		if err != nil {
+			return "", err
+		}
+
+		dependencies[i] = hash
+	}
+
+	_, _, _, build := h.sq.graphBuild(id)
+
+	ret, err := build.Hash(dependencies...)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to hash build `%s`", id)
+	}
+
+	h.lock.Lock()
+	h.hashes[id] = ret
+	h.lock.Unlock()
+
+	return ret, nil
+}
+
+// unchanged returns true if the image still exists locally and has been built with the given hash
+// before according to the state file or the label of the image
+func (s *buildState) unchanged(ctx context.Context, image, hash string) bool {
+	// the image is required for subsequent pushes, e.g. after a `docker image prune`
+	out, err := util.NewDockerCommand().ImageLabel(image, BuildHashLabel).Run(ctx)
+	if err != nil {
+		return false
+	}
+
+	s.lock.Lock()
+	value, ok := s.images[image]
+	s.lock.Unlock()
+
+	if ok && value.Hash == hash {
+		return true
+	}
+
+	return strings.TrimSpace(out) == hash
+}
+
+// digest returns the digest recorded for the image built with the given hash, falling back to the
+// repository digest of the local image
+func (s *buildState) digest(ctx context.Context, image, hash string) string {
+	s.lock.Lock()
+	value, ok := s.images[image]
+	s.lock.Unlock()
+
+	if ok && value.Hash == hash && value.Digest != "" {
+		return value.Digest
+	}
+
+	out, err := util.NewDockerCommand().ImageRepoDigests(image).Run(ctx)
+	if err != nil {
+		return ""
+	}
+
+	repository := NewBuildImage([]string{image}, "").Image
+	for line := range strings.Lines(out) {
+		if digest, ok := strings.CutPrefix(strings.TrimSpace(line), repository+"@"); ok {
+			return digest
+		}
+	}
+
+	return ""
+}
+
+func (s *buildState) set(image, hash, digest string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.images[image] = buildStateImage{Hash: hash, Digest: digest}
+}
+
+// save writes the state file
+func (s *buildState) save() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.filename == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s.images, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal build state")
+	}
+
+	if err := os.MkdirAll(path.Dir(s.filename), 0700); err != nil {
+		return errors.Wrap(err, "failed to create build state dir")
+	}
+
+	tmp := s.filename + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return errors.Wrap(err, "failed to write build state")
+	}
+
+	return errors.Wrap(os.Rename(tmp, s.filename), "failed to write build state")
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private functions
+// ------------------------------------------------------------------------------------------------
+
+func newBuildHashes(sq *Squadron, graph *dag.Graph) *buildHashes {
+	return &buildHashes{
+		sq:     sq,
+		graph:  graph,
+		hashes: map[string]string{},
+	}
+}
+
+// loadBuildState reads the state file, a missing file is an empty state
+func loadBuildState(filename string) (*buildState, error) {
+	ret := &buildState{
+		filename: filename,
+		images:   map[string]buildStateImage{},
+	}
+
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return ret, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to read build state")
+	}
+
+	if err := json.Unmarshal(data, &ret.images); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal build state")
+	}
+
+	return ret, nil
+}
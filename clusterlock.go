@@ -0,0 +1,294 @@
+package squadron
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/foomo/squadron/config"
+	"github.com/foomo/squadron/internal/util"
+	"github.com/pkg/errors"
+	"github.com/pterm/pterm"
+)
+
+const (
+	lockKeyHolder   = "holder"
+	lockKeyStatus   = "status"
+	lockKeyAcquired = "acquired"
+	lockKeyExpires  = "expires"
+)
+
+// DefaultLockTTL after which cluster locks of crashed runs may be replaced
+const DefaultLockTTL = time.Hour
+
+type clusterLock struct {
+	name      string
+	namespace string
+	warn      func(message string)
+}
+
+// lease describes a cluster lock held by a run
+type lease struct {
+	holder  string
+	status  string
+	since   string
+	expires time.Time
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public methods
+// ------------------------------------------------------------------------------------------------
+
+// Lock acquires a ConfigMap lease per squadron in every namespace of its units and returns a
+// function to release them again. Every call holds the leases under a unique id until they are
+// released or expire after the ttl, if any. Leases held by others are replaced only if force is set.
+func (sq *Squadron) Lock(ctx context.Context, status Status, ttl time.Duration, force bool) (func(ctx context.Context) error, error) {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return nil, err
+	}
+
+	holder, err := newLockHolder()
+	if err != nil {
+		return nil, err
+	}
+
+	value := lease{holder: holder, status: string(data)}
+	if ttl > 0 {
+		value.expires = time.Now().Add(ttl)
+	}
+
+	locks, err := sq.clusterLocks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var acquired []clusterLock
+
+	unlock := func(ctx context.Context) error {
+		var ret error
+
+		for _, l := range acquired {
+			if err := l.release(ctx, holder); err != nil && ret == nil {
+				ret = err
+			}
+		}
+
+		return ret
+	}
+
+	for _, l := range locks {
+		if err := l.acquire(ctx, value, force); err != nil {
+			if e := unlock(ctx); e != nil {
+				sq.warn(OperationUp, e.Error())
+			}
+
+			return nil, err
+		}
+
+		acquired = append(acquired, l)
+	}
+
+	return unlock, nil
+}
+
+// CheckLocks returns an error if any cluster lock of the squadrons is held, expired locks are
+// ignored
+func (sq *Squadron) CheckLocks(ctx context.Context) error {
+	locks, err := sq.clusterLocks(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, l := range locks {
+		current, ok, err := l.current(ctx)
+		if err != nil {
+			return err
+		} else if !ok {
+			continue
+		}
+
+		if current.expired() {
+			l.warn(fmt.Sprintf("Ignoring expired lock %s in namespace %s held by %s", l.name, l.namespace, current))
+			continue
+		}
+
+		return errors.Errorf("lock `%s` in namespace `%s` is held by %s, use --force-unlock to override", l.name, l.namespace, current)
+	}
+
+	return nil
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private methods
+// ------------------------------------------------------------------------------------------------
+
+// clusterLocks returns the unique locks of all squadrons and unit namespaces
+func (sq *Squadron) clusterLocks(ctx context.Context) ([]clusterLock, error) {
+	var ret []clusterLock
+
+	err := sq.c.Squadrons.Iterate(ctx, func(ctx context.Context, key string, value config.Map[*config.Unit]) error {
+		return value.Iterate(ctx, func(ctx context.Context, k string, v *config.Unit) error {
+			namespace, err := sq.Namespace(ctx, key, k, v)
+			if err != nil {
+				return err
+			}
+
+			l := clusterLock{name: "squadron-lock-" + key, namespace: namespace}
+			if !slices.ContainsFunc(ret, func(other clusterLock) bool { return other.name == l.name && other.namespace == l.namespace }) {
+				l.warn = func(message string) { sq.warn(OperationUp, message) }
+				ret = append(ret, l)
+			}
+
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(ret, func(a, b clusterLock) int {
+		return strings.Compare(a.namespace+"/"+a.name, b.namespace+"/"+b.name)
+	})
+
+	return ret, nil
+}
+
+func (l clusterLock) acquire(ctx context.Context, value lease, force bool) error {
+	pterm.Debug.Printfln("acquiring lock %s in namespace %s", l.name, l.namespace)
+
+	out, err := l.create(ctx, value)
+	if err == nil {
+		return nil
+	} else if !strings.Contains(out, "AlreadyExists") {
+		return errors.Wrapf(err, "failed to acquire lock `%s` in namespace `%s`: %s", l.name, l.namespace, out)
+	}
+
+	current, ok, err := l.current(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case !ok:
+		// released in the meantime
+	case current.expired():
+		l.warn(fmt.Sprintf("Replacing expired lock %s in namespace %s held by %s", l.name, l.namespace, current))
+	case !force:
+		return errors.Errorf("lock `%s` in namespace `%s` is held by %s, use --force-unlock to override", l.name, l.namespace, current)
+	default:
+		l.warn(fmt.Sprintf("Force unlocking %s in namespace %s held by %s", l.name, l.namespace, current))
+	}
+
+	if out, err := util.NewKubeCommand().Namespace(l.namespace).DeleteConfigMap(ctx, l.name); err != nil && !strings.Contains(out, "NotFound") {
+		return errors.Wrapf(err, "failed to force unlock `%s` in namespace `%s`: %s", l.name, l.namespace, out)
+	}
+
+	if out, err := l.create(ctx, value); err != nil {
+		return errors.Wrapf(err, "failed to acquire lock `%s` in namespace `%s`: %s", l.name, l.namespace, out)
+	}
+
+	return nil
+}
+
+// current returns the lease of the lock if it is held
+func (l clusterLock) current(ctx context.Context) (lease, bool, error) {
+	var ret lease
+
+	kube := util.NewKubeCommand().Namespace(l.namespace)
+
+	holder, err := kube.GetConfigMapKey(ctx, l.name, lockKeyHolder)
+	if err != nil {
+		if strings.Contains(holder, "NotFound") {
+			return ret, false, nil
+		}
+
+		return ret, false, errors.Wrapf(err, "failed to read lock `%s` in namespace `%s`: %s", l.name, l.namespace, holder)
+	}
+
+	ret.holder = holder
+
+	if value, err := kube.GetConfigMapKey(ctx, l.name, lockKeyStatus); err == nil {
+		ret.status = value
+	}
+
+	if value, err := kube.GetConfigMapKey(ctx, l.name, lockKeyAcquired); err == nil {
+		ret.since = value
+	}
+
+	if value, err := kube.GetConfigMapKey(ctx, l.name, lockKeyExpires); err == nil {
+		ret.expires, _ = time.Parse(time.RFC3339, value)
+	}
+
+	return ret, true, nil
+}
+
+// release deletes the lock unless it has been taken over by another holder in the meantime
+func (l clusterLock) release(ctx context.Context, holder string) error {
+	pterm.Debug.Printfln("releasing lock %s in namespace %s", l.name, l.namespace)
+
+	current, ok, err := l.current(ctx)
+	if err != nil {
+		return err
+	} else if !ok {
+		return nil
+	} else if current.holder != holder {
+		l.warn(fmt.Sprintf("Skipping release of %s in namespace %s taken over by another holder", l.name, l.namespace))
+		return nil
+	}
+
+	if out, err := util.NewKubeCommand().Namespace(l.namespace).DeleteConfigMap(ctx, l.name); err != nil {
+		return errors.Wrapf(err, "failed to release lock `%s` in namespace `%s`: %s", l.name, l.namespace, out)
+	}
+
+	return nil
+}
+
+func (l clusterLock) create(ctx context.Context, value lease) (string, error) {
+	return util.NewKubeCommand().Namespace(l.namespace).CreateConfigMap(ctx, l.name, map[string]string{
+		lockKeyHolder:   value.holder,
+		lockKeyStatus:   value.status,
+		lockKeyAcquired: time.Now().UTC().Format(time.RFC3339),
+		lockKeyExpires:  value.expires.UTC().Format(time.RFC3339),
+	})
+}
+
+// expired returns true if the lease has an expiry in the past
+func (l lease) expired() bool {
+	return !l.expires.IsZero() && time.Now().After(l.expires)
+}
+
+// String returns the status of the holder
+func (l lease) String() string {
+	ret := "unknown holder"
+
+	var status Status
+	if err := json.Unmarshal([]byte(l.status), &status); err == nil {
+		ret = status.String()
+	}
+
+	if l.since != "" {
+		ret += " since " + l.since
+	}
+
+	return ret
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private functions
+// ------------------------------------------------------------------------------------------------
+
+// newLockHolder returns a random id identifying the locks of a single run
+func newLockHolder() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "failed to generate lock holder")
+	}
+
+	return hex.EncodeToString(b), nil
+}
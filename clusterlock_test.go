@@ -0,0 +1,127 @@
+package squadron_test
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	testingx "github.com/foomo/go/testing"
+	tagx "github.com/foomo/go/testing/tag"
+	"github.com/foomo/squadron"
+	"github.com/foomo/squadron/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKubectl stores config maps as directories in `KUBECTL_STORE`
+const fakeKubectl = `#!/bin/sh
+dir="$KUBECTL_STORE/$2"
+shift 2
+mkdir -p "$dir"
+case "$1" in
+create)
+  name="$3"
+  shift 3
+  if [ -d "$dir/$name" ]; then
+    echo "Error from server (AlreadyExists): configmaps \"$name\" already exists" >&2
+    exit 1
+  fi
+  mkdir "$dir/$name"
+  for arg in "$@"; do
+    kv="${arg#--from-literal=}"
+    printf '%s' "${kv#*=}" > "$dir/$name/${kv%%=*}"
+  done
+  ;;
+get)
+  key="${5#jsonpath=\{.data.}"
+  if [ ! -d "$dir/$3" ]; then
+    echo "Error from server (NotFound): configmaps \"$3\" not found" >&2
+    exit 1
+  fi
+  cat "$dir/$3/${key%\}}"
+  ;;
+delete)
+  rm -rf "$dir/$3"
+  ;;
+esac
+`
+
+func TestSquadron_Lock(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+	t.Setenv("PROJECT_ROOT", ".")
+
+	bin := t.TempDir()
+	store := t.TempDir()
+	require.NoError(t, os.WriteFile(path.Join(bin, "kubectl"), []byte(fakeKubectl), 0700)) //nolint:gosec
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("KUBECTL_STORE", store)
+
+	var cwd string
+
+	ctx := t.Context()
+	require.NoError(t, util.ValidatePath(".", &cwd))
+
+	sq := squadron.New(cwd, "demo", []string{path.Join("testdata", "simple", "squadron.yaml")})
+	require.NoError(t, sq.MergeConfigFiles(ctx))
+
+	alice := squadron.Status{User: "alice", Branch: "main", Commit: "abc"}
+	bob := squadron.Status{User: "bob", Branch: "feature"}
+	lock := path.Join(store, "demo", "squadron-lock-storefinder")
+
+	require.NoError(t, sq.CheckLocks(ctx))
+
+	aliceUnlock, err := sq.Lock(ctx, alice, time.Hour, false)
+	require.NoError(t, err)
+	assert.DirExists(t, lock)
+
+	t.Run("locked", func(t *testing.T) {
+		_, err := sq.Lock(ctx, bob, time.Hour, false)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "lock `squadron-lock-storefinder` in namespace `demo` is held by alice (branch: main, commit: abc) since ")
+		assert.Contains(t, err.Error(), "use --force-unlock to override")
+
+		require.ErrorContains(t, sq.CheckLocks(ctx), "is held by alice")
+	})
+
+	t.Run("same user", func(t *testing.T) {
+		_, err := sq.Lock(ctx, alice, time.Hour, false)
+		require.ErrorContains(t, err, "is held by alice")
+	})
+
+	t.Run("force unlock", func(t *testing.T) {
+		unlock, err := sq.Lock(ctx, bob, time.Hour, true)
+		require.NoError(t, err)
+
+		status, err := os.ReadFile(path.Join(lock, "status"))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"user":"bob","branch":"feature"}`, string(status))
+
+		// the previous holder must not release the taken over lock
+		require.NoError(t, aliceUnlock(ctx))
+		assert.DirExists(t, lock)
+
+		require.NoError(t, unlock(ctx))
+		assert.NoDirExists(t, lock)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		_, err := sq.Lock(ctx, alice, time.Nanosecond, false)
+		require.NoError(t, err)
+
+		// wait for the expiry with second precision
+		time.Sleep(1100 * time.Millisecond)
+		require.NoError(t, sq.CheckLocks(ctx))
+
+		unlock, err := sq.Lock(ctx, bob, time.Hour, false)
+		require.NoError(t, err)
+		require.NoError(t, unlock(ctx))
+	})
+
+	t.Run("released", func(t *testing.T) {
+		unlock, err := sq.Lock(ctx, bob, 0, false)
+		require.NoError(t, err)
+		require.NoError(t, unlock(ctx))
+		require.NoError(t, sq.CheckLocks(ctx))
+	})
+}
@@ -2,10 +2,20 @@ package config
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 
+	"github.com/foomo/squadron/internal/dockerignore"
 	"github.com/foomo/squadron/internal/qflag"
 	"github.com/foomo/squadron/internal/util"
+	"github.com/pkg/errors"
 	"github.com/pterm/pterm"
 	"github.com/spf13/pflag"
 )
@@ -15,6 +25,8 @@ type Build struct {
 	Context string `json:"context,omitempty" yaml:"context,omitempty"`
 	// Dependencies list of build names defined in the squadron configuration
 	Dependencies []string `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
+	// SkipUnchanged skip the build if an image with the same content hash has been built before
+	SkipUnchanged bool `json:"skipUnchanged,omitempty" yaml:"skipUnchanged,omitempty"`
 
 	// AddHost add a custom host-to-IP mapping (format: "host:ip")
 	AddHost []string `json:"addHost,omitempty" yaml:"addHost,omitempty"`
@@ -147,3 +159,273 @@ func (b *Build) Build(ctx context.Context, squadron, unit string, args []string)
 		Args(qflag.Parse(f)...).
 		Run(ctx)
 }
+
+// Hash returns the content hash of the build over the files of the build context not ignored by
+// its `.dockerignore`, the Dockerfile, the build args, platforms and target and the given hashes of
+// its dependencies
+func (b *Build) Hash(dependencies ...string) (string, error) {
+	dir := b.Context
+	if dir == "" {
+		dir = "."
+	}
+
+	if strings.Contains(dir, "://") || dir == "-" {
+		return "", errors.Errorf("unsupported build context `%s`", dir)
+	}
+
+	ignore, err := dockerignore.Load(dir)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+
+	if err := filepath.WalkDir(dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, name)
+		if err != nil || rel == "." {
+			return err
+		}
+
+		rel = filepath.ToSlash(rel)
+
+		if ignore.Matches(rel) {
+			if d.IsDir() && !ignore.Exclusions() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		switch {
+		case d.IsDir():
+			return nil
+		case d.Type()&fs.ModeSymlink != 0:
+			target, err := os.Readlink(name)
+			if err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintf(h, "link %s %s\n", rel, target)
+
+			return nil
+		case !d.Type().IsRegular():
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		sum, err := hashFile(name)
+		if err != nil {
+			return err
+		}
+
+		_, _ = fmt.Fprintf(h, "file %s %t %s\n", rel, info.Mode().Perm()&0o111 != 0, sum)
+
+		return nil
+	}); err != nil {
+		return "", errors.Wrapf(err, "failed to hash build context `%s`", dir)
+	}
+
+	dockerfile := b.File
+	if dockerfile == "" {
+		dockerfile = "Dockerfile"
+	}
+
+	// docker runs in the build context
+	if !filepath.IsAbs(dockerfile) {
+		dockerfile = filepath.Join(dir, dockerfile)
+	}
+
+	if sum, err := hashFile(dockerfile); err == nil {
+		_, _ = fmt.Fprintf(h, "dockerfile %s\n", sum)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", errors.Wrapf(err, "failed to hash dockerfile `%s`", dockerfile)
+	}
+
+	for _, values := range []struct {
+		name   string
+		values []string
+	}{
+		{name: "arg", values: b.BuildArg},
+		{name: "platform", values: b.Platform},
+		{name: "target", values: []string{b.Target}},
+		{name: "dependency", values: dependencies},
+	} {
+		items := slices.Clone(values.values)
+		slices.Sort(items)
+
+		for _, item := range items {
+			_, _ = fmt.Fprintf(h, "%s %q\n", values.name, item)
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// BakeTarget converts the build into a `docker buildx bake` target with the given name
+func (b *Build) BakeTarget(name string) BakeTarget {
+	ret := BakeTarget{
+		Name:          name,
+		Context:       b.Context,
+		Dockerfile:    b.File,
+		Annotations:   b.Annotation,
+		Tags:          b.Tag,
+		Platforms:     b.Platform,
+		Target:        b.Target,
+		Pull:          b.Pull,
+		NoCache:       b.NoCache,
+		NoCacheFilter: b.NoCacheFilter,
+		NetworkMode:   b.Network,
+		ShmSize:       b.ShmSize,
+		Call:          b.Call,
+		Entitlements:  b.Allow,
+	}
+
+	if ret.Context == "" {
+		ret.Context = "."
+	}
+
+	if b.Check {
+		ret.Call = "check"
+	}
+
+	if b.ULimit != "" {
+		ret.Ulimits = []string{b.ULimit}
+	}
+
+	for _, value := range b.BuildArg {
+		k, v, ok := strings.Cut(value, "=")
+		if !ok {
+			// like docker, args without a value are taken from the environment
+			if v, ok = os.LookupEnv(k); !ok {
+				continue
+			}
+		}
+
+		ret.Args = setValue(ret.Args, k, v)
+	}
+
+	for _, value := range b.Label {
+		k, v, _ := strings.Cut(value, "=")
+		ret.Labels = setValue(ret.Labels, k, v)
+	}
+
+	for _, value := range b.BuildContext {
+		if k, v, ok := strings.Cut(value, "="); ok {
+			ret.Contexts = setValue(ret.Contexts, k, v)
+		}
+	}
+
+	for _, value := range b.AddHost {
+		k, v, ok := strings.Cut(value, "=")
+		if !ok {
+			k, v, _ = strings.Cut(value, ":")
+		}
+
+		ret.ExtraHosts = setValue(ret.ExtraHosts, k, v)
+	}
+
+	for _, value := range b.Attest {
+		ret.Attest = append(ret.Attest, stringToAnyMap(value))
+	}
+
+	if b.Provenance {
+		ret.Attest = append(ret.Attest, map[string]any{"type": "provenance"})
+	}
+
+	if b.Sbom {
+		ret.Attest = append(ret.Attest, map[string]any{"type": "sbom"})
+	}
+
+	for _, value := range b.CacheFrom {
+		ret.CacheFrom = append(ret.CacheFrom, cacheOption(value))
+	}
+
+	for _, value := range b.CacheTo {
+		ret.CacheTo = append(ret.CacheTo, cacheOption(value))
+	}
+
+	for _, value := range b.Secret {
+		ret.Secret = append(ret.Secret, stringToAnyMap(value))
+	}
+
+	for _, value := range b.SSH {
+		id, paths, ok := strings.Cut(value, "=")
+
+		item := map[string]any{"id": id}
+		if ok {
+			item["paths"] = strings.Split(paths, ",")
+		}
+
+		ret.SSH = append(ret.SSH, item)
+	}
+
+	for _, value := range b.Output {
+		ret.Outputs = append(ret.Outputs, stringToAnyMap(value))
+	}
+
+	if b.Push {
+		ret.Outputs = append(ret.Outputs, map[string]any{"type": "registry"})
+	}
+
+	if b.Load {
+		ret.Outputs = append(ret.Outputs, map[string]any{"type": "docker"})
+	}
+
+	return ret
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private functions
+// ------------------------------------------------------------------------------------------------
+
+func setValue(m map[string]string, key, value string) map[string]string {
+	if m == nil {
+		m = map[string]string{}
+	}
+
+	m[key] = value
+
+	return m
+}
+
+// stringToAnyMap parses comma separated `key=value` pairs
+func stringToAnyMap(value string) map[string]any {
+	ret := map[string]any{}
+	for k, v := range util.StringToMap(value) {
+		ret[k] = v
+	}
+
+	return ret
+}
+
+// cacheOption parses a cache option where a plain value is a registry reference
+func cacheOption(value string) map[string]string {
+	if !strings.Contains(value, "=") {
+		return map[string]string{"type": "registry", "ref": value}
+	}
+
+	return util.StringToMap(value)
+}
+
+func hashFile(name string) (string, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,104 @@
+package config_test
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	testingx "github.com/foomo/go/testing"
+	tagx "github.com/foomo/go/testing/tag"
+	"github.com/foomo/squadron/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuild_Hash(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		require.NoError(t, os.MkdirAll(path.Dir(path.Join(dir, name)), 0700))
+		require.NoError(t, os.WriteFile(path.Join(dir, name), []byte(content), 0600))
+	}
+
+	write("Dockerfile", "FROM alpine\n")
+	write("main.go", "package main\n")
+	write(".dockerignore", "*.md\nnode_modules\n")
+	write("README.md", "# readme\n")
+
+	b := config.Build{Context: dir, BuildArg: []string{"A=a", "B=b"}}
+
+	hash, err := b.Hash()
+	require.NoError(t, err)
+	assert.Len(t, hash, 64)
+
+	t.Run("ignored", func(t *testing.T) {
+		write("README.md", "# changed\n")
+		write("node_modules/pkg/index.js", "")
+
+		actual, err := b.Hash()
+		require.NoError(t, err)
+		assert.Equal(t, hash, actual)
+	})
+
+	t.Run("args", func(t *testing.T) {
+		actual, err := (&config.Build{Context: dir, BuildArg: []string{"B=b", "A=a"}}).Hash()
+		require.NoError(t, err)
+		assert.Equal(t, hash, actual)
+
+		actual, err = (&config.Build{Context: dir, BuildArg: []string{"A=b"}}).Hash()
+		require.NoError(t, err)
+		assert.NotEqual(t, hash, actual)
+	})
+
+	t.Run("dependencies", func(t *testing.T) {
+		actual, err := b.Hash("abc")
+		require.NoError(t, err)
+		assert.NotEqual(t, hash, actual)
+	})
+
+	t.Run("changed", func(t *testing.T) {
+		write("Dockerfile", "FROM debian\n")
+
+		actual, err := b.Hash()
+		require.NoError(t, err)
+		assert.NotEqual(t, hash, actual)
+	})
+}
+
+func TestBuild_BakeTarget(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+	t.Setenv("SQUADRON_TEST_ARG", "env")
+
+	b := config.Build{
+		Context:   "backend",
+		File:      "docker/Dockerfile",
+		Tag:       []string{"docker.mycompany.com/backend:latest"},
+		Platform:  []string{"linux/amd64"},
+		BuildArg:  []string{"A=a", "SQUADRON_TEST_ARG", "SQUADRON_TEST_MISSING"},
+		Secret:    []string{"id=npm,src=.npmrc"},
+		SSH:       []string{"default", "github=/tmp/a.pem,/tmp/b.pem"},
+		CacheFrom: []string{"docker.mycompany.com/backend:cache", "type=gha,scope=backend"},
+		AddHost:   []string{"db:127.0.0.1"},
+		Push:      true,
+	}
+
+	actual := b.BakeTarget("backend")
+	assert.Equal(t, "backend", actual.Name)
+	assert.Equal(t, "backend", actual.Context)
+	assert.Equal(t, "docker/Dockerfile", actual.Dockerfile)
+	assert.Equal(t, b.Tag, actual.Tags)
+	assert.Equal(t, b.Platform, actual.Platforms)
+	assert.Equal(t, map[string]string{"A": "a", "SQUADRON_TEST_ARG": "env"}, actual.Args)
+	assert.Equal(t, []map[string]any{{"id": "npm", "src": ".npmrc"}}, actual.Secret)
+	assert.Equal(t, []map[string]any{{"id": "default"}, {"id": "github", "paths": []string{"/tmp/a.pem", "/tmp/b.pem"}}}, actual.SSH)
+	assert.Equal(t, []map[string]string{
+		{"type": "registry", "ref": "docker.mycompany.com/backend:cache"},
+		{"type": "gha", "scope": "backend"},
+	}, actual.CacheFrom)
+	assert.Equal(t, map[string]string{"db": "127.0.0.1"}, actual.ExtraHosts)
+	assert.Equal(t, []map[string]any{{"type": "registry"}}, actual.Outputs)
+
+	assert.Equal(t, ".", (&config.Build{}).BakeTarget("empty").Context)
+}
@@ -0,0 +1,157 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/foomo/squadron/dag"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+type Config struct {
+	// Version of the schema
+	Version string `json:"version,omitempty" yaml:"version,omitempty" jsonschema:"pattern=^[0-9]\\.[0-9]$,required"`
+	// Global values to be injected into all squadron values
+	Vars map[string]any `json:"vars,omitempty" yaml:"vars,omitempty"`
+	// Global values to be injected into all squadron values
+	Global map[string]any `json:"global,omitempty" yaml:"global,omitempty"`
+	// Global raw bake instructions
+	Bake string `json:"bake,omitempty" yaml:"bake,omitempty"`
+	// Global builds that can be referenced as dependencies
+	Builds map[string]Build `json:"builds,omitempty" yaml:"builds,omitempty"`
+	// Global lifecycle hooks run for every unit
+	Hooks *Hooks `json:"hooks,omitempty" yaml:"hooks,omitempty"`
+	// Unit templates referenced by `template` in units and other templates
+	Templates map[string]*Unit `json:"templates,omitempty" yaml:"templates,omitempty"`
+	// Environment overlays selected by `--env`
+	Environments map[string]*Environment `json:"environments,omitempty" yaml:"environments,omitempty"`
+	// Squadron definitions
+	Squadrons Map[Map[*Unit]] `json:"squadron,omitempty" yaml:"squadron,omitempty"`
+}
+
+// JSONSchemaProperty type workaround
+func (Config) JSONSchemaProperty(prop string) any {
+	if prop == "squadron" {
+		return map[string]map[string]*Unit{}
+	}
+
+	return nil
+}
+
+// BuildDependencies returns the global builds required by the units including their transitive
+// dependencies
+func (c *Config) BuildDependencies(ctx context.Context) map[string]Build {
+	ret := map[string]Build{}
+
+	for _, name := range c.BuildGraph(ctx).Nodes() {
+		if build, ok := c.Builds[name]; ok {
+			ret[name] = build
+		}
+	}
+
+	if len(ret) > 0 {
+		return ret
+	}
+
+	return nil
+}
+
+// BuildGraph returns the graph of all unit builds identified by `squadron/unit.build` and the
+// global builds they depend on identified by their name
+func (c *Config) BuildGraph(ctx context.Context) *dag.Graph {
+	ret := dag.New()
+
+	var queue []string
+
+	_ = c.Squadrons.Iterate(ctx, func(ctx context.Context, key string, value Map[*Unit]) error {
+		return value.Iterate(ctx, func(ctx context.Context, k string, v *Unit) error {
+			for _, name := range v.BuildNames() {
+				dependencies := v.Builds[name].Dependencies
+				ret.Add(key+"/"+k+"."+name, dependencies...)
+				queue = append(queue, dependencies...)
+			}
+
+			return nil
+		})
+	})
+
+	// add the global builds transitively, missing ones are reported by validating the graph
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+
+		build, ok := c.Builds[name]
+		if !ok || ret.Has(name) {
+			continue
+		}
+
+		ret.Add(name, build.Dependencies...)
+		queue = append(queue, build.Dependencies...)
+	}
+
+	return ret
+}
+
+// Environment returns the environment by name
+func (c *Config) Environment(name string) (*Environment, error) {
+	if value, ok := c.Environments[name]; ok && value != nil {
+		return value, nil
+	} else if ok {
+		return &Environment{}, nil
+	}
+
+	names := make([]string, 0, len(c.Environments))
+	for key := range c.Environments {
+		names = append(names, key)
+	}
+
+	sort.Strings(names)
+
+	return nil, errors.Errorf("unknown environment `%s` (available: %s)", name, strings.Join(names, ", "))
+}
+
+// UnitGraph returns the dependency graph of all units identified by `squadron/unit`
+func (c *Config) UnitGraph(ctx context.Context) *dag.Graph {
+	ret := dag.New()
+	_ = c.Squadrons.Iterate(ctx, func(ctx context.Context, key string, value Map[*Unit]) error {
+		return value.Iterate(ctx, func(ctx context.Context, k string, v *Unit) error {
+			dependencies := make([]string, 0, len(v.DependsOn))
+			for _, dependency := range v.DependsOn {
+				if !strings.Contains(dependency, "/") {
+					dependency = key + "/" + dependency
+				}
+
+				dependencies = append(dependencies, dependency)
+			}
+
+			ret.Add(key+"/"+k, dependencies...)
+
+			return nil
+		})
+	})
+
+	return ret
+}
+
+// Trim delete empty squadron recursively
+func (c *Config) Trim(ctx context.Context) {
+	_ = c.Squadrons.Iterate(ctx, func(ctx context.Context, key string, value Map[*Unit]) error {
+		value.Trim()
+		return nil
+	})
+	c.Squadrons.Trim()
+}
+
+// UnmarshalYAML interface method
+func (c *Config) UnmarshalYAML(value *yaml.Node) error {
+	switch value.Tag {
+	case "!!map":
+		type wrapper Config
+		return value.Decode((*wrapper)(c))
+	default:
+		return fmt.Errorf("unsupported node tag type for %T: %q", c, value.Tag)
+	}
+}
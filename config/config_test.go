@@ -0,0 +1,58 @@
+package config_test
+
+import (
+	"testing"
+
+	testingx "github.com/foomo/go/testing"
+	tagx "github.com/foomo/go/testing/tag"
+	"github.com/foomo/squadron/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_BuildGraph(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	c := config.Config{
+		Builds: map[string]config.Build{
+			"base":    {},
+			"runtime": {Dependencies: []string{"base"}},
+			"builder": {Dependencies: []string{"runtime"}},
+			"unused":  {},
+		},
+		Squadrons: config.Map[config.Map[*config.Unit]]{
+			"site": {
+				"backend": {Builds: map[string]config.Build{
+					"default": {Dependencies: []string{"builder"}},
+				}},
+				"frontend": {Builds: map[string]config.Build{
+					"default": {Dependencies: []string{"base"}},
+				}},
+			},
+		},
+	}
+
+	graph := c.BuildGraph(t.Context())
+	require.NoError(t, graph.Validate())
+
+	waves, err := graph.Waves()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"base"},
+		{"runtime", "site/frontend.default"},
+		{"builder"},
+		{"site/backend.default"},
+	}, waves)
+
+	assert.Len(t, c.BuildDependencies(t.Context()), 3)
+
+	t.Run("cycle", func(t *testing.T) {
+		c.Builds["base"] = config.Build{Dependencies: []string{"builder"}}
+		require.EqualError(t, c.BuildGraph(t.Context()).Validate(), "dependency cycle detected: base -> builder -> runtime -> base")
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		c.Builds["base"] = config.Build{Dependencies: []string{"missing"}}
+		require.EqualError(t, c.BuildGraph(t.Context()).Validate(), "missing dependency `missing` for `base`")
+	})
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"dario.cat/mergo"
+	"github.com/pkg/errors"
+)
+
+// Environment overlays the config for a deployment stage
+type Environment struct {
+	// Namespace name or template for all units without namespace
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	// Additional squadron files merged on top of the given files
+	Files []string `json:"files,omitempty" yaml:"files,omitempty"`
+	// Vars merged into the global vars
+	Vars map[string]any `json:"vars,omitempty" yaml:"vars,omitempty"`
+	// Units allowed in the environment (format: squadron or squadron/unit, supports globs)
+	Units []string `json:"units,omitempty" yaml:"units,omitempty"`
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public methods
+// ------------------------------------------------------------------------------------------------
+
+// Allows returns true if the unit is allowed in the environment
+func (e *Environment) Allows(squadron, unit string) bool {
+	if len(e.Units) == 0 {
+		return true
+	}
+
+	for _, pattern := range e.Units {
+		if e.match(pattern, squadron, unit) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Apply merges the environment vars and removes all units not allowed in the environment
+func (e *Environment) Apply(ctx context.Context, c *Config) error {
+	// validate unit references
+	for _, pattern := range e.Units {
+		var found bool
+
+		_ = c.Squadrons.Iterate(ctx, func(ctx context.Context, key string, value Map[*Unit]) error {
+			for k := range value {
+				if e.match(pattern, key, k) {
+					found = true
+				}
+			}
+
+			return nil
+		})
+
+		if !found {
+			return errors.Errorf("unknown unit reference `%s`", pattern)
+		}
+	}
+
+	if len(e.Vars) > 0 {
+		if c.Vars == nil {
+			c.Vars = map[string]any{}
+		}
+
+		if err := mergo.Merge(&c.Vars, e.Vars, mergo.WithOverride); err != nil {
+			return errors.Wrap(err, "failed to merge vars")
+		}
+	}
+
+	_ = c.Squadrons.Iterate(ctx, func(ctx context.Context, key string, value Map[*Unit]) error {
+		return value.FilterFn(func(k string, v *Unit) bool {
+			return e.Allows(key, k)
+		})
+	})
+
+	c.Trim(ctx)
+
+	return nil
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private methods
+// ------------------------------------------------------------------------------------------------
+
+func (e *Environment) match(pattern, squadron, unit string) bool {
+	squadronPattern, unitPattern, ok := strings.Cut(pattern, "/")
+	if !ok {
+		unitPattern = "*"
+	}
+
+	if ok, _ := path.Match(squadronPattern, squadron); !ok {
+		return false
+	}
+
+	ok, _ = path.Match(unitPattern, unit)
+
+	return ok
+}
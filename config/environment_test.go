@@ -0,0 +1,65 @@
+package config_test
+
+import (
+	"testing"
+
+	testingx "github.com/foomo/go/testing"
+	tagx "github.com/foomo/go/testing/tag"
+	"github.com/foomo/squadron/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvironment_Allows(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	tests := []struct {
+		units    []string
+		squadron string
+		unit     string
+		want     bool
+	}{
+		{units: nil, squadron: "storefinder", unit: "frontend", want: true},
+		{units: []string{"storefinder"}, squadron: "storefinder", unit: "frontend", want: true},
+		{units: []string{"storefinder/frontend"}, squadron: "storefinder", unit: "backend", want: false},
+		{units: []string{"storefinder/*-api"}, squadron: "storefinder", unit: "search-api", want: true},
+		{units: []string{"store*"}, squadron: "checkout", unit: "frontend", want: false},
+	}
+
+	for _, test := range tests {
+		e := &config.Environment{Units: test.units}
+		assert.Equal(t, test.want, e.Allows(test.squadron, test.unit), "%v %s/%s", test.units, test.squadron, test.unit)
+	}
+}
+
+func TestEnvironment_Apply(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	newConfig := func() *config.Config {
+		return &config.Config{
+			Vars: map[string]any{"replicas": 1, "domain": "example.local"},
+			Squadrons: config.Map[config.Map[*config.Unit]]{
+				"storefinder": {"frontend": {Tags: config.Tags{"web"}}, "backend": {Tags: config.Tags{"api"}}},
+				"checkout":    {"api": {Tags: config.Tags{"api"}}},
+			},
+		}
+	}
+
+	t.Run("apply", func(t *testing.T) {
+		c := newConfig()
+		e := &config.Environment{
+			Vars:  map[string]any{"replicas": 3},
+			Units: []string{"storefinder/front*"},
+		}
+
+		require.NoError(t, e.Apply(t.Context(), c))
+		assert.Equal(t, map[string]any{"replicas": 3, "domain": "example.local"}, c.Vars)
+		assert.Equal(t, []string{"storefinder"}, c.Squadrons.Keys())
+		assert.Equal(t, []string{"frontend"}, c.Squadrons["storefinder"].Keys())
+	})
+
+	t.Run("unknown unit", func(t *testing.T) {
+		e := &config.Environment{Units: []string{"storefinder/search"}}
+		require.EqualError(t, e.Apply(t.Context(), newConfig()), "unknown unit reference `storefinder/search`")
+	})
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/foomo/squadron/internal/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	HookPreBuild  = "preBuild"
+	HookPostBuild = "postBuild"
+	HookPreUp     = "preUp"
+	HookPostUp    = "postUp"
+	HookPreDown   = "preDown"
+	HookPostDown  = "postDown"
+)
+
+type Hooks struct {
+	// Commands to run before building the unit
+	PreBuild []string `json:"preBuild,omitempty" yaml:"preBuild,omitempty"`
+	// Commands to run after building the unit
+	PostBuild []string `json:"postBuild,omitempty" yaml:"postBuild,omitempty"`
+	// Commands to run before installing the unit
+	PreUp []string `json:"preUp,omitempty" yaml:"preUp,omitempty"`
+	// Commands to run after installing the unit
+	PostUp []string `json:"postUp,omitempty" yaml:"postUp,omitempty"`
+	// Commands to run before uninstalling the unit
+	PreDown []string `json:"preDown,omitempty" yaml:"preDown,omitempty"`
+	// Commands to run after uninstalling the unit
+	PostDown []string `json:"postDown,omitempty" yaml:"postDown,omitempty"`
+}
+
+// HookData is passed to the hook command templates
+type HookData struct {
+	Squadron  string
+	Unit      string
+	Namespace string
+	Release   string
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public methods
+// ------------------------------------------------------------------------------------------------
+
+// Commands returns the commands of the given hook
+func (h *Hooks) Commands(hook string) []string {
+	if h == nil {
+		return nil
+	}
+
+	switch hook {
+	case HookPreBuild:
+		return h.PreBuild
+	case HookPostBuild:
+		return h.PostBuild
+	case HookPreUp:
+		return h.PreUp
+	case HookPostUp:
+		return h.PostUp
+	case HookPreDown:
+		return h.PreDown
+	case HookPostDown:
+		return h.PostDown
+	default:
+		return nil
+	}
+}
+
+// Run executes the commands of the given hook one after another and stops at the first failure
+func (h *Hooks) Run(ctx context.Context, hook string, data HookData) error {
+	for _, command := range h.Commands(hook) {
+		value, err := util.RenderTemplateString(command, data)
+		if err != nil {
+			return errors.Wrapf(err, "failed to render %s hook", hook)
+		}
+
+		out, err := util.NewCommand("sh").
+			Env(
+				"SQUADRON_NAME="+data.Squadron,
+				"SQUADRON_UNIT_NAME="+data.Unit,
+				"SQUADRON_NAMESPACE="+data.Namespace,
+				"SQUADRON_RELEASE="+data.Release,
+			).
+			Args("-c", value).
+			Run(ctx)
+		if err != nil {
+			return errors.Wrapf(err, "%s hook failed: %s", hook, strings.TrimSpace(out))
+		}
+	}
+
+	return nil
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private methods
+// ------------------------------------------------------------------------------------------------
+
+// merge returns the hooks with the commands of the given hooks appended
+func (h *Hooks) merge(other *Hooks) *Hooks {
+	if h == nil {
+		return other
+	} else if other == nil {
+		ret := *h
+		return &ret
+	}
+
+	return &Hooks{
+		PreBuild:  append(slices.Clone(h.PreBuild), other.PreBuild...),
+		PostBuild: append(slices.Clone(h.PostBuild), other.PostBuild...),
+		PreUp:     append(slices.Clone(h.PreUp), other.PreUp...),
+		PostUp:    append(slices.Clone(h.PostUp), other.PostUp...),
+		PreDown:   append(slices.Clone(h.PreDown), other.PreDown...),
+		PostDown:  append(slices.Clone(h.PostDown), other.PostDown...),
+	}
+}
@@ -0,0 +1,56 @@
+package config_test
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	testingx "github.com/foomo/go/testing"
+	tagx "github.com/foomo/go/testing/tag"
+	"github.com/foomo/squadron/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHooks_Run(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	out := path.Join(t.TempDir(), "out")
+	data := config.HookData{
+		Squadron:  "storefinder",
+		Unit:      "backend",
+		Namespace: "demo",
+		Release:   "storefinder-backend",
+	}
+
+	t.Run("template", func(t *testing.T) {
+		hooks := &config.Hooks{
+			PreUp: []string{
+				"echo {{.Squadron}}/{{.Unit}} >> " + out,
+				"echo $SQUADRON_NAMESPACE $SQUADRON_RELEASE >> " + out,
+			},
+		}
+
+		require.NoError(t, hooks.Run(t.Context(), config.HookPreUp, data))
+		require.NoError(t, hooks.Run(t.Context(), config.HookPostUp, data))
+
+		value, err := os.ReadFile(out)
+		require.NoError(t, err)
+		assert.Equal(t, "storefinder/backend\ndemo storefinder-backend\n", string(value))
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		hooks := &config.Hooks{
+			PreDown: []string{"echo oops && exit 1", "echo unreachable >> " + out},
+		}
+
+		err := hooks.Run(t.Context(), config.HookPreDown, data)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "preDown hook failed: oops")
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		var hooks *config.Hooks
+		require.NoError(t, hooks.Run(t.Context(), config.HookPreBuild, data))
+	})
+}
@@ -5,7 +5,7 @@ import (
 
 	testingx "github.com/foomo/go/testing"
 	tagx "github.com/foomo/go/testing/tag"
-	"github.com/foomo/squadron/internal/config"
+	"github.com/foomo/squadron/config"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -0,0 +1,219 @@
+package config
+
+import (
+	"slices"
+	"strings"
+
+	"dario.cat/mergo"
+	"github.com/pkg/errors"
+)
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public methods
+// ------------------------------------------------------------------------------------------------
+
+// ResolveTemplates merges the unit templates into all units referencing them and removes the templates
+func (c *Config) ResolveTemplates() error {
+	resolved := map[string]*Unit{}
+
+	for _, key := range c.Squadrons.Keys() {
+		for _, k := range c.Squadrons[key].Keys() {
+			unit := c.Squadrons[key][k]
+			if unit == nil || unit.Template == "" {
+				continue
+			}
+
+			template, err := c.resolveTemplate(unit.Template, nil, resolved)
+			if err != nil {
+				return errors.Wrapf(err, "failed to resolve template of unit `%s/%s`", key, k)
+			}
+
+			if err := unit.Inherit(template); err != nil {
+				return errors.Wrapf(err, "failed to inherit template of unit `%s/%s`", key, k)
+			}
+		}
+	}
+
+	c.Templates = nil
+
+	return nil
+}
+
+// Inherit merges the given template into the unit with the unit's settings taking precedence:
+//
+//   - chart, namespace, kustomize and verify are inherited if unset
+//   - priority is inherited if not set explicitly, so a unit may reset it to 0
+//   - tags and dependsOn of the template come first followed by the unit's own
+//   - hook commands of the template run before the unit's own
+//   - builds and bakes are merged by name with unset fields filled from the template
+//   - values are merged deeply with lists being appended
+//
+// Release names are not inherited as they must be unique per unit.
+func (u *Unit) Inherit(template *Unit) error {
+	if u.Chart == (Chart{}) {
+		u.Chart = template.Chart
+	}
+
+	if u.Namespace == "" {
+		u.Namespace = template.Namespace
+	}
+
+	if u.Priority == 0 && !u.prioritySet {
+		u.Priority = template.Priority
+		u.prioritySet = template.prioritySet
+	}
+
+	if u.Kustomize == "" {
+		u.Kustomize = template.Kustomize
+	}
+
+	u.Tags = appendUnique(template.Tags, u.Tags...)
+	u.DependsOn = appendUnique(template.DependsOn, u.DependsOn...)
+	u.Hooks = template.Hooks.merge(u.Hooks)
+
+	if u.Verify == nil && template.Verify != nil {
+		verify := *template.Verify
+		u.Verify = &verify
+	} else if u.Verify != nil && template.Verify != nil && u.Verify.Timeout == "" {
+		u.Verify.Timeout = template.Verify.Timeout
+	}
+
+	if len(template.Builds) > 0 {
+		builds := make(map[string]Build, len(template.Builds))
+		for name, build := range template.Builds {
+			if value, ok := u.Builds[name]; ok {
+				if err := mergo.Merge(&value, build); err != nil {
+					return errors.Wrapf(err, "failed to merge build `%s`", name)
+				}
+
+				build = value
+			}
+
+			builds[name] = build
+		}
+
+		for name, build := range u.Builds {
+			if _, ok := builds[name]; !ok {
+				builds[name] = build
+			}
+		}
+
+		u.Builds = builds
+	}
+
+	if len(template.Bakes) > 0 {
+		bakes := make(map[string]BakeTarget, len(template.Bakes))
+		for name, bake := range template.Bakes {
+			if value, ok := u.Bakes[name]; ok {
+				if err := mergo.Merge(&value, bake); err != nil {
+					return errors.Wrapf(err, "failed to merge bake `%s`", name)
+				}
+
+				bake = value
+			}
+
+			bakes[name] = bake
+		}
+
+		for name, bake := range u.Bakes {
+			if _, ok := bakes[name]; !ok {
+				bakes[name] = bake
+			}
+		}
+
+		u.Bakes = bakes
+	}
+
+	if len(template.Values) > 0 {
+		values, _ := copyValue(template.Values).(map[string]any)
+		if err := mergo.Merge(&values, u.Values, mergo.WithAppendSlice, mergo.WithOverride, mergo.WithSliceDeepCopy); err != nil {
+			return errors.Wrap(err, "failed to merge values")
+		}
+
+		u.Values = values
+	}
+
+	u.Template = ""
+
+	return nil
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private methods
+// ------------------------------------------------------------------------------------------------
+
+// resolveTemplate returns the named template with all its parent templates merged in
+func (c *Config) resolveTemplate(name string, stack []string, resolved map[string]*Unit) (*Unit, error) {
+	if value, ok := resolved[name]; ok {
+		return value, nil
+	}
+
+	if slices.Contains(stack, name) {
+		return nil, errors.Errorf("template cycle `%s`", strings.Join(append(stack, name), " -> "))
+	}
+
+	template, ok := c.Templates[name]
+	if !ok || template == nil {
+		return nil, errors.Errorf("unknown template `%s`", name)
+	} else if template.Name != "" {
+		return nil, errors.Errorf("template `%s` must not set a release name", name)
+	}
+
+	ret := *template
+	if ret.Template != "" {
+		parent, err := c.resolveTemplate(ret.Template, append(stack, name), resolved)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := ret.Inherit(parent); err != nil {
+			return nil, errors.Wrapf(err, "failed to inherit template `%s`", ret.Template)
+		}
+	}
+
+	resolved[name] = &ret
+
+	return &ret, nil
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private functions
+// ------------------------------------------------------------------------------------------------
+
+// appendUnique returns a copy of the given list with all values appended that are not contained yet
+func appendUnique[T comparable](list []T, values ...T) []T {
+	if len(list) == 0 && len(values) == 0 {
+		return list
+	}
+
+	ret := slices.Clone(list)
+	for _, value := range values {
+		if !slices.Contains(ret, value) {
+			ret = append(ret, value)
+		}
+	}
+
+	return ret
+}
+
+// copyValue returns a deep copy of the maps and slices of the given value
+func copyValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		ret := make(map[string]any, len(v))
+		for key, item := range v {
+			ret[key] = copyValue(item)
+		}
+
+		return ret
+	case []any:
+		ret := make([]any, len(v))
+		for i, item := range v {
+			ret[i] = copyValue(item)
+		}
+
+		return ret
+	default:
+		return value
+	}
+}
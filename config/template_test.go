@@ -0,0 +1,243 @@
+package config_test
+
+import (
+	"testing"
+
+	testingx "github.com/foomo/go/testing"
+	tagx "github.com/foomo/go/testing/tag"
+	"github.com/foomo/squadron/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestConfig_ResolveTemplates(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	c := &config.Config{
+		Templates: map[string]*config.Unit{
+			"base": {
+				Chart:  config.Chart{Name: "service", Repository: "https://charts.example.com", Version: "1.0.0"},
+				Tags:   config.Tags{"service"},
+				Values: map[string]any{"image": map[string]any{"repository": "nginx", "tag": "latest"}},
+			},
+			"go-service": {
+				Template: "base",
+				Tags:     config.Tags{"go"},
+				Priority: 10,
+				Builds:   map[string]config.Build{"default": {Context: ".", File: "Dockerfile"}},
+			},
+		},
+		Squadrons: config.Map[config.Map[*config.Unit]]{
+			"checkout": {
+				"api": {
+					Template: "go-service",
+					Tags:     config.Tags{"go", "api"},
+					Builds:   map[string]config.Build{"default": {File: "api.Dockerfile"}},
+					Values:   map[string]any{"image": map[string]any{"tag": "v1"}},
+				},
+				"worker": {
+					Template: "go-service",
+					Priority: 1,
+				},
+				"plain": {
+					Tags: config.Tags{"plain"},
+				},
+			},
+		},
+	}
+
+	require.NoError(t, c.ResolveTemplates())
+	assert.Nil(t, c.Templates)
+
+	api := c.Squadrons["checkout"]["api"]
+	assert.Empty(t, api.Template)
+	assert.Equal(t, "service", api.Chart.Name)
+	assert.Equal(t, config.Tags{"service", "go", "api"}, api.Tags)
+	assert.Equal(t, 10, api.Priority)
+	assert.Equal(t, config.Build{Context: ".", File: "api.Dockerfile"}, api.Builds["default"])
+	assert.Equal(t, map[string]any{"image": map[string]any{"repository": "nginx", "tag": "v1"}}, api.Values)
+
+	// overrides of one unit must not leak into the others
+	worker := c.Squadrons["checkout"]["worker"]
+	assert.Equal(t, 1, worker.Priority)
+	assert.Equal(t, config.Build{Context: ".", File: "Dockerfile"}, worker.Builds["default"])
+	assert.Equal(t, map[string]any{"image": map[string]any{"repository": "nginx", "tag": "latest"}}, worker.Values)
+
+	assert.Equal(t, config.Tags{"plain"}, c.Squadrons["checkout"]["plain"].Tags)
+}
+
+func TestConfig_ResolveTemplates_error(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	tests := []struct {
+		name      string
+		templates map[string]*config.Unit
+		want      string
+	}{
+		{
+			name:      "unknown",
+			templates: map[string]*config.Unit{"a": {Template: "b"}},
+			want:      "failed to resolve template of unit `checkout/api`: unknown template `b`",
+		},
+		{
+			name:      "name",
+			templates: map[string]*config.Unit{"a": {Name: "api"}},
+			want:      "failed to resolve template of unit `checkout/api`: template `a` must not set a release name",
+		},
+		{
+			name:      "cycle",
+			templates: map[string]*config.Unit{"a": {Template: "b"}, "b": {Template: "a"}},
+			want:      "failed to resolve template of unit `checkout/api`: template cycle `a -> b -> a`",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &config.Config{
+				Templates: tt.templates,
+				Squadrons: config.Map[config.Map[*config.Unit]]{
+					"checkout": {"api": {Template: "a"}},
+				},
+			}
+			require.EqualError(t, c.ResolveTemplates(), tt.want)
+		})
+	}
+}
+
+func TestUnit_Inherit(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	tests := []struct {
+		name     string
+		template config.Unit
+		unit     config.Unit
+		want     config.Unit
+	}{
+		{
+			name:     "chart",
+			template: config.Unit{Chart: config.Chart{Name: "service", Version: "1.0.0"}},
+			unit:     config.Unit{},
+			want:     config.Unit{Chart: config.Chart{Name: "service", Version: "1.0.0"}},
+		},
+		{
+			name:     "chart override",
+			template: config.Unit{Chart: config.Chart{Name: "service", Version: "1.0.0"}},
+			unit:     config.Unit{Chart: config.Chart{Name: "worker"}},
+			want:     config.Unit{Chart: config.Chart{Name: "worker"}},
+		},
+		{
+			name:     "namespace",
+			template: config.Unit{Namespace: "{{.Squadron}}"},
+			unit:     config.Unit{},
+			want:     config.Unit{Namespace: "{{.Squadron}}"},
+		},
+		{
+			name:     "namespace override",
+			template: config.Unit{Namespace: "{{.Squadron}}"},
+			unit:     config.Unit{Namespace: "custom"},
+			want:     config.Unit{Namespace: "custom"},
+		},
+		{
+			name:     "priority",
+			template: config.Unit{Priority: 10},
+			unit:     config.Unit{},
+			want:     config.Unit{Priority: 10},
+		},
+		{
+			name:     "priority override",
+			template: config.Unit{Priority: 10},
+			unit:     config.Unit{Priority: 1},
+			want:     config.Unit{Priority: 1},
+		},
+		{
+			name:     "kustomize",
+			template: config.Unit{Kustomize: "./kustomize"},
+			unit:     config.Unit{},
+			want:     config.Unit{Kustomize: "./kustomize"},
+		},
+		{
+			name:     "tags",
+			template: config.Unit{Tags: config.Tags{"service", "go"}},
+			unit:     config.Unit{Tags: config.Tags{"go", "api"}},
+			want:     config.Unit{Tags: config.Tags{"service", "go", "api"}},
+		},
+		{
+			name:     "dependsOn",
+			template: config.Unit{DependsOn: []string{"storage/postgres"}},
+			unit:     config.Unit{DependsOn: []string{"storage/redis", "storage/postgres"}},
+			want:     config.Unit{DependsOn: []string{"storage/postgres", "storage/redis"}},
+		},
+		{
+			name:     "hooks",
+			template: config.Unit{Hooks: &config.Hooks{PreUp: []string{"echo template"}, PostDown: []string{"echo cleanup"}}},
+			unit:     config.Unit{Hooks: &config.Hooks{PreUp: []string{"echo unit"}}},
+			want:     config.Unit{Hooks: &config.Hooks{PreUp: []string{"echo template", "echo unit"}, PostDown: []string{"echo cleanup"}}},
+		},
+		{
+			name:     "hooks from template",
+			template: config.Unit{Hooks: &config.Hooks{PreBuild: []string{"make generate"}}},
+			unit:     config.Unit{},
+			want:     config.Unit{Hooks: &config.Hooks{PreBuild: []string{"make generate"}}},
+		},
+		{
+			name:     "verify",
+			template: config.Unit{Verify: &config.Verify{Timeout: "10m"}},
+			unit:     config.Unit{},
+			want:     config.Unit{Verify: &config.Verify{Timeout: "10m"}},
+		},
+		{
+			name:     "verify override",
+			template: config.Unit{Verify: &config.Verify{Timeout: "10m"}},
+			unit:     config.Unit{Verify: &config.Verify{Timeout: "1m"}},
+			want:     config.Unit{Verify: &config.Verify{Timeout: "1m"}},
+		},
+		{
+			name:     "builds",
+			template: config.Unit{Builds: map[string]config.Build{"default": {Context: ".", File: "Dockerfile"}}},
+			unit:     config.Unit{Builds: map[string]config.Build{"default": {File: "api.Dockerfile"}, "migrate": {Context: "migrations"}}},
+			want:     config.Unit{Builds: map[string]config.Build{"default": {Context: ".", File: "api.Dockerfile"}, "migrate": {Context: "migrations"}}},
+		},
+		{
+			name:     "bakes",
+			template: config.Unit{Bakes: map[string]config.BakeTarget{"default": {Context: ".", Dockerfile: "Dockerfile"}}},
+			unit:     config.Unit{Bakes: map[string]config.BakeTarget{"default": {Dockerfile: "api.Dockerfile"}}},
+			want:     config.Unit{Bakes: map[string]config.BakeTarget{"default": {Context: ".", Dockerfile: "api.Dockerfile"}}},
+		},
+		{
+			name:     "values",
+			template: config.Unit{Values: map[string]any{"image": map[string]any{"repository": "nginx", "tag": "latest"}, "args": []any{"a"}}},
+			unit:     config.Unit{Values: map[string]any{"image": map[string]any{"tag": "v1"}, "args": []any{"b"}}},
+			want:     config.Unit{Values: map[string]any{"image": map[string]any{"repository": "nginx", "tag": "v1"}, "args": []any{"a", "b"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unit := tt.unit
+			require.NoError(t, unit.Inherit(&tt.template))
+			assert.Equal(t, tt.want, unit)
+		})
+	}
+}
+
+func TestUnit_Inherit_priority(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	var c config.Config
+	require.NoError(t, yaml.Unmarshal([]byte(`
+version: "2.3"
+templates:
+  service:
+    priority: 10
+squadron:
+  checkout:
+    api:
+      template: service
+    worker:
+      template: service
+      priority: 0
+`), &c))
+	require.NoError(t, c.ResolveTemplates())
+
+	assert.Equal(t, 10, c.Squadrons["checkout"]["api"].Priority)
+	assert.Equal(t, 0, c.Squadrons["checkout"]["worker"].Priority)
+}
@@ -1,16 +1,12 @@
 package config
 
 import (
-	"bytes"
 	"context"
 	"os"
-	"path"
 	"sort"
-	"strings"
 
 	"dario.cat/mergo"
 	"github.com/foomo/squadron/internal/template"
-	"github.com/foomo/squadron/internal/util"
 	"github.com/pkg/errors"
 	yamlv2 "gopkg.in/yaml.v2"
 	"gopkg.in/yaml.v3"
@@ -27,16 +23,26 @@ type Unit struct {
 	Tags Tags `json:"tags,omitempty" yaml:"tags,omitempty"`
 	// Installation priority, higher comes first
 	Priority int `json:"priority,omitempty" yaml:"priority,omitempty"`
+	// List of units this unit depends on (format: squadron/unit)
+	DependsOn []string `json:"dependsOn,omitempty" yaml:"dependsOn,omitempty"`
+	// Name of the unit template to inherit from
+	Template string `json:"template,omitempty" yaml:"template,omitempty"`
 	// Extend chart values
 	Extends string `json:"extends,omitempty" yaml:"extends,omitempty"`
 	// Kustomize files path
 	Kustomize string `json:"kustomize,omitempty" yaml:"kustomize,omitempty"`
+	// Lifecycle hooks
+	Hooks *Hooks `json:"hooks,omitempty" yaml:"hooks,omitempty"`
+	// Post deployment verification settings
+	Verify *Verify `json:"verify,omitempty" yaml:"verify,omitempty"`
 	// Map of containers to build
 	Builds map[string]Build `json:"builds,omitempty" yaml:"builds,omitempty"`
 	// Map of containers to bakes
 	Bakes map[string]BakeTarget `json:"bakes,omitempty" yaml:"bakes,omitempty"`
 	// Chart values
 	Values map[string]any `json:"values,omitempty" yaml:"values,omitempty"`
+	// whether the priority has been set explicitly, even if to 0
+	prioritySet bool
 }
 
 // ------------------------------------------------------------------------------------------------
@@ -50,6 +56,12 @@ func (u *Unit) UnmarshalYAML(value *yaml.Node) error {
 		return err
 	}
 
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		if value.Content[i].Value == "priority" {
+			u.prioritySet = true
+		}
+	}
+
 	if u.Extends != "" {
 		// render filename
 		filename, err := template.ExecuteFileTemplate(context.Background(), u.Extends, nil, true)
@@ -125,57 +137,3 @@ func (u *Unit) BuildNames() []string {
 
 	return ret
 }
-
-func (u *Unit) Template(ctx context.Context, name, squadron, unit, namespace string, global map[string]any, helmArgs []string) ([]byte, error) {
-	var ret bytes.Buffer
-
-	valueBytes, err := u.ValuesYAML(global)
-	if err != nil {
-		return nil, err
-	}
-
-	cmd := util.NewHelmCommand().Args("template", name).
-		Stdin(bytes.NewReader(valueBytes)).
-		Stdout(&ret).
-		Args("--dependency-update").
-		Args("--namespace", namespace).
-		Args("--debug").
-		Args("--set", "global.foomo.squadron.name="+squadron).
-		Args("--set", "global.foomo.squadron.unit="+unit).
-		Args(u.PostRendererArgs()...).
-		Args("--values", "-").
-		Args(helmArgs...)
-
-	if after, ok := strings.CutPrefix(u.Chart.Repository, "file://"); ok {
-		cmd.Args(path.Clean(after))
-	} else {
-		cmd.Args(u.Chart.Name)
-
-		if u.Chart.Repository != "" {
-			cmd.Args("--repo", u.Chart.Repository)
-		}
-
-		if u.Chart.Version != "" {
-			cmd.Args("--version", u.Chart.Version)
-		}
-	}
-
-	if out, err := cmd.Run(ctx); err != nil {
-		return nil, errors.Wrap(err, out)
-	}
-
-	return ret.Bytes(), nil
-}
-
-func (u *Unit) PostRendererArgs() []string {
-	var ret []string
-	if u.Kustomize != "" {
-		ret = append(ret,
-			"--post-renderer", "squadron",
-			"--post-renderer-args", "post-renderer",
-			"--post-renderer-args", u.Kustomize,
-		)
-	}
-
-	return ret
-}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultVerifyTimeout is used if the unit does not configure a verify timeout
+const DefaultVerifyTimeout = 5 * time.Minute
+
+type Verify struct {
+	// Maximum duration to wait for the workload rollouts (default: 5m)
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public methods
+// ------------------------------------------------------------------------------------------------
+
+// TimeoutDuration returns the parsed timeout or the default
+func (v *Verify) TimeoutDuration() (time.Duration, error) {
+	if v == nil || v.Timeout == "" {
+		return DefaultVerifyTimeout, nil
+	}
+
+	ret, err := time.ParseDuration(v.Timeout)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid verify timeout `%s`", v.Timeout)
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,255 @@
+package dag
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+// Graph of named nodes and their dependencies
+type Graph struct {
+	nodes        []string
+	dependencies map[string][]string
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Constructor
+// ------------------------------------------------------------------------------------------------
+
+// New returns an empty graph
+func New() *Graph {
+	return &Graph{
+		dependencies: map[string][]string{},
+	}
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public methods
+// ------------------------------------------------------------------------------------------------
+
+// Add adds the node with the given dependencies
+func (g *Graph) Add(name string, dependencies ...string) {
+	if _, ok := g.dependencies[name]; !ok {
+		g.nodes = append(g.nodes, name)
+		g.dependencies[name] = nil
+	}
+
+	for _, dependency := range dependencies {
+		if !slices.Contains(g.dependencies[name], dependency) {
+			g.dependencies[name] = append(g.dependencies[name], dependency)
+		}
+	}
+}
+
+// Has returns true if the node exists
+func (g *Graph) Has(name string) bool {
+	_, ok := g.dependencies[name]
+	return ok
+}
+
+// Nodes returns all node names sorted
+func (g *Graph) Nodes() []string {
+	ret := slices.Clone(g.nodes)
+	sort.Strings(ret)
+
+	return ret
+}
+
+// Dependencies returns the known dependencies of the given node
+func (g *Graph) Dependencies(name string) []string {
+	var ret []string
+
+	for _, dependency := range g.dependencies[name] {
+		if g.Has(dependency) {
+			ret = append(ret, dependency)
+		}
+	}
+
+	return ret
+}
+
+// Validate returns an error on missing dependencies or cycles
+func (g *Graph) Validate() error {
+	for _, name := range g.Nodes() {
+		for _, dependency := range g.dependencies[name] {
+			if !g.Has(dependency) {
+				return errors.Errorf("missing dependency `%s` for `%s`", dependency, name)
+			}
+		}
+	}
+
+	if cycle := g.cycle(); len(cycle) > 0 {
+		return errors.Errorf("dependency cycle detected: %s", strings.Join(cycle, " -> "))
+	}
+
+	return nil
+}
+
+// Waves returns the nodes grouped in topological order where every wave only
+// depends on nodes of previous waves. Dependencies on unknown nodes are ignored.
+func (g *Graph) Waves() ([][]string, error) {
+	if cycle := g.cycle(); len(cycle) > 0 {
+		return nil, errors.Errorf("dependency cycle detected: %s", strings.Join(cycle, " -> "))
+	}
+
+	var ret [][]string
+
+	done := map[string]bool{}
+	for len(done) < len(g.nodes) {
+		var wave []string
+
+		for _, name := range g.Nodes() {
+			if done[name] {
+				continue
+			}
+
+			ready := true
+
+			for _, dependency := range g.Dependencies(name) {
+				if !done[dependency] {
+					ready = false
+					break
+				}
+			}
+
+			if ready {
+				wave = append(wave, name)
+			}
+		}
+
+		for _, name := range wave {
+			done[name] = true
+		}
+
+		ret = append(ret, wave)
+	}
+
+	return ret, nil
+}
+
+// Run calls the function for all nodes with up to `parallel` calls at once, starting every node as
+// soon as all of its dependencies succeeded. The first error cancels the context and no further
+// nodes are started.
+func (g *Graph) Run(ctx context.Context, parallel int, fn func(ctx context.Context, name string) error) error {
+	if cycle := g.cycle(); len(cycle) > 0 {
+		return errors.Errorf("dependency cycle detected: %s", strings.Join(cycle, " -> "))
+	}
+
+	type result struct {
+		name string
+		err  error
+	}
+
+	pending := map[string]int{}
+	dependents := map[string][]string{}
+
+	var queue []string
+
+	for _, name := range g.Nodes() {
+		dependencies := g.Dependencies(name)
+		for _, dependency := range dependencies {
+			dependents[dependency] = append(dependents[dependency], name)
+		}
+
+		if pending[name] = len(dependencies); pending[name] == 0 {
+			queue = append(queue, name)
+		}
+	}
+
+	wg, ctx := errgroup.WithContext(ctx)
+	wg.SetLimit(parallel)
+
+	// buffered so that finished nodes never block
+	results := make(chan result, len(g.nodes))
+
+	var (
+		running int
+		failed  bool
+	)
+
+	for len(queue) > 0 || running > 0 {
+		for _, name := range queue {
+			running++
+
+			wg.Go(func() error {
+				err := fn(ctx, name)
+				results <- result{name: name, err: err}
+
+				return err
+			})
+		}
+
+		queue = nil
+
+		res := <-results
+		running--
+
+		if failed = failed || res.err != nil || ctx.Err() != nil; failed {
+			continue
+		}
+
+		for _, dependent := range dependents[res.name] {
+			if pending[dependent]--; pending[dependent] == 0 {
+				queue = append(queue, dependent)
+			}
+		}
+	}
+
+	return wg.Wait()
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private methods
+// ------------------------------------------------------------------------------------------------
+
+// cycle returns the first dependency cycle found
+func (g *Graph) cycle() []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := map[string]int{}
+
+	var (
+		stack []string
+		visit func(name string) []string
+	)
+
+	visit = func(name string) []string {
+		state[name] = visiting
+
+		stack = append(stack, name)
+		for _, dependency := range g.Dependencies(name) {
+			switch state[dependency] {
+			case visiting:
+				index := slices.Index(stack, dependency)
+				return append(slices.Clone(stack[index:]), dependency)
+			case unvisited:
+				if ret := visit(dependency); len(ret) > 0 {
+					return ret
+				}
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		state[name] = visited
+
+		return nil
+	}
+
+	for _, name := range g.Nodes() {
+		if state[name] == unvisited {
+			if ret := visit(name); len(ret) > 0 {
+				return ret
+			}
+		}
+	}
+
+	return nil
+}
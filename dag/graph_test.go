@@ -0,0 +1,139 @@
+package dag_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	testingx "github.com/foomo/go/testing"
+	tagx "github.com/foomo/go/testing/tag"
+	"github.com/foomo/squadron/dag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraph_Waves(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	g := dag.New()
+	g.Add("frontend", "backend", "cache")
+	g.Add("backend", "database")
+	g.Add("cache")
+	g.Add("database")
+	g.Add("worker", "backend", "unknown")
+
+	require.Error(t, g.Validate())
+
+	waves, err := g.Waves()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"cache", "database"},
+		{"backend"},
+		{"frontend", "worker"},
+	}, waves)
+}
+
+func TestGraph_Validate(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	t.Run("valid", func(t *testing.T) {
+		g := dag.New()
+		g.Add("a", "b")
+		g.Add("b")
+		require.NoError(t, g.Validate())
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		g := dag.New()
+		g.Add("a", "b")
+		require.EqualError(t, g.Validate(), "missing dependency `b` for `a`")
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		g := dag.New()
+		g.Add("a", "b")
+		g.Add("b", "c")
+		g.Add("c", "a")
+		require.EqualError(t, g.Validate(), "dependency cycle detected: a -> b -> c -> a")
+
+		_, err := g.Waves()
+		require.Error(t, err)
+	})
+
+	t.Run("self", func(t *testing.T) {
+		g := dag.New()
+		g.Add("a", "a")
+		require.EqualError(t, g.Validate(), "dependency cycle detected: a -> a")
+	})
+}
+
+func TestGraph_Run(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	newGraph := func() *dag.Graph {
+		g := dag.New()
+		g.Add("base")
+		g.Add("runtime", "base")
+		g.Add("builder", "runtime")
+		g.Add("service", "builder", "base")
+		g.Add("other")
+
+		return g
+	}
+
+	t.Run("order", func(t *testing.T) {
+		var (
+			lock sync.Mutex
+			done []string
+		)
+
+		err := newGraph().Run(t.Context(), 3, func(ctx context.Context, name string) error {
+			lock.Lock()
+			defer lock.Unlock()
+
+			done = append(done, name)
+
+			return nil
+		})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"base", "runtime", "builder", "service", "other"}, done)
+		assert.Less(t, slices.Index(done, "base"), slices.Index(done, "runtime"))
+		assert.Less(t, slices.Index(done, "runtime"), slices.Index(done, "builder"))
+		assert.Less(t, slices.Index(done, "builder"), slices.Index(done, "service"))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		var (
+			lock sync.Mutex
+			done []string
+		)
+
+		err := newGraph().Run(t.Context(), 1, func(ctx context.Context, name string) error {
+			if name == "runtime" {
+				return errors.New("failed")
+			}
+
+			lock.Lock()
+			defer lock.Unlock()
+
+			done = append(done, name)
+
+			return nil
+		})
+		require.EqualError(t, err, "failed")
+		assert.NotContains(t, done, "builder")
+		assert.NotContains(t, done, "service")
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		g := newGraph()
+		g.Add("base", "service")
+
+		err := g.Run(t.Context(), 1, func(ctx context.Context, name string) error {
+			return nil
+		})
+		require.EqualError(t, err, "dependency cycle detected: base -> service -> builder -> runtime -> base")
+	})
+}
@@ -28,6 +28,7 @@ export default defineConfig({
 					{ text: "Quick Start", link: "/guide/quickstart" },
 					{ text: "Core Concepts", link: "/guide/concepts" },
 					{ text: "Configuration", link: "/guide/configuration" },
+					{ text: "Embedding", link: "/guide/embedding" },
 				],
 			},
 			{
@@ -52,6 +53,10 @@ export default defineConfig({
 							{ text: "config", link: "/reference/cli/squadron_config" },
 							{ text: "template", link: "/reference/cli/squadron_template" },
 							{ text: "schema", link: "/reference/cli/squadron_schema" },
+							{ text: "validate", link: "/reference/cli/squadron_validate" },
+							{ text: "migrate", link: "/reference/cli/squadron_migrate" },
+							{ text: "lock", link: "/reference/cli/squadron_lock" },
+							{ text: "fetch", link: "/reference/cli/squadron_fetch" },
 							{
 								text: "completion",
 								link: "/reference/cli/squadron_completion",
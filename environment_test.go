@@ -0,0 +1,97 @@
+package squadron_test
+
+import (
+	"path"
+	"testing"
+
+	testingx "github.com/foomo/go/testing"
+	tagx "github.com/foomo/go/testing/tag"
+	"github.com/foomo/squadron"
+	"github.com/foomo/squadron/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSquadron_WithEnv(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+	t.Setenv("PROJECT_ROOT", ".")
+
+	var cwd string
+
+	ctx := t.Context()
+	require.NoError(t, util.ValidatePath(".", &cwd))
+
+	newSquadron := func(t *testing.T, env string) *squadron.Squadron {
+		t.Helper()
+
+		sq := squadron.New(cwd, "", []string{path.Join("testdata", "environment", "squadron.yaml")}, squadron.WithEnv(env))
+		require.NoError(t, sq.MergeConfigFiles(ctx))
+		require.NoError(t, sq.RenderConfig(ctx))
+
+		return sq
+	}
+
+	t.Run("none", func(t *testing.T) {
+		sq := newSquadron(t, "")
+		units := sq.Config().Squadrons["storefinder"]
+		require.Len(t, units, 2)
+
+		namespace, err := sq.Namespace(ctx, "storefinder", "frontend", units["frontend"])
+		require.NoError(t, err)
+		assert.Equal(t, "default", namespace)
+		assert.Equal(t, map[string]any{"env": nil, "replicas": 1, "host": "example.local"}, units["frontend"].Values)
+		assert.Contains(t, sq.ConfigYAML(), "environments:")
+	})
+
+	t.Run("dev", func(t *testing.T) {
+		sq := newSquadron(t, "dev")
+		units := sq.Config().Squadrons["storefinder"]
+		require.Len(t, units, 2)
+
+		namespace, err := sq.Namespace(ctx, "storefinder", "frontend", units["frontend"])
+		require.NoError(t, err)
+		assert.Equal(t, "storefinder-dev", namespace)
+		assert.NotContains(t, sq.ConfigYAML(), "environments:")
+	})
+
+	t.Run("prod", func(t *testing.T) {
+		sq := newSquadron(t, "prod")
+		units := sq.Config().Squadrons["storefinder"]
+		require.Len(t, units, 1)
+
+		namespace, err := sq.Namespace(ctx, "storefinder", "frontend", units["frontend"])
+		require.NoError(t, err)
+		assert.Equal(t, "storefinder-prod", namespace)
+		assert.Equal(t, map[string]any{"env": "prod", "replicas": 3, "host": "example.com"}, units["frontend"].Values)
+
+		// the dependency on the excluded unit is ignored
+		waves, err := sq.UnitWaves(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{{"storefinder/frontend"}}, waves)
+	})
+
+	t.Run("explicit namespace", func(t *testing.T) {
+		sq := squadron.New(cwd, "custom", []string{path.Join("testdata", "environment", "squadron.yaml")}, squadron.WithEnv("prod"))
+		require.NoError(t, sq.MergeConfigFiles(ctx))
+		require.NoError(t, sq.RenderConfig(ctx))
+
+		namespace, err := sq.Namespace(ctx, "storefinder", "frontend", sq.Config().Squadrons["storefinder"]["frontend"])
+		require.NoError(t, err)
+		assert.Equal(t, "custom", namespace)
+	})
+
+	t.Run("explain", func(t *testing.T) {
+		sq := newSquadron(t, "prod")
+
+		out, values, err := sq.Explain("vars")
+		require.NoError(t, err)
+		assert.Contains(t, out, "replicas: 3 # testdata/environment/squadron.yaml:15 (overrides testdata/environment/squadron.yaml:4)")
+		assert.Contains(t, out, "domain: example.com # testdata/environment/squadron.prod.yaml:4 (overrides testdata/environment/squadron.yaml:5)")
+		assert.Len(t, values, 2)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		sq := squadron.New(cwd, "default", []string{path.Join("testdata", "environment", "squadron.yaml")}, squadron.WithEnv("stage"))
+		require.EqualError(t, sq.MergeConfigFiles(ctx), "unknown environment `stage` (available: dev, prod)")
+	})
+}
@@ -0,0 +1,105 @@
+package squadron
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/foomo/squadron/internal/provenance"
+)
+
+// ConfigValue describes where a merged config value was defined
+type ConfigValue struct {
+	// Dot separated path of the value (format: a.b[0].c), dots in keys are escaped (e.g. app\.kubernetes\.io/name)
+	Path string `json:"path" yaml:"path"`
+	// File and line of the merged value
+	Source string `json:"source" yaml:"source"`
+	// Files and lines of the overridden values, oldest first
+	Overrides []string `json:"overrides,omitempty" yaml:"overrides,omitempty"`
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public methods
+// ------------------------------------------------------------------------------------------------
+
+// Explain returns the config yaml below the given path annotated with the file and line each value
+// came from, along with all values overriding values of previous files
+func (sq *Squadron) Explain(path string) (string, []ConfigValue, error) {
+	p, err := provenance.Load(sq.merged...)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if sq.env != "" {
+		p.Overlay(provenance.Join(provenance.Join("environments", sq.env), "vars"), "vars")
+	}
+
+	inheritTemplates(p)
+
+	out, values, err := p.Explain([]byte(sq.config), strings.Trim(path, "."))
+	if err != nil {
+		return "", nil, err
+	}
+
+	ret := make([]ConfigValue, 0, len(values))
+	for _, value := range values {
+		item := ConfigValue{
+			Path:   value.Path,
+			Source: value.Source.String(),
+		}
+
+		for _, override := range value.Overrides {
+			item.Overrides = append(item.Overrides, override.String())
+		}
+
+		ret = append(ret, item)
+	}
+
+	return string(out), ret, nil
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private functions
+// ------------------------------------------------------------------------------------------------
+
+// inheritTemplates records the values units inherit from their templates, following the merge order
+// of config.Unit.Inherit
+func inheritTemplates(p *provenance.Provenance) {
+	merge := func(path string) provenance.Merge {
+		switch {
+		case path == "chart":
+			return provenance.MergeReplace
+		case path == "tags" || path == "dependsOn":
+			return provenance.MergeAppendUnique
+		case provenance.Contains("hooks", path) || provenance.Contains("values", path):
+			return provenance.MergeAppend
+		default:
+			return provenance.MergeDeep
+		}
+	}
+
+	resolved := map[string]bool{}
+
+	// inherit merges the template into the given unit or template after resolving its parents
+	var inherit func(path string, stack []string)
+	inherit = func(path string, stack []string) {
+		name, ok := p.Scalar(provenance.Join(path, "template"))
+		if !ok || slices.Contains(stack, path) {
+			return
+		}
+
+		template := provenance.Join("templates", name)
+		if !resolved[template] {
+			resolved[template] = true
+			inherit(template, append(stack, path))
+		}
+
+		p.Inherit(template, path, merge)
+	}
+
+	for _, value := range p.Values("squadron") {
+		if unit := provenance.Parent(value.Path); provenance.Parent(provenance.Parent(unit)) == "squadron" &&
+			value.Path == provenance.Join(unit, "template") {
+			inherit(unit, nil)
+		}
+	}
+}
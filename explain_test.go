@@ -0,0 +1,47 @@
+package squadron_test
+
+import (
+	"path"
+	"testing"
+
+	testingx "github.com/foomo/go/testing"
+	tagx "github.com/foomo/go/testing/tag"
+	"github.com/foomo/squadron"
+	"github.com/foomo/squadron/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSquadron_Explain(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+	t.Setenv("PROJECT_ROOT", ".")
+
+	var cwd string
+
+	ctx := t.Context()
+	require.NoError(t, util.ValidatePath(".", &cwd))
+
+	sq := squadron.New(cwd, "", []string{path.Join("testdata", "templates", "squadron.yaml")})
+	require.NoError(t, sq.MergeConfigFiles(ctx))
+	require.NoError(t, sq.RenderConfig(ctx))
+
+	out, values, err := sq.Explain("squadron.storefinder.backend")
+	require.NoError(t, err)
+	assert.Contains(t, out, "priority: 10 # testdata/templates/squadron.yaml:18\n")
+	assert.Contains(t, out, "- service # testdata/templates/squadron.yaml:7\n")
+	assert.Contains(t, out, "- go # testdata/templates/squadron.yaml:17\n")
+	assert.Contains(t, out, "repository: nginx # testdata/templates/squadron.yaml:10\n")
+	assert.Contains(t, out, "tag: v1.0.0 # testdata/templates/squadron.yaml:39 (overrides testdata/templates/squadron.yaml:11)\n")
+	assert.Contains(t, out, "port: 8080 # testdata/templates/squadron.yaml:27\n")
+	assert.Contains(t, out, "value: info # testdata/templates/squadron.yaml:14\n")
+	assert.Contains(t, out, "value: storefinder # testdata/templates/squadron.yaml:42\n")
+	assert.Contains(t, out, "- ghcr.io/foomo/storefinder:latest # testdata/templates/squadron.yaml:36\n")
+	assert.Contains(t, out, "file: Dockerfile # testdata/templates/squadron.yaml:22\n")
+
+	paths := make([]string, 0, len(values))
+	for _, value := range values {
+		paths = append(paths, value.Path)
+	}
+
+	assert.Contains(t, paths, "squadron.storefinder.backend.values.image.tag")
+}
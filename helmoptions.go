@@ -0,0 +1,30 @@
+package squadron
+
+import (
+	"time"
+
+	"github.com/foomo/squadron/internal/helm"
+)
+
+// HelmOptions configures the helm operations of all units
+type HelmOptions struct {
+	// Timeout of every helm operation, defaults to 5m
+	Timeout time.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// Wait for all resources of the releases to become ready instead of the hooks only
+	Wait bool `json:"wait,omitempty" yaml:"wait,omitempty"`
+	// ExtraArgs passed on to the helm cli, e.g. `--skip-crds`, which selects the helm cli backend
+	ExtraArgs []string `json:"extraArgs,omitempty" yaml:"extraArgs,omitempty"`
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private methods
+// ------------------------------------------------------------------------------------------------
+
+// backend returns the release backend for the options
+func (o HelmOptions) backend() (helm.ReleaseBackend, error) {
+	return helm.NewReleaseBackend(helm.Options{
+		Timeout: o.Timeout,
+		Wait:    o.Wait,
+		Args:    o.ExtraArgs,
+	})
+}
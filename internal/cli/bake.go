@@ -19,7 +19,7 @@ func NewBake(c *viper.Viper) *cobra.Command {
 		Example: "squadron bake storefinder frontend backend",
 		Args:    cobra.MinimumNArgs(0),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			sq := squadron.New(cwd, "", c.GetStringSlice("file"))
+			sq := newSquadron("", c.GetStringSlice("file"), squadron.WithBakeBuilds(x.GetBool("bake-builds")))
 
 			if err := sq.MergeConfigFiles(cmd.Context()); err != nil {
 				return errors.Wrap(err, "failed to merge config files")
@@ -54,7 +54,7 @@ func NewBake(c *viper.Viper) *cobra.Command {
 			}
 
 			if x.GetBool("push") {
-				if err := sq.Push(cmd.Context(), x.GetStringSlice("push-args"), x.GetInt("parallel")); err != nil {
+				if _, err := sq.Push(cmd.Context(), x.GetStringSlice("push-args"), x.GetInt("parallel")); err != nil {
 					return errors.Wrap(err, "failed to push units")
 				}
 			}
@@ -77,6 +77,9 @@ func NewBake(c *viper.Viper) *cobra.Command {
 	flags.StringArray("bake-args", nil, "additional docker bake args")
 	_ = x.BindPFlag("bake-args", flags.Lookup("bake-args"))
 
+	flags.Bool("bake-builds", false, "bakes the unit builds and their build dependencies as well")
+	_ = x.BindPFlag("bake-builds", flags.Lookup("bake-builds"))
+
 	flags.StringArray("push-args", nil, "additional docker push args")
 	_ = x.BindPFlag("push-args", flags.Lookup("push-args"))
 
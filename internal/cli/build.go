@@ -1,8 +1,13 @@
 package cli
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/foomo/squadron"
 	"github.com/pkg/errors"
+	"github.com/pterm/pterm"
+	"github.com/pterm/pterm/putils"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -16,27 +21,30 @@ func NewBuild(c *viper.Viper) *cobra.Command {
 		Example: "squadron build storefinder frontend backend",
 		Args:    cobra.MinimumNArgs(0),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			sq := squadron.New(cwd, "", c.GetStringSlice("file"))
+			sq := newSquadron("", c.GetStringSlice("file"))
 
 			if err := sq.MergeConfigFiles(cmd.Context()); err != nil {
 				return errors.Wrap(err, "failed to merge config files")
 			}
 
-			squadronName, unitNames := parseSquadronAndUnitNames(args)
-			if err := sq.FilterConfig(cmd.Context(), squadronName, unitNames, x.GetStringSlice("tags")); err != nil {
-				return errors.Wrap(err, "failed to filter config")
+			if err := filterConfig(cmd.Context(), x, sq, args); err != nil {
+				return err
+			}
+
+			if x.GetBool("graph") {
+				return printBuildGraph(cmd, sq)
 			}
 
 			if err := sq.RenderConfig(cmd.Context()); err != nil {
 				return errors.Wrap(err, "failed to render config")
 			}
 
-			if err := sq.Build(cmd.Context(), x.GetStringSlice("build-args"), x.GetInt("parallel")); err != nil {
+			if _, err := sq.Build(cmd.Context(), x.GetStringSlice("build-args"), x.GetInt("parallel")); err != nil {
 				return errors.Wrap(err, "failed to build units")
 			}
 
 			if x.GetBool("push") {
-				if err := sq.Push(cmd.Context(), x.GetStringSlice("push-args"), x.GetInt("parallel")); err != nil {
+				if _, err := sq.Push(cmd.Context(), x.GetStringSlice("push-args"), x.GetInt("parallel")); err != nil {
 					return errors.Wrap(err, "failed to push units")
 				}
 			}
@@ -53,6 +61,9 @@ func NewBuild(c *viper.Viper) *cobra.Command {
 
 	_ = x.BindPFlag("parallel", flags.Lookup("parallel"))
 
+	flags.Bool("graph", false, "print the build dependency graph instead of building")
+	_ = x.BindPFlag("graph", flags.Lookup("graph"))
+
 	flags.StringArray("build-args", nil, "additional docker buildx build args")
 	_ = x.BindPFlag("build-args", flags.Lookup("build-args"))
 
@@ -60,7 +71,53 @@ func NewBuild(c *viper.Viper) *cobra.Command {
 	_ = c.BindPFlag("push-args", flags.Lookup("push-args"))
 
 	flags.StringSlice("tags", nil, "list of tags to include or exclude (can specify multiple or separate values with commas: tag1,tag2,-tag3)")
-	_ = c.BindPFlag("tags", flags.Lookup("tags"))
+	_ = x.BindPFlag("tags", flags.Lookup("tags"))
+
+	flags.String("select", "", "select units by expression (e.g. 'squadron in (checkout,storefinder) && (tag:backend || tag:critical) && !tag:skip')")
+	_ = x.BindPFlag("select", flags.Lookup("select"))
 
 	return cmd
 }
+
+// printBuildGraph prints the builds grouped in the order they are started
+func printBuildGraph(cmd *cobra.Command, sq *squadron.Squadron) error {
+	c := sq.Config()
+
+	graph := c.BuildGraph(cmd.Context())
+	if err := graph.Validate(); err != nil {
+		return errors.Wrap(err, "invalid build dependencies")
+	}
+
+	waves, err := graph.Waves()
+	if err != nil {
+		return err
+	}
+
+	var list pterm.LeveledList
+
+	for i, wave := range waves {
+		list = append(list, pterm.LeveledListItem{Level: 0, Text: fmt.Sprintf("%d.", i+1)})
+
+		for _, id := range wave {
+			text := "📦 " + id
+			if !strings.Contains(id, "/") {
+				text = "💾 " + id
+			}
+
+			list = append(list, pterm.LeveledListItem{Level: 1, Text: text})
+
+			for _, dependency := range graph.Dependencies(id) {
+				list = append(list, pterm.LeveledListItem{Level: 2, Text: "🗃️: " + dependency})
+			}
+		}
+	}
+
+	if len(list) == 0 {
+		return nil
+	}
+
+	root := putils.TreeFromLeveledList(list)
+	root.Text = "Builds"
+
+	return pterm.DefaultTree.WithRoot(root).Render()
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"context"
+	"os"
+
+	"github.com/foomo/squadron"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/pkg/errors"
+	"github.com/pterm/pterm"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// addLockFlags registers the cluster lock flags
+func addLockFlags(flags *pflag.FlagSet, x *viper.Viper) {
+	flags.Bool("lock", false, "acquire a cluster lock per squadron and namespace while running")
+	_ = x.BindPFlag("lock", flags.Lookup("lock"))
+
+	flags.Bool("force-unlock", false, "replace cluster locks held by others (implies --lock)")
+	_ = x.BindPFlag("force-unlock", flags.Lookup("force-unlock"))
+
+	flags.Duration("lock-ttl", squadron.DefaultLockTTL, "duration after which a cluster lock of a crashed run may be replaced (0 to never expire)")
+	_ = x.BindPFlag("lock-ttl", flags.Lookup("lock-ttl"))
+}
+
+// withLock runs the handler while holding the cluster locks if requested and refuses to run while
+// others hold them otherwise
+func withLock(ctx context.Context, x *viper.Viper, sq *squadron.Squadron, handler func() error) error {
+	if !x.GetBool("lock") && !x.GetBool("force-unlock") {
+		if err := sq.CheckLocks(ctx); err != nil {
+			return errors.Wrap(err, "failed to check lock")
+		}
+
+		return handler()
+	}
+
+	unlock, err := sq.Lock(ctx, newStatus(), x.GetDuration("lock-ttl"), x.GetBool("force-unlock"))
+	if err != nil {
+		return errors.Wrap(err, "failed to acquire lock")
+	}
+
+	defer func() {
+		// release even if the command has been canceled
+		if err := unlock(context.WithoutCancel(ctx)); err != nil {
+			pterm.Warning.Println(err.Error())
+		}
+	}()
+
+	return handler()
+}
+
+// newStatus returns the deployment status from the current git repository
+func newStatus() squadron.Status {
+	status := squadron.Status{
+		Squadron: version,
+		User:     "unknown",
+	}
+
+	if wd, err := os.Getwd(); err == nil {
+		if value := os.Getenv("GIT_DIR"); value != "" {
+			wd = value
+		}
+
+		if repo, err := git.PlainOpen(wd); err == nil {
+			if c, err := repo.Config(); err == nil {
+				status.User = c.User.Name
+			}
+
+			if ref, err := repo.Head(); err == nil {
+				status.Branch = ref.Name().Short()
+				status.Commit = ref.Hash().String()
+
+				if tags, err := repo.Tags(); err == nil {
+					_ = tags.ForEach(func(r *plumbing.Reference) error {
+						if r.Hash() == ref.Hash() {
+							status.Branch = r.Name().Short()
+							return errors.New("found tag")
+						}
+
+						return nil
+					})
+				}
+			}
+		}
+	}
+
+	return status
+}
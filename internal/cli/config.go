@@ -22,7 +22,7 @@ func NewConfig(c *viper.Viper) *cobra.Command {
 		Args:    cobra.MinimumNArgs(0),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			files := c.GetStringSlice("file")
-			sq := squadron.New(cwd, "", files)
+			sq := newSquadron("", files)
 
 			if err := sq.MergeConfigFiles(cmd.Context()); err != nil {
 				return errors.Wrap(err, "failed to merge config files")
@@ -43,6 +43,14 @@ func NewConfig(c *viper.Viper) *cobra.Command {
 
 			out := sq.ConfigYAML()
 
+			var values []squadron.ConfigValue
+			if cmd.Flags().Changed("explain") {
+				var err error
+				if out, values, err = sq.Explain(x.GetString("explain")); err != nil {
+					return errors.Wrap(err, "failed to explain config")
+				}
+			}
+
 			switch {
 			case x.GetBool("raw"):
 				pterm.Println(out)
@@ -53,6 +61,10 @@ func NewConfig(c *viper.Viper) *cobra.Command {
 				pterm.Println(util.Highlight(out))
 			}
 
+			if len(values) > 0 {
+				return printConfigValues(values)
+			}
+
 			return nil
 		},
 	}
@@ -70,5 +82,34 @@ func NewConfig(c *viper.Viper) *cobra.Command {
 	flags.Bool("raw", false, "print raw output without highlighting")
 	_ = x.BindPFlag("raw", flags.Lookup("raw"))
 
+	flags.String("explain", "", "annotate the values below the given path with their source file and line (e.g. --explain=squadron.storefinder)")
+	flags.Lookup("explain").NoOptDefVal = "."
+	_ = x.BindPFlag("explain", flags.Lookup("explain"))
+
 	return cmd
 }
+
+// printConfigValues prints the values overriding values of previous files
+func printConfigValues(values []squadron.ConfigValue) error {
+	tbd := pterm.TableData{
+		{"Path", "Source", "Overrides"},
+	}
+
+	for _, value := range values {
+		tbd = append(tbd, []string{
+			value.Path,
+			value.Source,
+			strings.Join(value.Overrides, "\n"),
+		})
+	}
+
+	out, err := pterm.DefaultTable.WithHasHeader().WithData(tbd).Srender()
+	if err != nil {
+		return err
+	}
+
+	pterm.Info.Println("🔀 | overridden values")
+	pterm.Println(out)
+
+	return nil
+}
@@ -1,7 +1,12 @@
 package cli
 
 import (
+	"fmt"
+	"slices"
+	"strings"
+
 	"github.com/foomo/squadron"
+	"github.com/foomo/squadron/internal/diff"
 	"github.com/foomo/squadron/internal/util"
 	"github.com/pkg/errors"
 	"github.com/pterm/pterm"
@@ -13,11 +18,14 @@ func NewDiff(c *viper.Viper) *cobra.Command {
 	x := viper.New()
 
 	cmd := &cobra.Command{
-		Use:     "diff [SQUADRON] [UNIT...]",
-		Short:   "shows the diff between the installed and local chart",
+		Use:   "diff [SQUADRON] [UNIT...]",
+		Short: "shows the diff between the installed and local chart",
+		Long: "shows the diff between the installed and local chart\n\n" +
+			"With --exit-code, the command exits with 2 if there are changes, 1 on errors and 0 otherwise.",
 		Example: "  squadron diff storefinder frontend backend --namespace demo",
+		PreRun:  preRunOutput(x),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			sq := squadron.New(cwd, x.GetString("namespace"), c.GetStringSlice("file"))
+			sq := newSquadron(x.GetString("namespace"), c.GetStringSlice("file"))
 
 			if err := sq.MergeConfigFiles(cmd.Context()); err != nil {
 				return errors.Wrap(err, "failed to merge config files")
@@ -25,9 +33,8 @@ func NewDiff(c *viper.Viper) *cobra.Command {
 
 			args, helmArgs := parseExtraArgs(args)
 
-			squadronName, unitNames := parseSquadronAndUnitNames(args)
-			if err := sq.FilterConfig(cmd.Context(), squadronName, unitNames, x.GetStringSlice("tags")); err != nil {
-				return errors.Wrap(err, "failed to filter config")
+			if err := filterConfig(cmd.Context(), x, sq, args); err != nil {
+				return err
 			}
 
 			if err := sq.RenderConfig(cmd.Context()); err != nil {
@@ -38,33 +45,126 @@ func NewDiff(c *viper.Viper) *cobra.Command {
 				return errors.Wrap(err, "failed to update dependencies")
 			}
 
-			out, err := sq.Diff(cmd.Context(), helmArgs, x.GetInt("parallel"))
+			items, err := sq.Diff(cmd.Context(), squadron.HelmOptions{ExtraArgs: helmArgs}, x.GetBool("mask-secrets"), x.GetInt("parallel"))
 			if err != nil {
 				return err
 			}
 
-			if !x.GetBool("raw") {
-				out = util.Highlight(out)
+			if x.GetBool("summary") {
+				err = printDiffSummary(x.GetString("output"), items)
+			} else {
+				err = printDiff(x.GetString("output"), x.GetBool("raw"), items)
 			}
 
-			pterm.Println(out)
+			if err != nil {
+				return err
+			}
+
+			if x.GetBool("exit-code") && slices.ContainsFunc(items, func(item squadron.UnitDiff) bool {
+				return len(item.Resources) > 0
+			}) {
+				return &exitCodeError{code: exitCodeDrift}
+			}
 
 			return nil
 		},
 	}
 
 	flags := cmd.Flags()
-	flags.StringP("namespace", "n", "default", "set the namespace name or template (default, squadron-{{.Squadron}}-{{.Unit}})")
+	flags.StringP("namespace", "n", "", "set the namespace name or template, takes precedence over the environment namespace (default, squadron-{{.Squadron}}-{{.Unit}})")
 	_ = x.BindPFlag("namespace", flags.Lookup("namespace"))
 
 	flags.Int("parallel", 1, "run command in parallel")
 	_ = x.BindPFlag("parallel", flags.Lookup("parallel"))
 
+	flags.StringP("output", "o", "", "output format (json, yaml)")
+	_ = x.BindPFlag("output", flags.Lookup("output"))
+
 	flags.StringSlice("tags", nil, "list of tags to include or exclude (can specify multiple or separate values with commas: tag1,tag2,-tag3)")
 	_ = x.BindPFlag("tags", flags.Lookup("tags"))
 
+	flags.String("select", "", "select units by expression (e.g. 'squadron in (checkout,storefinder) && (tag:backend || tag:critical) && !tag:skip')")
+	_ = x.BindPFlag("select", flags.Lookup("select"))
+
 	flags.Bool("raw", false, "print raw output without highlighting")
 	_ = x.BindPFlag("raw", flags.Lookup("raw"))
 
+	flags.Bool("summary", false, "only print the number of added, changed and removed resources")
+	_ = x.BindPFlag("summary", flags.Lookup("summary"))
+
+	flags.Bool("exit-code", false, "exit with 2 if there are changes, 1 on errors and 0 otherwise")
+	_ = x.BindPFlag("exit-code", flags.Lookup("exit-code"))
+
+	flags.Bool("mask-secrets", true, "mask the values of secrets")
+	_ = x.BindPFlag("mask-secrets", flags.Lookup("mask-secrets"))
+
 	return cmd
 }
+
+func printDiff(output string, raw bool, items []squadron.UnitDiff) error {
+	if output != "" {
+		return printOutput(output, items)
+	}
+
+	var res strings.Builder
+	for _, item := range items {
+		res.WriteString(item.Diff)
+	}
+
+	out := res.String()
+	if !raw {
+		out = util.HighlightDiff(out)
+	}
+
+	pterm.Println(out)
+
+	return nil
+}
+
+func printDiffSummary(output string, items []squadron.UnitDiff) error {
+	type summary struct {
+		Squadron string `json:"squadron" yaml:"squadron"`
+		Unit     string `json:"unit" yaml:"unit"`
+		Added    int    `json:"added" yaml:"added"`
+		Changed  int    `json:"changed" yaml:"changed"`
+		Removed  int    `json:"removed" yaml:"removed"`
+	}
+
+	summaries := make([]summary, 0, len(items))
+	for _, item := range items {
+		summaries = append(summaries, summary{
+			Squadron: item.Squadron,
+			Unit:     item.Unit,
+			Added:    item.Count(diff.ActionAdded),
+			Changed:  item.Count(diff.ActionChanged),
+			Removed:  item.Count(diff.ActionRemoved),
+		})
+	}
+
+	if output != "" {
+		return printOutput(output, summaries)
+	}
+
+	tbd := pterm.TableData{
+		{"Squadron", "Unit", "Added", "Changed", "Removed"},
+	}
+
+	for _, s := range summaries {
+		tbd = append(tbd, []string{
+			s.Squadron,
+			s.Unit,
+			fmt.Sprintf("%d", s.Added),
+			fmt.Sprintf("%d", s.Changed),
+			fmt.Sprintf("%d", s.Removed),
+		})
+	}
+
+	out, err := pterm.DefaultTable.WithHasHeader().WithData(tbd).Srender()
+	if err != nil {
+		return err
+	}
+
+	pterm.Println(out)
+
+	return nil
+}
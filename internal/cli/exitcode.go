@@ -0,0 +1,21 @@
+package cli
+
+import (
+	"fmt"
+)
+
+const (
+	// exitCodeFailure is returned for all errors
+	exitCodeFailure = 1
+	// exitCodeDrift is returned by `diff --exit-code` if there are changes
+	exitCodeDrift = 2
+)
+
+// exitCodeError ends the command with the given exit code without printing an error
+type exitCodeError struct {
+	code int
+}
+
+func (e *exitCodeError) Error() string {
+	return fmt.Sprintf("exit code %d", e.code)
+}
@@ -0,0 +1,28 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func NewFetch(c *viper.Viper) *cobra.Command {
+	x := viper.New()
+
+	cmd := &cobra.Command{
+		Use:     "fetch",
+		Short:   "downloads all charts recorded in squadron.lock into the chart cache",
+		Example: "  squadron fetch --parallel 4",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sq := newSquadron("", c.GetStringSlice("file"))
+
+			return sq.FetchCharts(cmd.Context(), x.GetInt("parallel"))
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.Int("parallel", 1, "run command in parallel")
+	_ = x.BindPFlag("parallel", flags.Lookup("parallel"))
+
+	return cmd
+}
@@ -1,11 +1,9 @@
 package cli
 
 import (
-	"context"
 	"fmt"
+	"strings"
 
-	"github.com/foomo/squadron"
-	"github.com/foomo/squadron/internal/config"
 	"github.com/pkg/errors"
 	"github.com/pterm/pterm"
 	"github.com/pterm/pterm/putils"
@@ -21,61 +19,75 @@ func NewList(c *viper.Viper) *cobra.Command {
 		Short:   "list squadron units",
 		Example: "  squadron list storefinder",
 		Args:    cobra.MinimumNArgs(0),
+		PreRun:  preRunOutput(x),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			sq := squadron.New(cwd, "", c.GetStringSlice("file"))
+			sq := newSquadron("", c.GetStringSlice("file"))
 
 			if err := sq.MergeConfigFiles(cmd.Context()); err != nil {
 				return errors.Wrap(err, "failed to merge config files")
 			}
 
-			squadronName, unitNames := parseSquadronAndUnitNames(args)
-			if err := sq.FilterConfig(cmd.Context(), squadronName, unitNames, x.GetStringSlice("tags")); err != nil {
-				return errors.Wrap(err, "failed to filter config")
+			if err := filterConfig(cmd.Context(), x, sq, args); err != nil {
+				return err
 			}
 
-			var list pterm.LeveledList
-
-			// List squadrons
-			_ = sq.Config().Squadrons.Iterate(cmd.Context(), func(ctx context.Context, key string, value config.Map[*config.Unit]) error {
-				list = append(list, pterm.LeveledListItem{Level: 0, Text: key})
+			items, err := sq.List(cmd.Context())
+			if err != nil {
+				return err
+			}
 
-				return value.Iterate(ctx, func(ctx context.Context, k string, v *config.Unit) error {
-					list = append(list, pterm.LeveledListItem{Level: 1, Text: k})
-					if x.GetBool("with-tags") && len(v.Tags) > 0 {
-						list = append(list, pterm.LeveledListItem{Level: 2, Text: "🏷️: " + v.Tags.SortedString()})
-					}
+			if output := x.GetString("output"); output != "" {
+				return printOutput(output, items)
+			}
 
-					if x.GetBool("with-charts") && len(v.Chart.String()) > 0 {
-						list = append(list, pterm.LeveledListItem{Level: 2, Text: "📑: " + v.Chart.String()})
+			var (
+				list     pterm.LeveledList
+				squadron string
+			)
+
+			for _, item := range items {
+				// List squadrons
+				if item.Squadron != squadron {
+					squadron = item.Squadron
+					list = append(list, pterm.LeveledListItem{Level: 0, Text: item.Squadron})
+				}
+
+				list = append(list, pterm.LeveledListItem{Level: 1, Text: item.Unit})
+				if x.GetBool("with-tags") && len(item.Tags) > 0 {
+					list = append(list, pterm.LeveledListItem{Level: 2, Text: "🏷️: " + strings.Join(item.Tags, ",")})
+				}
+
+				if x.GetBool("with-charts") && len(item.Chart) > 0 {
+					list = append(list, pterm.LeveledListItem{Level: 2, Text: "📑: " + item.Chart})
+				}
+
+				if x.GetBool("with-priority") && len(item.Chart) > 0 {
+					list = append(list, pterm.LeveledListItem{Level: 2, Text: fmt.Sprintf("☝️: %d", item.Priority)})
+				}
+
+				if x.GetBool("with-bakes") && len(item.Bakes) > 0 {
+					for name, tags := range item.Bakes {
+						list = append(list, pterm.LeveledListItem{Level: 2, Text: "📦: " + name})
+						for _, tag := range tags {
+							list = append(list, pterm.LeveledListItem{Level: 3, Text: tag})
+						}
 					}
+				}
 
-					if x.GetBool("with-priority") && len(v.Chart.String()) > 0 {
-						list = append(list, pterm.LeveledListItem{Level: 2, Text: fmt.Sprintf("☝️: %d", v.Priority)})
-					}
+				if x.GetBool("with-builds") && len(item.Builds) > 0 {
+					for name, tags := range item.Builds {
+						list = append(list, pterm.LeveledListItem{Level: 2, Text: "📦: " + name})
 
-					if x.GetBool("with-bakes") && len(v.Bakes) > 0 {
-						for name, build := range v.Bakes {
-							list = append(list, pterm.LeveledListItem{Level: 2, Text: "📦: " + name})
-							for _, tag := range build.Tags {
-								list = append(list, pterm.LeveledListItem{Level: 3, Text: tag})
-							}
+						if len(tags) > 0 {
+							list = append(list, pterm.LeveledListItem{Level: 3, Text: tags[0]})
 						}
-					}
-
-					if x.GetBool("with-builds") && len(v.Builds) > 0 {
-						for name, build := range v.Builds {
-							list = append(list, pterm.LeveledListItem{Level: 2, Text: "📦: " + name})
 
-							list = append(list, pterm.LeveledListItem{Level: 3, Text: build.Tag[0]})
-							for _, dependency := range build.Dependencies {
-								list = append(list, pterm.LeveledListItem{Level: 3, Text: "🗃️: " + dependency})
-							}
+						for _, dependency := range item.BuildDependencies[name] {
+							list = append(list, pterm.LeveledListItem{Level: 3, Text: "🗃️: " + dependency})
 						}
 					}
-
-					return nil
-				})
-			})
+				}
+			}
 
 			if len(list) > 0 {
 				root := putils.TreeFromLeveledList(list)
@@ -90,12 +102,15 @@ func NewList(c *viper.Viper) *cobra.Command {
 
 	flags := cmd.Flags()
 
-	flags.String("output", "", "write the output to the given path")
+	flags.StringP("output", "o", "", "output format (json, yaml)")
 	_ = x.BindPFlag("output", flags.Lookup("output"))
 
 	flags.StringSlice("tags", nil, "list of tags to include or exclude (can specify multiple or separate values with commas: tag1,tag2,-tag3)")
 	_ = x.BindPFlag("tags", flags.Lookup("tags"))
 
+	flags.String("select", "", "select units by expression (e.g. 'squadron in (checkout,storefinder) && (tag:backend || tag:critical) && !tag:skip')")
+	_ = x.BindPFlag("select", flags.Lookup("select"))
+
 	flags.Bool("with-tags", false, "include tags")
 	_ = x.BindPFlag("with-tags", flags.Lookup("with-tags"))
 
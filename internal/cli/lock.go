@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func NewLock(c *viper.Viper) *cobra.Command {
+	x := viper.New()
+
+	cmd := &cobra.Command{
+		Use:     "lock [SQUADRON] [UNIT...]",
+		Short:   "resolves remote charts and records their versions and digests in squadron.lock",
+		Example: "  squadron lock storefinder frontend backend",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sq := newSquadron("", c.GetStringSlice("file"))
+
+			if err := sq.MergeConfigFiles(cmd.Context()); err != nil {
+				return errors.Wrap(err, "failed to merge config files")
+			}
+
+			squadronName, unitNames := parseSquadronAndUnitNames(args)
+			if err := sq.FilterConfig(cmd.Context(), squadronName, unitNames, x.GetStringSlice("tags")); err != nil {
+				return errors.Wrap(err, "failed to filter config")
+			}
+
+			if err := sq.RenderConfig(cmd.Context()); err != nil {
+				return errors.Wrap(err, "failed to render config")
+			}
+
+			// only prune charts if all units have been resolved
+			prune := squadronName == "" && len(x.GetStringSlice("tags")) == 0
+
+			return sq.LockCharts(cmd.Context(), prune, x.GetInt("parallel"))
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.Int("parallel", 1, "run command in parallel")
+	_ = x.BindPFlag("parallel", flags.Lookup("parallel"))
+
+	flags.StringSlice("tags", nil, "list of tags to include or exclude (can specify multiple or separate values with commas: tag1,tag2,-tag3)")
+	_ = x.BindPFlag("tags", flags.Lookup("tags"))
+
+	return cmd
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"os"
+
+	"github.com/foomo/squadron/config"
+	"github.com/foomo/squadron/internal/migrate"
+	"github.com/foomo/squadron/internal/util"
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func NewMigrate(c *viper.Viper) *cobra.Command {
+	x := viper.New()
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "migrates the squadron files to the current config version",
+		Long: "migrates the squadron files to the current config version\n\n" +
+			"Only files of version " + migrate.DefaultRegistry.Oldest() + " or newer can be migrated, older files have to be upgraded by hand first.",
+		Example: "  squadron migrate --dry-run",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, file := range c.GetStringSlice("file") {
+				info, err := os.Stat(file)
+				if err != nil {
+					return errors.Wrapf(err, "failed to stat `%s`", file)
+				}
+
+				data, err := os.ReadFile(file)
+				if err != nil {
+					return errors.Wrapf(err, "failed to read `%s`", file)
+				}
+
+				out, migrators, err := migrate.DefaultRegistry.Migrate(data, config.Version)
+				if err != nil {
+					return errors.Wrapf(err, "failed to migrate `%s`", file)
+				}
+
+				if len(migrators) == 0 {
+					pterm.Info.Printfln("✅ | %s is up to date", file)
+					continue
+				}
+
+				for _, m := range migrators {
+					pterm.Info.Printfln("🔀 | %s: %s ➜ %s (%s)", file, m.From, m.To, m.Description)
+				}
+
+				if x.GetBool("dry-run") {
+					diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+						A:        difflib.SplitLines(string(data)),
+						B:        difflib.SplitLines(string(out)),
+						FromFile: file + " (" + migrators[0].From + ")",
+						ToFile:   file + " (" + config.Version + ")",
+						Context:  3,
+					})
+					if err != nil {
+						return errors.Wrapf(err, "failed to diff `%s`", file)
+					}
+
+					if !x.GetBool("raw") {
+						diff = util.HighlightDiff(diff)
+					}
+
+					pterm.Println(diff)
+
+					continue
+				}
+
+				if err := os.WriteFile(file, out, info.Mode().Perm()); err != nil {
+					return errors.Wrapf(err, "failed to write `%s`", file)
+				}
+
+				pterm.Success.Printfln("💾 | migrated %s", file)
+			}
+
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.Bool("dry-run", false, "print the changes as diff instead of writing the files")
+	_ = x.BindPFlag("dry-run", flags.Lookup("dry-run"))
+
+	flags.Bool("raw", false, "print raw output without highlighting")
+	_ = x.BindPFlag("raw", flags.Lookup("raw"))
+
+	return cmd
+}
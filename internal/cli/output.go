@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/pkg/errors"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	outputJSON = "json"
+	outputYAML = "yaml"
+)
+
+// isStructuredOutput returns true if the output format is machine-readable
+func isStructuredOutput(format string) bool {
+	return slices.Contains([]string{outputJSON, outputYAML}, format)
+}
+
+// preRunOutput disables the regular output for machine-readable output formats
+func preRunOutput(x *viper.Viper) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		if isStructuredOutput(x.GetString("output")) {
+			pterm.DisableOutput()
+		}
+	}
+}
+
+// printOutput writes v in the given machine-readable format to stdout
+func printOutput(format string, v any) error {
+	pterm.EnableOutput()
+
+	return writeOutput(os.Stdout, format, v)
+}
+
+// writeOutput writes v in the given machine-readable format
+func writeOutput(w io.Writer, format string, v any) error {
+	switch format {
+	case outputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(v)
+	case outputYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+
+		return enc.Close()
+	default:
+		return errors.Errorf("unsupported output format: %s", format)
+	}
+}
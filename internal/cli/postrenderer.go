@@ -3,9 +3,8 @@ package cli
 import (
 	"io"
 	"os"
-	"os/exec"
-	"path"
 
+	"github.com/foomo/squadron/internal/helm"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -22,16 +21,7 @@ func NewPostRenderer(c *viper.Viper) *cobra.Command {
 				return err
 			}
 
-			err = os.WriteFile(path.Join(args[0], ".chart.yaml"), r, 0600)
-			if err != nil {
-				return err
-			}
-
-			c := exec.CommandContext(cmd.Context(), "kustomize", "build", args[0]) //nolint:gosec
-			c.Stdout = os.Stdout
-			c.Stderr = os.Stderr
-
-			return c.Run()
+			return helm.Kustomize(cmd.Context(), args[0], r, os.Stdout)
 		},
 	}
 
@@ -15,7 +15,7 @@ func NewPush(c *viper.Viper) *cobra.Command {
 		Short:   "pushes the squadron or given units",
 		Example: "  squadron push storefinder frontend backend --namespace demo --build",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			sq := squadron.New(cwd, x.GetString("namespace"), c.GetStringSlice("file"))
+			sq := newSquadron(x.GetString("namespace"), c.GetStringSlice("file"), squadron.WithBakeBuilds(x.GetBool("bake-builds")))
 
 			if err := sq.MergeConfigFiles(cmd.Context()); err != nil {
 				return errors.Wrap(err, "failed to merge config files")
@@ -42,17 +42,19 @@ func NewPush(c *viper.Viper) *cobra.Command {
 			}
 
 			if x.GetBool("build") {
-				if err := sq.Build(cmd.Context(), x.GetStringSlice("build-args"), x.GetInt("parallel")); err != nil {
+				if _, err := sq.Build(cmd.Context(), x.GetStringSlice("build-args"), x.GetInt("parallel")); err != nil {
 					return errors.Wrap(err, "failed to build units")
 				}
 			}
 
-			return sq.Push(cmd.Context(), x.GetStringSlice("push-args"), x.GetInt("parallel"))
+			_, err := sq.Push(cmd.Context(), x.GetStringSlice("push-args"), x.GetInt("parallel"))
+
+			return err
 		},
 	}
 
 	flags := cmd.Flags()
-	flags.StringP("namespace", "n", "default", "set the namespace name or template (default, squadron-{{.Squadron}}-{{.Unit}})")
+	flags.StringP("namespace", "n", "", "set the namespace name or template, takes precedence over the environment namespace (default, squadron-{{.Squadron}}-{{.Unit}})")
 	_ = x.BindPFlag("namespace", flags.Lookup("namespace"))
 
 	flags.Bool("build", false, "builds or rebuilds units")
@@ -67,6 +69,9 @@ func NewPush(c *viper.Viper) *cobra.Command {
 	flags.StringArray("bake-args", nil, "additional docker buildx bake args")
 	_ = x.BindPFlag("bake-args", flags.Lookup("bake-args"))
 
+	flags.Bool("bake-builds", false, "bakes the unit builds and their build dependencies as well")
+	_ = x.BindPFlag("bake-builds", flags.Lookup("bake-builds"))
+
 	flags.StringArray("build-args", nil, "additional docker buildx build args")
 	_ = x.BindPFlag("build-args", flags.Lookup("build-args"))
 
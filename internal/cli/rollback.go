@@ -1,11 +1,10 @@
 package cli
 
 import (
+	"github.com/foomo/squadron"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
-
-	"github.com/foomo/squadron"
 )
 
 func NewRollback(c *viper.Viper) *cobra.Command {
@@ -16,7 +15,7 @@ func NewRollback(c *viper.Viper) *cobra.Command {
 		Short:   "rolls back the squadron or given units",
 		Example: "  squadron rollback storefinder frontend backend --namespace demo",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			sq := squadron.New(cwd, c.GetString("namespace"), c.GetStringSlice("file"))
+			sq := newSquadron(x.GetString("namespace"), c.GetStringSlice("file"))
 
 			if err := sq.MergeConfigFiles(cmd.Context()); err != nil {
 				return errors.Wrap(err, "failed to merge config files")
@@ -24,12 +23,15 @@ func NewRollback(c *viper.Viper) *cobra.Command {
 
 			args, helmArgs := parseExtraArgs(args)
 
-			squadronName, unitNames := parseSquadronAndUnitNames(args)
-			if err := sq.FilterConfig(cmd.Context(), squadronName, unitNames, x.GetStringSlice("tags")); err != nil {
-				return errors.Wrap(err, "failed to filter config")
+			if err := filterConfig(cmd.Context(), x, sq, args); err != nil {
+				return err
 			}
 
-			return sq.Rollback(cmd.Context(), x.GetString("revision"), helmArgs, x.GetInt("parallel"))
+			return withLock(cmd.Context(), x, sq, func() error {
+				_, err := sq.Rollback(cmd.Context(), x.GetString("revision"), squadron.HelmOptions{ExtraArgs: helmArgs}, x.GetInt("parallel"))
+
+				return err
+			})
 		},
 	}
 
@@ -37,14 +39,19 @@ func NewRollback(c *viper.Viper) *cobra.Command {
 	flags.Int("parallel", 1, "run command in parallel")
 	_ = x.BindPFlag("parallel", flags.Lookup("parallel"))
 
-	flags.StringP("namespace", "n", "default", "set the namespace name or template (default, squadron-{{.Squadron}}-{{.Unit}})")
+	flags.StringP("namespace", "n", "", "set the namespace name or template, takes precedence over the environment namespace (default, squadron-{{.Squadron}}-{{.Unit}})")
 	_ = x.BindPFlag("namespace", flags.Lookup("namespace"))
 
 	flags.StringP("revision", "r", "", "specifies the revision to roll back to")
 	_ = x.BindPFlag("revision", flags.Lookup("revision"))
 
+	addLockFlags(flags, x)
+
 	flags.StringSlice("tags", nil, "list of tags to include or exclude (can specify multiple or separate values with commas: tag1,tag2,-tag3)")
 	_ = x.BindPFlag("tags", flags.Lookup("tags"))
 
+	flags.String("select", "", "select units by expression (e.g. 'squadron in (checkout,storefinder) && (tag:backend || tag:critical) && !tag:skip')")
+	_ = x.BindPFlag("select", flags.Lookup("select"))
+
 	return cmd
 }
@@ -1,14 +1,19 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"runtime/debug"
 	"strings"
+	"time"
 
 	cowsay "github.com/Code-Hex/Neo-cowsay/v2"
+	"github.com/foomo/squadron"
 	"github.com/foomo/squadron/internal/cmd"
+	templatex "github.com/foomo/squadron/internal/template"
 	"github.com/foomo/squadron/internal/util"
+	"github.com/pkg/errors"
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -30,6 +35,9 @@ func NewRoot() *cobra.Command {
 				pterm.EnableDebugMessages()
 			}
 
+			templatex.DefaultSecretRegistry.SetOffline(viper.GetBool("offline"))
+			templatex.DefaultSecretRegistry.SetCache(newSecretCache())
+
 			if cmd.Name() == "help" || cmd.Name() == "init" || cmd.Name() == "version" {
 				return nil
 			}
@@ -43,6 +51,18 @@ func NewRoot() *cobra.Command {
 
 	_ = viper.BindPFlag("debug", root.PersistentFlags().Lookup("debug"))
 
+	flags.Bool("offline", false, "use locked charts from the chart cache and fail if anything requires network access")
+	_ = viper.BindPFlag("offline", root.PersistentFlags().Lookup("offline"))
+
+	flags.Bool("no-secret-cache", false, "do not read or write the encrypted secret cache")
+	_ = viper.BindPFlag("no-secret-cache", root.PersistentFlags().Lookup("no-secret-cache"))
+
+	flags.Duration("secret-cache-ttl", time.Hour, "time to live of persisted secrets")
+	_ = viper.BindPFlag("secret-cache-ttl", root.PersistentFlags().Lookup("secret-cache-ttl"))
+
+	flags.StringP("env", "e", "", "apply the given environment from the squadron files")
+	_ = viper.BindPFlag("env", root.PersistentFlags().Lookup("env"))
+
 	flags.StringSliceP("file", "f", []string{"squadron.yaml"}, "specify alternative squadron files")
 
 	root.AddCommand(
@@ -61,6 +81,10 @@ func NewRoot() *cobra.Command {
 		NewTemplate(NewViper(root)),
 		NewPostRenderer(NewViper(root)),
 		NewSchema(NewViper(root)),
+		NewLock(NewViper(root)),
+		NewFetch(NewViper(root)),
+		NewValidate(NewViper(root)),
+		NewMigrate(NewViper(root)),
 	)
 
 	return root
@@ -73,6 +97,36 @@ func NewViper(root *cobra.Command) *viper.Viper {
 	return c
 }
 
+// newSquadron returns a squadron configured with the global flags
+func newSquadron(namespace string, files []string, opts ...squadron.Option) *squadron.Squadron {
+	return squadron.New(cwd, namespace, files, append([]squadron.Option{
+		squadron.WithEnv(viper.GetString("env")),
+		squadron.WithOffline(viper.GetBool("offline")),
+	}, opts...)...)
+}
+
+// newSecretCache returns a secret cache, persisted if `SQUADRON_SECRET_CACHE_KEY` is set
+func newSecretCache() *templatex.SecretCache {
+	key := os.Getenv("SQUADRON_SECRET_CACHE_KEY")
+	if key == "" || viper.GetBool("no-secret-cache") {
+		return templatex.NewSecretCache(0)
+	}
+
+	ret := templatex.NewSecretCache(viper.GetDuration("secret-cache-ttl"))
+
+	filename, err := templatex.SecretCacheFilename()
+	if err == nil {
+		err = ret.Persist(filename, key)
+	}
+
+	if err != nil {
+		pterm.Warning.Println("Not using the secret cache: " + err.Error())
+		return templatex.NewSecretCache(0)
+	}
+
+	return ret
+}
+
 func Execute() {
 	root := NewRoot()
 	l := cmd.NewLogger()
@@ -93,16 +147,23 @@ func Execute() {
 			l.Error(fmt.Sprintf("%v", r))
 			l.Error(string(debug.Stack()))
 
-			code = 1
+			code = exitCodeFailure
 		}
 
 		os.Exit(code)
 	}()
 
 	if err := root.Execute(); err != nil {
+		var exitErr *exitCodeError
+		if errors.As(err, &exitErr) {
+			code = exitErr.code
+			return
+		}
+
+		pterm.EnableOutput()
 		l.Error(util.SprintError(err))
 
-		code = 1
+		code = exitCodeFailure
 	}
 }
 
@@ -119,6 +180,22 @@ func parseExtraArgs(args []string) (out []string, extraArgs []string) { //nolint
 	return args, nil
 }
 
+// filterConfig filters the config by the squadron and unit args and the `tags` and `select` flags
+func filterConfig(ctx context.Context, x *viper.Viper, sq *squadron.Squadron, args []string) error {
+	squadronName, unitNames := parseSquadronAndUnitNames(args)
+	if err := sq.FilterConfig(ctx, squadronName, unitNames, x.GetStringSlice("tags")); err != nil {
+		return errors.Wrap(err, "failed to filter config")
+	}
+
+	if expr := x.GetString("select"); expr != "" {
+		if err := sq.SelectConfig(ctx, expr); err != nil {
+			return errors.Wrap(err, "failed to select config")
+		}
+	}
+
+	return nil
+}
+
 func parseSquadronAndUnitNames(args []string) (squadron string, units []string) { //nolint:nonamedreturns
 	if len(args) == 0 {
 		return "", nil
@@ -3,7 +3,6 @@ package cli
 import (
 	"os"
 
-	"github.com/foomo/squadron"
 	"github.com/foomo/squadron/internal/util"
 	"github.com/pkg/errors"
 	"github.com/pterm/pterm"
@@ -19,7 +18,7 @@ func NewSchema(c *viper.Viper) *cobra.Command {
 		Example: "  squadron schema",
 		Args:    cobra.MinimumNArgs(0),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			sq := squadron.New(cwd, "", c.GetStringSlice("file"))
+			sq := newSquadron("", c.GetStringSlice("file"))
 
 			if err := sq.MergeConfigFiles(cmd.Context()); err != nil {
 				return errors.Wrap(err, "failed to merge config files")
@@ -1,11 +1,14 @@
 package cli
 
 import (
+	"fmt"
+	"time"
+
+	"github.com/foomo/squadron"
 	"github.com/pkg/errors"
+	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
-
-	"github.com/foomo/squadron"
 )
 
 func NewStatus(c *viper.Viper) *cobra.Command {
@@ -15,8 +18,9 @@ func NewStatus(c *viper.Viper) *cobra.Command {
 		Use:     "status [SQUADRON] [UNIT...]",
 		Short:   "installs the squadron or given units",
 		Example: "  squadron status storefinder frontend backend --namespace demo",
+		PreRun:  preRunOutput(x),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			sq := squadron.New(cwd, x.GetString("namespace"), c.GetStringSlice("file"))
+			sq := newSquadron(x.GetString("namespace"), c.GetStringSlice("file"))
 
 			if err := sq.MergeConfigFiles(cmd.Context()); err != nil {
 				return errors.Wrap(err, "failed to merge config files")
@@ -24,12 +28,50 @@ func NewStatus(c *viper.Viper) *cobra.Command {
 
 			args, helmArgs := parseExtraArgs(args)
 
-			squadronName, unitNames := parseSquadronAndUnitNames(args)
-			if err := sq.FilterConfig(cmd.Context(), squadronName, unitNames, x.GetStringSlice("tags")); err != nil {
-				return errors.Wrap(err, "failed to filter config")
+			if err := filterConfig(cmd.Context(), x, sq, args); err != nil {
+				return err
+			}
+
+			items, err := sq.Status(cmd.Context(), squadron.HelmOptions{ExtraArgs: helmArgs}, x.GetInt("parallel"))
+			if err != nil {
+				return err
+			}
+
+			if output := x.GetString("output"); output != "" {
+				return printOutput(output, items)
+			}
+
+			tbd := pterm.TableData{
+				{"Name", "Revision", "Status", "User", "Branch", "Commit", "Squadron", "Last deployed", "Notes"},
 			}
 
-			return sq.Status(cmd.Context(), helmArgs, x.GetInt("parallel"))
+			for _, item := range items {
+				lastDeployed := item.LastDeployed
+				if t, err := time.Parse(time.RFC3339, item.LastDeployed); err == nil {
+					lastDeployed = t.Format(time.RFC822)
+				}
+
+				tbd = append(tbd, []string{
+					item.Name,
+					fmt.Sprintf("%d", item.Revision),
+					item.Status,
+					item.User,
+					item.Branch,
+					item.Commit,
+					item.Version,
+					lastDeployed,
+					item.Notes,
+				})
+			}
+
+			out, err := pterm.DefaultTable.WithHasHeader().WithData(tbd).Srender()
+			if err != nil {
+				return err
+			}
+
+			pterm.Println(out)
+
+			return nil
 		},
 	}
 
@@ -37,11 +79,17 @@ func NewStatus(c *viper.Viper) *cobra.Command {
 	flags.Int("parallel", 1, "run command in parallel")
 	_ = x.BindPFlag("parallel", flags.Lookup("parallel"))
 
-	flags.StringP("namespace", "n", "default", "set the namespace name or template (default, squadron-{{.Squadron}}-{{.Unit}})")
+	flags.StringP("namespace", "n", "", "set the namespace name or template, takes precedence over the environment namespace (default, squadron-{{.Squadron}}-{{.Unit}})")
 	_ = x.BindPFlag("namespace", flags.Lookup("namespace"))
 
+	flags.StringP("output", "o", "", "output format (json, yaml)")
+	_ = x.BindPFlag("output", flags.Lookup("output"))
+
 	flags.StringSlice("tags", nil, "list of tags to include or exclude (can specify multiple or separate values with commas: tag1,tag2,-tag3)")
 	_ = x.BindPFlag("tags", flags.Lookup("tags"))
 
+	flags.String("select", "", "select units by expression (e.g. 'squadron in (checkout,storefinder) && (tag:backend || tag:critical) && !tag:skip')")
+	_ = x.BindPFlag("select", flags.Lookup("select"))
+
 	return cmd
 }
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"bytes"
 	"os"
 
 	"github.com/foomo/squadron"
@@ -19,8 +20,9 @@ func NewTemplate(c *viper.Viper) *cobra.Command {
 		Short:   "render chart templates locally and display the output",
 		Example: "  squadron template storefinder frontend backend --namespace demo",
 		Args:    cobra.MinimumNArgs(0),
+		PreRun:  preRunOutput(x),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			sq := squadron.New(cwd, x.GetString("namespace"), c.GetStringSlice("file"))
+			sq := newSquadron(x.GetString("namespace"), c.GetStringSlice("file"))
 
 			if err := sq.MergeConfigFiles(cmd.Context()); err != nil {
 				return errors.Wrap(err, "failed to merge config files")
@@ -28,32 +30,42 @@ func NewTemplate(c *viper.Viper) *cobra.Command {
 
 			args, helmArgs := parseExtraArgs(args)
 
-			squadronName, unitNames := parseSquadronAndUnitNames(args)
-			if err := sq.FilterConfig(cmd.Context(), squadronName, unitNames, x.GetStringSlice("tags")); err != nil {
-				return errors.Wrap(err, "failed to filter config")
+			if err := filterConfig(cmd.Context(), x, sq, args); err != nil {
+				return err
 			}
 
 			if err := sq.RenderConfig(cmd.Context()); err != nil {
 				return errors.Wrap(err, "failed to render config")
 			}
 
+			if err := validate(cmd.Context(), x, sq); err != nil {
+				return err
+			}
+
 			if err := sq.UpdateLocalDependencies(cmd.Context(), x.GetInt("parallel")); err != nil {
 				return errors.Wrap(err, "failed to update dependencies")
 			}
 
-			out, err := sq.Template(cmd.Context(), helmArgs, x.GetInt("parallel"))
+			items, err := sq.Template(cmd.Context(), squadron.HelmOptions{ExtraArgs: helmArgs}, x.GetInt("parallel"))
 			if err != nil {
 				return errors.Wrap(err, "failed to render template")
 			}
 
+			output, outputFile := x.GetString("output"), x.GetString("output-file")
+			if output != "" && !isStructuredOutput(output) {
+				return errors.Errorf("unsupported output format: %s", output)
+			}
+
 			switch {
+			case outputFile != "":
+				pterm.Info.Printfln("💾 | writing output to %s", outputFile)
+				return writeTemplates(outputFile, output, items)
+			case output != "":
+				return printOutput(output, items)
 			case x.GetBool("raw"):
-				pterm.Println(out)
-			case x.GetString("output") != "":
-				pterm.Info.Printfln("💾 | writing outut to %s", x.GetString("output"))
-				return os.WriteFile(x.GetString("output"), []byte(out), 0600)
+				pterm.Println(items.String())
 			default:
-				pterm.Println(util.Highlight(out))
+				pterm.Println(util.Highlight(items.String()))
 			}
 
 			return nil
@@ -64,17 +76,41 @@ func NewTemplate(c *viper.Viper) *cobra.Command {
 	flags.Int("parallel", 1, "run command in parallel")
 	_ = x.BindPFlag("parallel", flags.Lookup("parallel"))
 
-	flags.StringP("namespace", "n", "default", "set the namespace name or template (default, squadron-{{.Squadron}}-{{.Unit}})")
+	flags.StringP("namespace", "n", "", "set the namespace name or template, takes precedence over the environment namespace (default, squadron-{{.Squadron}}-{{.Unit}})")
 	_ = x.BindPFlag("namespace", flags.Lookup("namespace"))
 
-	flags.String("output", "", "write the output to the given path")
+	flags.StringP("output", "o", "", "output format (json, yaml)")
 	_ = x.BindPFlag("output", flags.Lookup("output"))
 
+	flags.String("output-file", "", "write the output to the given path instead of stdout")
+	_ = x.BindPFlag("output-file", flags.Lookup("output-file"))
+
+	flags.Bool("validate", false, "validate the config and unit values against their schemas first")
+	_ = x.BindPFlag("validate", flags.Lookup("validate"))
+
 	flags.StringSlice("tags", nil, "list of tags to include or exclude (can specify multiple or separate values with commas: tag1,tag2,-tag3)")
 	_ = x.BindPFlag("tags", flags.Lookup("tags"))
 
+	flags.String("select", "", "select units by expression (e.g. 'squadron in (checkout,storefinder) && (tag:backend || tag:critical) && !tag:skip')")
+	_ = x.BindPFlag("select", flags.Lookup("select"))
+
 	flags.Bool("raw", false, "print raw output without highlighting")
 	_ = x.BindPFlag("raw", flags.Lookup("raw"))
 
 	return cmd
 }
+
+// writeTemplates writes the rendered templates, or their structured output if a format is given,
+// to the file
+func writeTemplates(filename, format string, items squadron.UnitTemplates) error {
+	if format == "" {
+		return os.WriteFile(filename, []byte(items.String()), 0600)
+	}
+
+	var buf bytes.Buffer
+	if err := writeOutput(&buf, format, items); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename, buf.Bytes(), 0600)
+}
@@ -1,12 +1,12 @@
 package cli
 
 import (
-	"os"
+	"fmt"
+	"strings"
 
 	"github.com/foomo/squadron"
-	"github.com/go-git/go-git/v5"
-	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/pkg/errors"
+	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -19,7 +19,7 @@ func NewUp(c *viper.Viper) *cobra.Command {
 		Short:   "installs the squadron or given units",
 		Example: "  squadron up storefinder frontend backend --namespace demo --build --push -- --dry-run",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			sq := squadron.New(cwd, x.GetString("namespace"), c.GetStringSlice("file"))
+			sq := newSquadron(x.GetString("namespace"), c.GetStringSlice("file"), squadron.WithBakeBuilds(x.GetBool("bake-builds")))
 
 			if err := sq.MergeConfigFiles(cmd.Context()); err != nil {
 				return errors.Wrap(err, "failed to merge config files")
@@ -27,15 +27,18 @@ func NewUp(c *viper.Viper) *cobra.Command {
 
 			args, helmArgs := parseExtraArgs(args)
 
-			squadronName, unitNames := parseSquadronAndUnitNames(args)
-			if err := sq.FilterConfig(cmd.Context(), squadronName, unitNames, x.GetStringSlice("tags")); err != nil {
-				return errors.Wrap(err, "failed to filter config")
+			if err := filterConfig(cmd.Context(), x, sq, args); err != nil {
+				return err
 			}
 
 			if err := sq.RenderConfig(cmd.Context()); err != nil {
 				return errors.Wrap(err, "failed to render config")
 			}
 
+			if err := validate(cmd.Context(), x, sq); err != nil {
+				return err
+			}
+
 			if x.GetBool("bake") {
 				bakefile, err := sq.Bakefile(cmd.Context())
 				if err != nil {
@@ -48,13 +51,13 @@ func NewUp(c *viper.Viper) *cobra.Command {
 			}
 
 			if x.GetBool("build") {
-				if err := sq.Build(cmd.Context(), x.GetStringSlice("build-args"), x.GetInt("parallel")); err != nil {
+				if _, err := sq.Build(cmd.Context(), x.GetStringSlice("build-args"), x.GetInt("parallel")); err != nil {
 					return errors.Wrap(err, "failed to build units")
 				}
 			}
 
 			if x.GetBool("push") {
-				if err := sq.Push(cmd.Context(), x.GetStringSlice("push-args"), x.GetInt("parallel")); err != nil {
+				if _, err := sq.Push(cmd.Context(), x.GetStringSlice("push-args"), x.GetInt("parallel")); err != nil {
 					return errors.Wrap(err, "failed to push units")
 				}
 			}
@@ -63,45 +66,27 @@ func NewUp(c *viper.Viper) *cobra.Command {
 				return err
 			}
 
-			status := squadron.Status{
-				Squadron: version,
-				User:     "unknown",
-			}
+			return withLock(cmd.Context(), x, sq, func() error {
+				if !x.GetBool("verify") {
+					_, err := sq.Up(cmd.Context(), squadron.HelmOptions{ExtraArgs: helmArgs}, newStatus(), x.GetInt("parallel"))
 
-			if wd, err := os.Getwd(); err == nil {
-				if value := os.Getenv("GIT_DIR"); value != "" {
-					wd = value
+					return err
 				}
 
-				if repo, err := git.PlainOpen(wd); err == nil {
-					if c, err := repo.Config(); err == nil {
-						status.User = c.User.Name
-					}
-
-					if ref, err := repo.Head(); err == nil {
-						status.Branch = ref.Name().Short()
-						status.Commit = ref.Hash().String()
-
-						if tags, err := repo.Tags(); err == nil {
-							_ = tags.ForEach(func(r *plumbing.Reference) error {
-								if r.Hash() == ref.Hash() {
-									status.Branch = r.Name().Short()
-									return errors.New("found tag")
-								}
-
-								return nil
-							})
-						}
+				_, items, err := sq.UpAndVerify(cmd.Context(), squadron.HelmOptions{ExtraArgs: helmArgs}, newStatus(), x.GetInt("parallel"))
+				if len(items) > 0 {
+					if verr := printVerification(items); err == nil {
+						err = verr
 					}
 				}
-			}
 
-			return sq.Up(cmd.Context(), helmArgs, status, x.GetInt("parallel"))
+				return err
+			})
 		},
 	}
 	flags := cmd.Flags()
 
-	flags.StringP("namespace", "n", "default", "set the namespace name or template (default, squadron-{{.Squadron}}-{{.Unit}})")
+	flags.StringP("namespace", "n", "", "set the namespace name or template, takes precedence over the environment namespace (default, squadron-{{.Squadron}}-{{.Unit}})")
 	_ = x.BindPFlag("namespace", flags.Lookup("namespace"))
 
 	flags.Bool("bake", false, "bakes or rebakes units")
@@ -119,14 +104,66 @@ func NewUp(c *viper.Viper) *cobra.Command {
 	flags.StringArray("bake-args", nil, "additional docker buildx bake args")
 	_ = x.BindPFlag("bake-args", flags.Lookup("bake-args"))
 
+	flags.Bool("bake-builds", false, "bakes the unit builds and their build dependencies as well")
+	_ = x.BindPFlag("bake-builds", flags.Lookup("bake-builds"))
+
 	flags.StringArray("build-args", nil, "additional docker buildx build args")
 	_ = x.BindPFlag("build-args", flags.Lookup("build-args"))
 
 	flags.StringArray("push-args", nil, "additional docker push args")
 	_ = x.BindPFlag("push-args", flags.Lookup("push-args"))
 
+	flags.Bool("verify", false, "wait for the workload rollouts of each dependency wave and roll back units that fail")
+	_ = x.BindPFlag("verify", flags.Lookup("verify"))
+
+	addLockFlags(flags, x)
+
+	flags.Bool("validate", false, "validate the config and unit values against their schemas first")
+	_ = x.BindPFlag("validate", flags.Lookup("validate"))
+
 	flags.StringSlice("tags", nil, "list of tags to include or exclude (can specify multiple or separate values with commas: tag1,tag2,-tag3)")
 	_ = x.BindPFlag("tags", flags.Lookup("tags"))
 
+	flags.String("select", "", "select units by expression (e.g. 'squadron in (checkout,storefinder) && (tag:backend || tag:critical) && !tag:skip')")
+	_ = x.BindPFlag("select", flags.Lookup("select"))
+
 	return cmd
 }
+
+// printVerification prints the verification summary and returns an error if any unit failed
+func printVerification(items []squadron.UnitVerification) error {
+	tbd := pterm.TableData{
+		{"Squadron", "Unit", "Namespace", "Revision", "Status", "Workloads", "Error"},
+	}
+
+	var failed int
+
+	for _, item := range items {
+		if item.Status != squadron.VerificationVerified {
+			failed++
+		}
+
+		tbd = append(tbd, []string{
+			item.Squadron,
+			item.Unit,
+			item.Namespace,
+			fmt.Sprintf("%d", item.Revision),
+			item.Status,
+			strings.Join(item.Workloads, "\n"),
+			item.Error,
+		})
+	}
+
+	out, err := pterm.DefaultTable.WithHasHeader().WithData(tbd).Srender()
+	if err != nil {
+		return err
+	}
+
+	pterm.Println(out)
+
+	if failed > 0 {
+		return errors.Errorf("%d of %d units failed verification", failed, len(items))
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/foomo/squadron"
+	"github.com/pkg/errors"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func NewValidate(c *viper.Viper) *cobra.Command {
+	x := viper.New()
+
+	cmd := &cobra.Command{
+		Use:     "validate [SQUADRON] [UNIT...]",
+		Short:   "validates the config and unit values against their schemas",
+		Example: "  squadron validate storefinder frontend backend",
+		Args:    cobra.MinimumNArgs(0),
+		PreRun:  preRunOutput(x),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sq := newSquadron(x.GetString("namespace"), c.GetStringSlice("file"))
+
+			if err := sq.MergeConfigFiles(cmd.Context()); err != nil {
+				return errors.Wrap(err, "failed to merge config files")
+			}
+
+			squadronName, unitNames := parseSquadronAndUnitNames(args)
+			if err := sq.FilterConfig(cmd.Context(), squadronName, unitNames, x.GetStringSlice("tags")); err != nil {
+				return errors.Wrap(err, "failed to filter config")
+			}
+
+			if err := sq.RenderConfig(cmd.Context()); err != nil {
+				return errors.Wrap(err, "failed to render config")
+			}
+
+			items, err := sq.Validate(cmd.Context(), x.GetInt("parallel"))
+			if err != nil {
+				return err
+			}
+
+			if output := x.GetString("output"); output != "" {
+				if err := printOutput(output, items); err != nil {
+					return err
+				}
+
+				if len(items) > 0 {
+					return &exitCodeError{code: exitCodeFailure}
+				}
+
+				return nil
+			}
+
+			return printViolations(items)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringP("namespace", "n", "", "set the namespace name or template, takes precedence over the environment namespace (default, squadron-{{.Squadron}}-{{.Unit}})")
+	_ = x.BindPFlag("namespace", flags.Lookup("namespace"))
+
+	flags.Int("parallel", 1, "run command in parallel")
+	_ = x.BindPFlag("parallel", flags.Lookup("parallel"))
+
+	flags.StringP("output", "o", "", "output format (json, yaml)")
+	_ = x.BindPFlag("output", flags.Lookup("output"))
+
+	flags.StringSlice("tags", nil, "list of tags to include or exclude (can specify multiple or separate values with commas: tag1,tag2,-tag3)")
+	_ = x.BindPFlag("tags", flags.Lookup("tags"))
+
+	return cmd
+}
+
+// validate runs the schema validation as a pre-flight check
+func validate(ctx context.Context, x *viper.Viper, sq *squadron.Squadron) error {
+	if !x.GetBool("validate") {
+		return nil
+	}
+
+	items, err := sq.Validate(ctx, x.GetInt("parallel"))
+	if err != nil {
+		return errors.Wrap(err, "failed to validate units")
+	}
+
+	return printViolations(items)
+}
+
+// printViolations prints the violations and returns an error if there are any
+func printViolations(items []squadron.UnitViolation) error {
+	if len(items) == 0 {
+		pterm.Success.Println("🔍 | no schema violations found")
+		return nil
+	}
+
+	tbd := pterm.TableData{
+		{"Squadron", "Unit", "Path", "Message", "Schema"},
+	}
+
+	for _, item := range items {
+		tbd = append(tbd, []string{
+			item.Squadron,
+			item.Unit,
+			item.Path,
+			item.Message,
+			item.Schema,
+		})
+	}
+
+	out, err := pterm.DefaultTable.WithHasHeader().WithData(tbd).Srender()
+	if err != nil {
+		return err
+	}
+
+	pterm.Println(out)
+
+	return errors.Errorf("found %d schema violations", len(items))
+}
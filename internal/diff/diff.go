@@ -0,0 +1,231 @@
+package diff
+
+import (
+	"bytes"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ActionAdded   = "added"
+	ActionRemoved = "removed"
+	ActionChanged = "changed"
+)
+
+const (
+	masked        = "***"
+	maskedChanged = "*** (changed)"
+)
+
+// Change describes the diff of a single resource
+type Change struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Action    string
+	Diff      string
+}
+
+// Resource is a single parsed manifest document
+type Resource struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Object    map[string]any
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public methods
+// ------------------------------------------------------------------------------------------------
+
+// Key returns the resource identifier (format: kind/namespace/name)
+func (c Change) Key() string {
+	return c.Kind + "/" + c.Namespace + "/" + c.Name
+}
+
+// Key returns the resource identifier (format: kind/namespace/name)
+func (r Resource) Key() string {
+	return r.Kind + "/" + r.Namespace + "/" + r.Name
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public functions
+// ------------------------------------------------------------------------------------------------
+
+// Manifests returns the unified diffs of all added, removed and changed resources sorted by key.
+// Resources without namespace are assigned to the given namespace.
+func Manifests(from, to, namespace string, maskSecrets bool) ([]Change, error) {
+	fromResources, err := Split(from, namespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to split installed manifest")
+	}
+
+	toResources, err := Split(to, namespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to split local manifest")
+	}
+
+	keys := make([]string, 0, len(fromResources)+len(toResources))
+	for key := range fromResources {
+		keys = append(keys, key)
+	}
+
+	for key := range toResources {
+		if _, ok := fromResources[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	slices.Sort(keys)
+
+	var ret []Change
+
+	for _, key := range keys {
+		fromResource, fromOK := fromResources[key]
+		toResource, toOK := toResources[key]
+
+		if maskSecrets {
+			mask(fromResource, toResource)
+		}
+
+		change := Change{
+			Kind:      toResource.Kind,
+			Namespace: toResource.Namespace,
+			Name:      toResource.Name,
+		}
+
+		switch {
+		case !fromOK:
+			change.Action = ActionAdded
+		case !toOK:
+			change.Action = ActionRemoved
+			change.Kind = fromResource.Kind
+			change.Namespace = fromResource.Namespace
+			change.Name = fromResource.Name
+		default:
+			change.Action = ActionChanged
+		}
+
+		fromYAML, err := marshal(fromResource.Object)
+		if err != nil {
+			return nil, err
+		}
+
+		toYAML, err := marshal(toResource.Object)
+		if err != nil {
+			return nil, err
+		}
+
+		if fromYAML == toYAML {
+			continue
+		}
+
+		change.Diff, err = difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(fromYAML),
+			B:        difflib.SplitLines(toYAML),
+			FromFile: key + " (installed)",
+			ToFile:   key + " (local)",
+			Context:  3,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to diff resource `%s`", key)
+		}
+
+		ret = append(ret, change)
+	}
+
+	return ret, nil
+}
+
+// Split parses the multi document manifest into resources by key
+func Split(manifest, namespace string) (map[string]Resource, error) {
+	ret := map[string]Resource{}
+
+	decoder := yaml.NewDecoder(strings.NewReader(manifest))
+
+	for {
+		var object map[string]any
+		if err := decoder.Decode(&object); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		if len(object) == 0 {
+			continue
+		}
+
+		resource := Resource{
+			Namespace: namespace,
+			Object:    object,
+		}
+
+		resource.Kind, _ = object["kind"].(string)
+		if metadata, ok := object["metadata"].(map[string]any); ok {
+			resource.Name, _ = metadata["name"].(string)
+			if value, ok := metadata["namespace"].(string); ok && value != "" {
+				resource.Namespace = value
+			}
+		}
+
+		ret[resource.Key()] = resource
+	}
+
+	return ret, nil
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private functions
+// ------------------------------------------------------------------------------------------------
+
+// mask replaces the secret values of both resources while keeping changed values distinguishable
+func mask(from, to Resource) {
+	for _, field := range []string{"data", "stringData"} {
+		fromData := secretData(from, field)
+		toData := secretData(to, field)
+
+		for key, value := range toData {
+			if fromValue, ok := fromData[key]; ok && fromValue != value {
+				toData[key] = maskedChanged
+			} else {
+				toData[key] = masked
+			}
+		}
+
+		for key := range fromData {
+			fromData[key] = masked
+		}
+	}
+}
+
+func secretData(r Resource, field string) map[string]any {
+	if r.Kind != "Secret" || r.Object == nil {
+		return nil
+	}
+
+	ret, _ := r.Object[field].(map[string]any)
+
+	return ret
+}
+
+func marshal(v map[string]any) (string, error) {
+	if v == nil {
+		return "", nil
+	}
+
+	var ret bytes.Buffer
+
+	encoder := yaml.NewEncoder(&ret)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(v); err != nil {
+		return "", errors.Wrap(err, "failed to marshal resource")
+	}
+
+	return ret.String(), nil
+}
@@ -0,0 +1,131 @@
+package diff_test
+
+import (
+	"testing"
+
+	testingx "github.com/foomo/go/testing"
+	tagx "github.com/foomo/go/testing/tag"
+	"github.com/foomo/squadron/internal/diff"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const installed = `---
+# Source: backend/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: backend
+spec:
+  type: ClusterIP
+---
+# Source: backend/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: backend
+data:
+  foo: bar
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: backend
+  namespace: other
+data:
+  password: c2VjcmV0
+  username: YWRtaW4=
+`
+
+const local = `---
+apiVersion: v1
+kind: Service
+metadata:
+  name: backend
+spec:
+  type: ClusterIP
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: backend
+  namespace: other
+data:
+  password: Y2hhbmdlZA==
+  username: YWRtaW4=
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: backend
+spec:
+  replicas: 1
+`
+
+func TestSplit(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	resources, err := diff.Split(installed, "default")
+	require.NoError(t, err)
+
+	keys := make([]string, 0, len(resources))
+	for key := range resources {
+		keys = append(keys, key)
+	}
+
+	assert.ElementsMatch(t, []string{
+		"Service/default/backend",
+		"ConfigMap/default/backend",
+		"Secret/other/backend",
+	}, keys)
+}
+
+func TestManifests(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	t.Run("changes", func(t *testing.T) {
+		changes, err := diff.Manifests(installed, local, "default", false)
+		require.NoError(t, err)
+		require.Len(t, changes, 3)
+
+		assert.Equal(t, "ConfigMap/default/backend", changes[0].Key())
+		assert.Equal(t, diff.ActionRemoved, changes[0].Action)
+		assert.Contains(t, changes[0].Diff, "-  foo: bar\n")
+
+		assert.Equal(t, "Deployment/default/backend", changes[1].Key())
+		assert.Equal(t, diff.ActionAdded, changes[1].Action)
+		assert.Contains(t, changes[1].Diff, "+  replicas: 1\n")
+
+		assert.Equal(t, "Secret/other/backend", changes[2].Key())
+		assert.Equal(t, diff.ActionChanged, changes[2].Action)
+		assert.Equal(t, `--- Secret/other/backend (installed)
++++ Secret/other/backend (local)
+@@ -1,6 +1,6 @@
+ apiVersion: v1
+ data:
+-  password: c2VjcmV0
++  password: Y2hhbmdlZA==
+   username: YWRtaW4=
+ kind: Secret
+ metadata:
+`, changes[2].Diff)
+	})
+
+	t.Run("mask secrets", func(t *testing.T) {
+		changes, err := diff.Manifests(installed, local, "default", true)
+		require.NoError(t, err)
+		require.Len(t, changes, 3)
+
+		assert.NotContains(t, changes[2].Diff, "c2VjcmV0")
+		assert.NotContains(t, changes[2].Diff, "Y2hhbmdlZA==")
+		assert.Contains(t, changes[2].Diff, "-  password: '***'\n")
+		assert.Contains(t, changes[2].Diff, "+  password: '*** (changed)'\n")
+		assert.Contains(t, changes[2].Diff, "   username: '***'\n")
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		changes, err := diff.Manifests(installed, installed, "default", true)
+		require.NoError(t, err)
+		assert.Empty(t, changes)
+	})
+}
@@ -0,0 +1,176 @@
+package dockerignore
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Matcher matches paths relative to the build context against the patterns of a `.dockerignore`
+type Matcher struct {
+	patterns []pattern
+}
+
+type pattern struct {
+	re        *regexp.Regexp
+	exclusion bool
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Constructor
+// ------------------------------------------------------------------------------------------------
+
+// New returns a matcher for the given patterns
+func New(patterns ...string) (*Matcher, error) {
+	ret := &Matcher{}
+
+	for _, value := range patterns {
+		value = strings.TrimSpace(value)
+		if value == "" || strings.HasPrefix(value, "#") {
+			continue
+		}
+
+		var p pattern
+		if value[0] == '!' {
+			p.exclusion = true
+			value = strings.TrimSpace(value[1:])
+		}
+
+		value = strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(value)), "/")
+		if value == "" {
+			continue
+		}
+
+		re, err := compile(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid pattern `%s`", value)
+		}
+
+		p.re = re
+		ret.patterns = append(ret.patterns, p)
+	}
+
+	return ret, nil
+}
+
+// Load returns the matcher for the `.dockerignore` of the given build context
+func Load(dir string) (*Matcher, error) {
+	file, err := os.Open(filepath.Join(dir, ".dockerignore"))
+	if errors.Is(err, os.ErrNotExist) {
+		return New()
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to open .dockerignore")
+	}
+	defer file.Close()
+
+	var patterns []string
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		patterns = append(patterns, scanner.Text())
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read .dockerignore")
+	}
+
+	return New(patterns...)
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public methods
+// ------------------------------------------------------------------------------------------------
+
+// Matches returns true if the slash separated path or one of its parents is ignored, the last
+// matching pattern wins
+func (m *Matcher) Matches(name string) bool {
+	var ret bool
+
+	parents := strings.Split(name, "/")
+
+	for _, p := range m.patterns {
+		for i := len(parents); i > 0; i-- {
+			if p.re.MatchString(strings.Join(parents[:i], "/")) {
+				ret = !p.exclusion
+				break
+			}
+		}
+	}
+
+	return ret
+}
+
+// Exclusions returns true if any pattern re-includes paths, i.e. ignored directories may not be
+// skipped
+func (m *Matcher) Exclusions() bool {
+	for _, p := range m.patterns {
+		if p.exclusion {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private functions
+// ------------------------------------------------------------------------------------------------
+
+// compile translates the pattern to a regular expression like `filepath.Match` with the addition
+// of `**` matching any number of directories
+func compile(value string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+
+	sb.WriteString("^")
+
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case '*':
+			if i+1 < len(value) && value[i+1] == '*' {
+				i++
+				// `**/` also matches no directory at all
+				if i+1 < len(value) && value[i+1] == '/' {
+					i++
+					sb.WriteString("(.*/)?")
+				} else {
+					sb.WriteString(".*")
+				}
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(value[i:], ']')
+			if end < 0 {
+				return nil, errors.New("missing closing bracket")
+			}
+
+			class := value[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+
+			sb.WriteString("[" + class + "]")
+
+			i += end
+		case '\\':
+			if i+1 < len(value) {
+				i++
+			}
+
+			sb.WriteString(regexp.QuoteMeta(string(value[i])))
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	sb.WriteString("$")
+
+	return regexp.Compile(sb.String())
+}
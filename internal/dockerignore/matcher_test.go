@@ -0,0 +1,66 @@
+package dockerignore_test
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	testingx "github.com/foomo/go/testing"
+	tagx "github.com/foomo/go/testing/tag"
+	"github.com/foomo/squadron/internal/dockerignore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatcher_Matches(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	m, err := dockerignore.New(
+		"# comment",
+		"node_modules",
+		"/tmp/*.log",
+		"**/*.md",
+		"!README.md",
+		"docs/",
+		"?.txt",
+	)
+	require.NoError(t, err)
+	assert.True(t, m.Exclusions())
+
+	tests := map[string]bool{
+		"main.go":                   false,
+		"node_modules":              true,
+		"node_modules/pkg/index.js": true,
+		"web/node_modules/pkg.js":   false,
+		"tmp/debug.log":             true,
+		"tmp/nested/debug.log":      false,
+		"CHANGELOG.md":              true,
+		"api/CHANGELOG.md":          true,
+		"README.md":                 false,
+		"docs/index.html":           true,
+		"a.txt":                     true,
+		"ab.txt":                    false,
+	}
+
+	for name, want := range tests {
+		assert.Equal(t, want, m.Matches(name), name)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	dir := t.TempDir()
+
+	m, err := dockerignore.Load(dir)
+	require.NoError(t, err)
+	assert.False(t, m.Matches("main.go"))
+
+	require.NoError(t, os.WriteFile(path.Join(dir, ".dockerignore"), []byte(".git\n*.go\n!main.go\n"), 0600))
+
+	m, err = dockerignore.Load(dir)
+	require.NoError(t, err)
+	assert.True(t, m.Matches(".git/HEAD"))
+	assert.True(t, m.Matches("util.go"))
+	assert.False(t, m.Matches("main.go"))
+}
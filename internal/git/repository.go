@@ -0,0 +1,452 @@
+package git
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/go-git/go-git/v6/plumbing/storer"
+	"github.com/pkg/errors"
+)
+
+// Repository provides the metadata of a git repository, caching all results
+type Repository struct {
+	repo  *git.Repository
+	lock  sync.Mutex
+	cache map[string]any
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Constructor
+// ------------------------------------------------------------------------------------------------
+
+// Open opens the repository containing the given directory
+func Open(dir string) (*Repository, error) {
+	repo, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open git repository `%s`", dir)
+	}
+
+	return &Repository{
+		repo:  repo,
+		cache: map[string]any{},
+	}, nil
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public methods
+// ------------------------------------------------------------------------------------------------
+
+// Commit returns the hash of the HEAD commit
+func (r *Repository) Commit() (string, error) {
+	commit, err := r.head()
+	if err != nil {
+		return "", err
+	}
+
+	return commit.Hash.String(), nil
+}
+
+// AbbrevCommit returns the abbreviated hash of the HEAD commit
+func (r *Repository) AbbrevCommit() (string, error) {
+	commit, err := r.Commit()
+	if err != nil {
+		return "", err
+	}
+
+	return commit[:7], nil
+}
+
+// Branch returns the name of the checked out branch or an empty string if HEAD is detached
+func (r *Repository) Branch() (string, error) {
+	return cached(r, "branch", func() (string, error) {
+		ref, err := r.repo.Head()
+		if err != nil {
+			return "", errors.Wrap(err, "failed to resolve HEAD")
+		}
+
+		if !ref.Name().IsBranch() {
+			return "", nil
+		}
+
+		return ref.Name().Short(), nil
+	})
+}
+
+// Tag returns the tag pointing at HEAD or an empty string, preferring annotated tags, the newest
+// ones first, over lightweight tags like `git describe --tags --exact-match`
+func (r *Repository) Tag() (string, error) {
+	return cached(r, "tag", func() (string, error) {
+		commit, err := r.head()
+		if err != nil {
+			return "", err
+		}
+
+		tags, err := r.tags()
+		if err != nil {
+			return "", err
+		}
+
+		if names := tags[commit.Hash]; len(names) > 0 {
+			return names[0], nil
+		}
+
+		return "", nil
+	})
+}
+
+// Describe returns the nearest tag reachable from HEAD, suffixed with the number of commits since
+// and the hash abbreviated to 7 characters like `git describe --tags --always`. As with git, the
+// distance counts the commits not contained in the history of the tag and the nearest of the 10
+// most recent tagged commits is chosen.
+func (r *Repository) Describe() (string, error) {
+	return cached(r, "describe", func() (string, error) {
+		commit, err := r.head()
+		if err != nil {
+			return "", err
+		}
+
+		tags, err := r.tags()
+		if err != nil {
+			return "", err
+		}
+
+		if names := tags[commit.Hash]; len(names) > 0 {
+			return names[0], nil
+		}
+
+		candidates, err := r.describeCandidates(commit.Hash, tags)
+		if err != nil || len(candidates) == 0 {
+			return commit.Hash.String()[:7], err
+		}
+
+		total, err := r.countCommits(commit.Hash)
+		if err != nil {
+			return "", err
+		}
+
+		var (
+			best     plumbing.Hash
+			distance int
+		)
+
+		// the first found candidate wins ties
+		for i, candidate := range candidates {
+			count, err := r.countCommits(candidate)
+			if err != nil {
+				return "", err
+			}
+
+			if i == 0 || total-count < distance {
+				best, distance = candidate, total-count
+			}
+		}
+
+		return fmt.Sprintf("%s-%d-g%s", tags[best][0], distance, commit.Hash.String()[:7]), nil
+	})
+}
+
+// Dirty returns true if the worktree has uncommitted changes
+func (r *Repository) Dirty() (bool, error) {
+	return cached(r, "dirty", func() (bool, error) {
+		status, err := r.status()
+		if err != nil {
+			return false, err
+		}
+
+		return !status.IsClean(), nil
+	})
+}
+
+// CommitTime returns the committer time of the HEAD commit
+func (r *Repository) CommitTime() (time.Time, error) {
+	commit, err := r.head()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return commit.Committer.When, nil
+}
+
+// Author returns the author name and email of the HEAD commit
+func (r *Repository) Author() (string, string, error) {
+	commit, err := r.head()
+	if err != nil {
+		return "", "", err
+	}
+
+	return commit.Author.Name, commit.Author.Email, nil
+}
+
+// OriginURL returns the https URL of the origin remote
+func (r *Repository) OriginURL() (string, error) {
+	return cached(r, "origin", func() (string, error) {
+		remote, err := r.repo.Remote("origin")
+		if err != nil {
+			return "", errors.Wrap(err, "failed to get origin remote")
+		}
+
+		if urls := remote.Config().URLs; len(urls) > 0 {
+			return OriginURL(urls[0]), nil
+		}
+
+		return "", nil
+	})
+}
+
+// Changed returns the sorted files changed since the given revision including uncommitted changes
+// like `git diff --name-only <rev>`, optionally limited to the given directories or files
+func (r *Repository) Changed(rev string, paths ...string) ([]string, error) {
+	all, err := cached(r, "changed\x00"+rev, func() ([]string, error) {
+		commit, err := r.head()
+		if err != nil {
+			return nil, err
+		}
+
+		hash, err := r.repo.ResolveRevision(plumbing.Revision(rev))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to resolve revision `%s`", rev)
+		}
+
+		base, err := r.repo.CommitObject(*hash)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get commit of `%s`", rev)
+		}
+
+		baseTree, err := base.Tree()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get tree of `%s`", rev)
+		}
+
+		headTree, err := commit.Tree()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get tree of HEAD")
+		}
+
+		changes, err := object.DiffTree(baseTree, headTree)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to diff `%s`", rev)
+		}
+
+		var ret []string
+
+		for _, change := range changes {
+			for _, name := range []string{change.From.Name, change.To.Name} {
+				if name != "" && !slices.Contains(ret, name) {
+					ret = append(ret, name)
+				}
+			}
+		}
+
+		status, err := r.status()
+		if err != nil {
+			return nil, err
+		}
+
+		for name, s := range status {
+			if (s.Staging != git.Unmodified || s.Worktree != git.Unmodified) && !slices.Contains(ret, name) {
+				ret = append(ret, name)
+			}
+		}
+
+		sort.Strings(ret)
+
+		return ret, nil
+	})
+	if err != nil || len(paths) == 0 {
+		return slices.Clone(all), err
+	}
+
+	ret := []string{}
+
+	for _, name := range all {
+		for _, p := range paths {
+			if p = path.Clean(p); p == "." || name == p || strings.HasPrefix(name, p+"/") {
+				ret = append(ret, name)
+				break
+			}
+		}
+	}
+
+	return ret, nil
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private methods
+// ------------------------------------------------------------------------------------------------
+
+func (r *Repository) head() (*object.Commit, error) {
+	return cached(r, "head", func() (*object.Commit, error) {
+		ref, err := r.repo.Head()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to resolve HEAD")
+		}
+
+		commit, err := r.repo.CommitObject(ref.Hash())
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get HEAD commit")
+		}
+
+		return commit, nil
+	})
+}
+
+// tags returns the tag names by commit in the order of preference of `git describe`: annotated
+// tags before lightweight tags, newer annotated tags first, then by name
+func (r *Repository) tags() (map[plumbing.Hash][]string, error) {
+	return cached(r, "tags", func() (map[plumbing.Hash][]string, error) {
+		iter, err := r.repo.Tags()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list tags")
+		}
+
+		type tag struct {
+			name      string
+			annotated bool
+			when      time.Time
+		}
+
+		all := map[plumbing.Hash][]tag{}
+
+		if err := iter.ForEach(func(ref *plumbing.Reference) error {
+			hash := ref.Hash()
+			item := tag{name: ref.Name().Short()}
+
+			// resolve annotated tags to their commit
+			if object, err := r.repo.TagObject(hash); err == nil {
+				commit, err := object.Commit()
+				if err != nil {
+					return nil //nolint:nilerr
+				}
+
+				hash = commit.Hash
+				item.annotated = true
+				item.when = object.Tagger.When
+			}
+
+			all[hash] = append(all[hash], item)
+
+			return nil
+		}); err != nil {
+			return nil, errors.Wrap(err, "failed to list tags")
+		}
+
+		ret := make(map[plumbing.Hash][]string, len(all))
+
+		for hash, items := range all {
+			slices.SortFunc(items, func(a, b tag) int {
+				switch {
+				case a.annotated != b.annotated && a.annotated:
+					return -1
+				case a.annotated != b.annotated:
+					return 1
+				case !a.when.Equal(b.when):
+					return b.when.Compare(a.when)
+				default:
+					return strings.Compare(a.name, b.name)
+				}
+			})
+
+			for _, item := range items {
+				ret[hash] = append(ret[hash], item.name)
+			}
+		}
+
+		return ret, nil
+	})
+}
+
+// describeCandidates returns up to 10 tagged commits reachable from the given commit, the most
+// recently committed first
+func (r *Repository) describeCandidates(hash plumbing.Hash, tags map[plumbing.Hash][]string) ([]plumbing.Hash, error) {
+	iter, err := r.repo.Log(&git.LogOptions{From: hash, Order: git.LogOrderCommitterTime})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to walk commits")
+	}
+
+	var ret []plumbing.Hash
+
+	if err := iter.ForEach(func(c *object.Commit) error {
+		if _, ok := tags[c.Hash]; ok {
+			if ret = append(ret, c.Hash); len(ret) == 10 {
+				return storer.ErrStop
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, errors.Wrap(err, "failed to walk commits")
+	}
+
+	return ret, nil
+}
+
+// countCommits returns the number of commits in the history of the given commit including itself
+func (r *Repository) countCommits(hash plumbing.Hash) (int, error) {
+	return cached(r, "count\x00"+hash.String(), func() (int, error) {
+		iter, err := r.repo.Log(&git.LogOptions{From: hash})
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to walk commits")
+		}
+
+		var ret int
+
+		if err := iter.ForEach(func(*object.Commit) error {
+			ret++
+			return nil
+		}); err != nil {
+			return 0, errors.Wrap(err, "failed to walk commits")
+		}
+
+		return ret, nil
+	})
+}
+
+func (r *Repository) status() (git.Status, error) {
+	return cached(r, "status", func() (git.Status, error) {
+		wt, err := r.repo.Worktree()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get worktree")
+		}
+
+		status, err := wt.Status()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get worktree status")
+		}
+
+		return status, nil
+	})
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private functions
+// ------------------------------------------------------------------------------------------------
+
+// cached returns the cached result of the given key or stores the result of the function
+func cached[T any](r *Repository, key string, fn func() (T, error)) (T, error) {
+	r.lock.Lock()
+	value, ok := r.cache[key]
+	r.lock.Unlock()
+
+	if ok {
+		return value.(T), nil //nolint:forcetypeassert
+	}
+
+	ret, err := fn()
+	if err != nil {
+		return ret, err
+	}
+
+	r.lock.Lock()
+	r.cache[key] = ret
+	r.lock.Unlock()
+
+	return ret, nil
+}
@@ -0,0 +1,215 @@
+package git_test
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	testingx "github.com/foomo/go/testing"
+	tagx "github.com/foomo/go/testing/tag"
+	"github.com/foomo/squadron/internal/git"
+	gogit "github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/config"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// initRepository creates a repository with two commits, the first one tagged `v1.0.0`
+func initRepository(t *testing.T) (string, plumbing.Hash, plumbing.Hash) {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	repo, err := gogit.PlainInit(dir, false)
+	require.NoError(t, err)
+
+	_, err = repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{"git@github.com:foomo/squadron.git"}})
+	require.NoError(t, err)
+
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+
+	when := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	commit := func(name string) plumbing.Hash {
+		require.NoError(t, os.MkdirAll(path.Dir(path.Join(dir, name)), 0700))
+		require.NoError(t, os.WriteFile(path.Join(dir, name), []byte(name), 0600))
+
+		_, err := wt.Add(name)
+		require.NoError(t, err)
+
+		hash, err := wt.Commit("add "+name, &gogit.CommitOptions{
+			Author: &object.Signature{Name: "Alice", Email: "alice@example.com", When: when},
+		})
+		require.NoError(t, err)
+
+		return hash
+	}
+
+	first := commit("README.md")
+
+	_, err = repo.CreateTag("v1.0.0", first, nil)
+	require.NoError(t, err)
+
+	second := commit("backend/main.go")
+
+	return dir, first, second
+}
+
+func TestRepository(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	dir, _, head := initRepository(t)
+
+	r, err := git.Open(path.Join(dir, "backend"))
+	require.NoError(t, err)
+
+	commit, err := r.Commit()
+	require.NoError(t, err)
+	assert.Equal(t, head.String(), commit)
+
+	abbrev, err := r.AbbrevCommit()
+	require.NoError(t, err)
+	assert.Equal(t, head.String()[:7], abbrev)
+
+	describe, err := r.Describe()
+	require.NoError(t, err)
+	assert.Equal(t, "v1.0.0-1-g"+abbrev, describe)
+
+	repo, err := gogit.PlainOpen(dir)
+	require.NoError(t, err)
+
+	ref, err := repo.Head()
+	require.NoError(t, err)
+
+	branch, err := r.Branch()
+	require.NoError(t, err)
+	assert.Equal(t, ref.Name().Short(), branch)
+
+	tag, err := r.Tag()
+	require.NoError(t, err)
+	assert.Empty(t, tag)
+
+	committed, err := r.CommitTime()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1704164645), committed.Unix())
+
+	name, email, err := r.Author()
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", name)
+	assert.Equal(t, "alice@example.com", email)
+
+	origin, err := r.OriginURL()
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/foomo/squadron", origin)
+
+	dirty, err := r.Dirty()
+	require.NoError(t, err)
+	assert.False(t, dirty)
+
+	changed, err := r.Changed("v1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backend/main.go"}, changed)
+
+	changed, err = r.Changed("v1.0.0", "frontend")
+	require.NoError(t, err)
+	assert.Empty(t, changed)
+}
+
+func TestRepository_uncommitted(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	dir, first, _ := initRepository(t)
+	require.NoError(t, os.WriteFile(path.Join(dir, "README.md"), []byte("changed"), 0600))
+
+	r, err := git.Open(dir)
+	require.NoError(t, err)
+
+	dirty, err := r.Dirty()
+	require.NoError(t, err)
+	assert.True(t, dirty)
+
+	changed, err := r.Changed("HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"README.md"}, changed)
+
+	changed, err = r.Changed(first.String(), "backend", "README.md")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"README.md", "backend/main.go"}, changed)
+
+	_, err = r.Changed("unknown")
+	require.Error(t, err)
+}
+
+func TestRepository_Describe(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	dir := t.TempDir()
+
+	repo, err := gogit.PlainInit(dir, false)
+	require.NoError(t, err)
+
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+
+	when := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	commit := func(message string, parents ...plumbing.Hash) plumbing.Hash {
+		when = when.Add(time.Minute)
+		signature := &object.Signature{Name: "Alice", Email: "alice@example.com", When: when}
+
+		hash, err := wt.Commit(message, &gogit.CommitOptions{AllowEmptyCommits: true, Author: signature, Committer: signature, Parents: parents})
+		require.NoError(t, err)
+
+		return hash
+	}
+
+	annotate := func(name string, hash plumbing.Hash, when time.Time) {
+		_, err := repo.CreateTag(name, hash, &gogit.CreateTagOptions{
+			Tagger:  &object.Signature{Name: "Alice", Email: "alice@example.com", When: when},
+			Message: name,
+		})
+		require.NoError(t, err)
+	}
+
+	// c1 (v1.0.0) - c2 ---------------- merge
+	//             \                    /
+	//              s1 (v2.0.0) - s2 - s3
+	c1 := commit("c1")
+	_, err = repo.CreateTag("v1.0.0", c1, nil)
+	require.NoError(t, err)
+
+	s1 := commit("s1", c1)
+	annotate("v2.0.0", s1, when)
+	s3 := commit("s3", commit("s2", s1))
+	c2 := commit("c2", c1)
+	merge := commit("merge", c2, s3)
+
+	r, err := git.Open(dir)
+	require.NoError(t, err)
+
+	// git counts the commits not in the history of the tag instead of the shortest path
+	describe, err := r.Describe()
+	require.NoError(t, err)
+	assert.Equal(t, "v2.0.0-4-g"+merge.String()[:7], describe)
+
+	// annotated tags take precedence over lightweight ones, the newest first
+	_, err = repo.CreateTag("latest", merge, nil)
+	require.NoError(t, err)
+	annotate("v3.0.0-rc.1", merge, when)
+	annotate("v3.0.0", merge, when.Add(time.Hour))
+
+	r, err = git.Open(dir)
+	require.NoError(t, err)
+
+	describe, err = r.Describe()
+	require.NoError(t, err)
+	assert.Equal(t, "v3.0.0", describe)
+
+	tag, err := r.Tag()
+	require.NoError(t, err)
+	assert.Equal(t, "v3.0.0", tag)
+}
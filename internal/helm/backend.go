@@ -0,0 +1,76 @@
+package helm
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/pterm/pterm"
+)
+
+const (
+	BackendSDK = "sdk"
+	BackendCLI = "cli"
+)
+
+// Options of the release operations
+type Options struct {
+	// Timeout of every operation, defaults to 5m
+	Timeout time.Duration
+	// Wait for the resources of the release to become ready
+	Wait bool
+	// Args passed on to the helm cli
+	Args []string
+}
+
+// ErrReleaseNotFound is returned by all backends if the requested release does not exist
+var ErrReleaseNotFound = errors.New("release not found")
+
+// ReleaseBackend executes release operations against the cluster
+type ReleaseBackend interface {
+	// Upgrade installs or upgrades the release
+	Upgrade(ctx context.Context, r Release) error
+	// DryRun returns the manifest an upgrade of the release would apply
+	DryRun(ctx context.Context, r Release) (string, error)
+	// Template renders the release manifests including hooks without contacting the cluster
+	Template(ctx context.Context, r Release) (string, error)
+	// Manifest returns the manifest of the installed release
+	Manifest(ctx context.Context, name, namespace string) (string, error)
+	// Status returns the status of the installed release
+	Status(ctx context.Context, name, namespace string) (*ReleaseStatus, error)
+	// Rollback rolls the release back to the given or previous revision
+	Rollback(ctx context.Context, name, namespace, revision string) error
+	// Uninstall removes the release
+	Uninstall(ctx context.Context, name, namespace string) error
+	// DependencyUpdate updates the dependencies of the local chart
+	DependencyUpdate(ctx context.Context, chartPath string) error
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Constructor
+// ------------------------------------------------------------------------------------------------
+
+// NewReleaseBackend returns the helm sdk backend. The helm cli backend is used as fallback
+// if extra helm args are given or if it is requested through `SQUADRON_HELM_BACKEND=cli`.
+func NewReleaseBackend(opts Options) (ReleaseBackend, error) {
+	switch backend := os.Getenv("SQUADRON_HELM_BACKEND"); backend {
+	case BackendCLI:
+		return newCLIBackend(opts), nil
+	case BackendSDK:
+		if len(opts.Args) > 0 {
+			return nil, errors.Errorf("extra helm args are not supported by the `%s` backend", BackendSDK)
+		}
+
+		return newSDKBackend(opts), nil
+	case "":
+		if len(opts.Args) > 0 {
+			pterm.Debug.Println("using helm cli backend for extra helm args")
+			return newCLIBackend(opts), nil
+		}
+
+		return newSDKBackend(opts), nil
+	default:
+		return nil, errors.Errorf("unknown helm backend `%s`", backend)
+	}
+}
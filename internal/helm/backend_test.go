@@ -0,0 +1,93 @@
+package helm_test
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	testingx "github.com/foomo/go/testing"
+	tagx "github.com/foomo/go/testing/tag"
+	"github.com/foomo/squadron/internal/helm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewReleaseBackend(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	t.Run("default", func(t *testing.T) {
+		t.Setenv("SQUADRON_HELM_BACKEND", "")
+
+		backend, err := helm.NewReleaseBackend(helm.Options{})
+		require.NoError(t, err)
+		assert.IsType(t, &helm.SDKBackend{}, backend)
+	})
+
+	t.Run("helm args", func(t *testing.T) {
+		t.Setenv("SQUADRON_HELM_BACKEND", "")
+
+		backend, err := helm.NewReleaseBackend(helm.Options{Args: []string{"--wait"}})
+		require.NoError(t, err)
+		assert.IsType(t, &helm.CLIBackend{}, backend)
+	})
+
+	t.Run("cli", func(t *testing.T) {
+		t.Setenv("SQUADRON_HELM_BACKEND", helm.BackendCLI)
+
+		backend, err := helm.NewReleaseBackend(helm.Options{})
+		require.NoError(t, err)
+		assert.IsType(t, &helm.CLIBackend{}, backend)
+	})
+
+	t.Run("sdk with helm args", func(t *testing.T) {
+		t.Setenv("SQUADRON_HELM_BACKEND", helm.BackendSDK)
+
+		_, err := helm.NewReleaseBackend(helm.Options{Args: []string{"--wait"}})
+		require.Error(t, err)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		t.Setenv("SQUADRON_HELM_BACKEND", "foo")
+
+		_, err := helm.NewReleaseBackend(helm.Options{})
+		require.EqualError(t, err, "unknown helm backend `foo`")
+	})
+}
+
+func TestNewReleaseBackend_options(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+	t.Setenv("SQUADRON_HELM_BACKEND", helm.BackendCLI)
+
+	// the fake helm records its args
+	bin := t.TempDir()
+	out := path.Join(t.TempDir(), "args")
+	require.NoError(t, os.WriteFile(path.Join(bin, "helm"), []byte("#!/bin/sh\necho \"$@\" > "+out+"\n"), 0700)) //nolint:gosec
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	backend, err := helm.NewReleaseBackend(helm.Options{Timeout: time.Minute, Wait: true, Args: []string{"--skip-crds"}})
+	require.NoError(t, err)
+	require.NoError(t, backend.Uninstall(t.Context(), "frontend", "demo"))
+
+	args, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "uninstall frontend --namespace demo --timeout 1m0s --wait --skip-crds\n", string(args))
+}
+
+func TestNewRelease(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	t.Run("local", func(t *testing.T) {
+		r := helm.NewRelease("storefinder-frontend", "default", "frontend", "file:///charts/frontend/", "0.0.1")
+		assert.Equal(t, "/charts/frontend", r.Chart)
+		assert.Empty(t, r.Repository)
+		assert.Empty(t, r.Version)
+	})
+
+	t.Run("remote", func(t *testing.T) {
+		r := helm.NewRelease("storefinder-frontend", "default", "frontend", "https://helm.mycompany.com/repository", "0.0.1")
+		assert.Equal(t, "frontend", r.Chart)
+		assert.Equal(t, "https://helm.mycompany.com/repository", r.Repository)
+		assert.Equal(t, "0.0.1", r.Version)
+	})
+}
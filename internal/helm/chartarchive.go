@@ -0,0 +1,101 @@
+package helm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"helm.sh/helm/v4/pkg/action"
+	chartx "helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chart/loader"
+	"helm.sh/helm/v4/pkg/cli"
+)
+
+// ChartArchive describes a packaged chart
+type ChartArchive struct {
+	// Path to the archive
+	Path string
+	// Chart version
+	Version string
+	// Archive digest (format: sha256:<hex>)
+	Digest string
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public methods
+// ------------------------------------------------------------------------------------------------
+
+// Pull downloads the remote chart of the release into the helm repository cache
+func (b *SDKBackend) Pull(ctx context.Context, r Release) (ChartArchive, error) {
+	settings := cli.New()
+
+	registryClient, err := b.registryClient(settings)
+	if err != nil {
+		return ChartArchive{}, err
+	}
+
+	cfg := action.NewConfiguration()
+	cfg.RegistryClient = registryClient
+
+	client := action.NewInstall(cfg)
+	b.setChartPathOptions(&client.ChartPathOptions, r)
+
+	filename, err := client.LocateChart(r.Chart, settings)
+	if err != nil {
+		return ChartArchive{}, errors.Wrapf(err, "failed to pull chart `%s`", r.Chart)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return ChartArchive{}, err
+	}
+
+	return LoadChartArchive(filename)
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public functions
+// ------------------------------------------------------------------------------------------------
+
+// LoadChartArchive returns the version and digest of the given chart archive
+func LoadChartArchive(filename string) (ChartArchive, error) {
+	digest, err := Digest(filename)
+	if err != nil {
+		return ChartArchive{}, err
+	}
+
+	chart, err := loader.Load(filename)
+	if err != nil {
+		return ChartArchive{}, errors.Wrapf(err, "failed to load chart `%s`", filename)
+	}
+
+	accessor, err := chartx.NewAccessor(chart)
+	if err != nil {
+		return ChartArchive{}, err
+	}
+
+	return ChartArchive{
+		Path:    filename,
+		Version: fmt.Sprintf("%v", accessor.MetadataAsMap()["Version"]),
+		Digest:  digest,
+	}, nil
+}
+
+// Digest returns the sha256 digest of the given file (format: sha256:<hex>)
+func Digest(filename string) (string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", errors.Wrapf(err, "failed to hash `%s`", filename)
+	}
+
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,246 @@
+package helm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/foomo/squadron/internal/util"
+	"github.com/pkg/errors"
+)
+
+// CLIBackend executes release operations through the helm binary
+type CLIBackend struct {
+	helmArgs []string
+	timeout  time.Duration
+	wait     bool
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Constructor
+// ------------------------------------------------------------------------------------------------
+
+func NewCLIBackend(helmArgs ...string) *CLIBackend {
+	return &CLIBackend{
+		helmArgs: helmArgs,
+	}
+}
+
+func newCLIBackend(opts Options) *CLIBackend {
+	return &CLIBackend{
+		helmArgs: opts.Args,
+		timeout:  opts.Timeout,
+		wait:     opts.Wait,
+	}
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public methods
+// ------------------------------------------------------------------------------------------------
+
+func (b *CLIBackend) Upgrade(ctx context.Context, r Release) error {
+	cmd := b.releaseCommand(r, "upgrade", r.Name, "--install").
+		Args("--description", r.Description).
+		Args("--dependency-update")
+
+	if out, err := b.chartArgs(cmd, r).Run(ctx); err != nil {
+		return errors.Wrap(err, out)
+	}
+
+	return nil
+}
+
+func (b *CLIBackend) DryRun(ctx context.Context, r Release) (string, error) {
+	var stdout bytes.Buffer
+
+	cmd := b.releaseCommand(r, "upgrade", r.Name, "--install").
+		Stdout(&stdout).
+		Args("--hide-notes").
+		Args("--dry-run")
+
+	if out, err := b.chartArgs(cmd, r).Run(ctx); err != nil {
+		return "", errors.Wrap(err, out)
+	}
+
+	// strip the release information printed before the manifest
+	if _, after, ok := strings.Cut(stdout.String(), "MANIFEST:\n"); ok {
+		return after, nil
+	}
+
+	return stdout.String(), nil
+}
+
+func (b *CLIBackend) Template(ctx context.Context, r Release) (string, error) {
+	var stdout bytes.Buffer
+
+	cmd := b.releaseCommand(r, "template", r.Name).
+		Stdout(&stdout).
+		Args("--dependency-update").
+		Args("--debug")
+
+	if out, err := b.chartArgs(cmd, r).Run(ctx); err != nil {
+		return "", errors.Wrap(err, out)
+	}
+
+	return stdout.String(), nil
+}
+
+func (b *CLIBackend) Manifest(ctx context.Context, name, namespace string) (string, error) {
+	var stdout bytes.Buffer
+
+	out, err := util.NewHelmCommand().Args("get", "manifest", name).
+		Stdout(&stdout).
+		Args("--namespace", namespace).
+		Run(ctx)
+	if err != nil {
+		return "", b.wrapError(err, out)
+	}
+
+	return stdout.String(), nil
+}
+
+func (b *CLIBackend) Status(ctx context.Context, name, namespace string) (*ReleaseStatus, error) {
+	var (
+		stdout bytes.Buffer
+		status struct {
+			Name      string `json:"name"`
+			Version   int    `json:"version"`
+			Namespace string `json:"namespace"`
+			Info      struct {
+				Status       string `json:"status"`
+				LastDeployed string `json:"last_deployed"`
+				Description  string `json:"description"`
+			} `json:"info"`
+		}
+	)
+
+	out, err := util.NewHelmCommand().Args("status", name).
+		Stdout(&stdout).
+		Args("--namespace", namespace, "--output", "json", "--show-desc").
+		Args(b.helmArgs...).
+		Run(ctx)
+	if err != nil {
+		return nil, b.wrapError(err, out)
+	}
+
+	if err := json.Unmarshal(stdout.Bytes(), &status); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal status")
+	}
+
+	return &ReleaseStatus{
+		Name:         status.Name,
+		Namespace:    status.Namespace,
+		Revision:     status.Version,
+		Status:       status.Info.Status,
+		Description:  status.Info.Description,
+		LastDeployed: status.Info.LastDeployed,
+	}, nil
+}
+
+func (b *CLIBackend) Rollback(ctx context.Context, name, namespace, revision string) error {
+	cmd := util.NewHelmCommand().Args("rollback", name)
+	if revision != "" {
+		cmd.Args(revision)
+	}
+
+	if out, err := cmd.Args(b.waitArgs()...).Args(b.helmArgs...).Args("--namespace", namespace).Run(ctx); err != nil {
+		return b.wrapError(err, out)
+	}
+
+	return nil
+}
+
+func (b *CLIBackend) Uninstall(ctx context.Context, name, namespace string) error {
+	if out, err := util.NewHelmCommand().Args("uninstall", name).
+		Args("--namespace", namespace).
+		Args(b.waitArgs()...).
+		Args(b.helmArgs...).
+		Run(ctx); err != nil {
+		return b.wrapError(err, out)
+	}
+
+	return nil
+}
+
+func (b *CLIBackend) DependencyUpdate(ctx context.Context, chartPath string) error {
+	if out, err := util.NewHelmCommand().
+		Cwd(chartPath).
+		Args("dependency", "update", "--skip-refresh", "--debug").
+		Run(ctx); err != nil {
+		return errors.Wrap(err, out)
+	}
+
+	return nil
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private methods
+// ------------------------------------------------------------------------------------------------
+
+func (b *CLIBackend) releaseCommand(r Release, args ...string) *util.Cmd {
+	cmd := util.NewHelmCommand().Args(args...).
+		Stdin(bytes.NewReader(r.Values)).
+		Args("--namespace", r.Namespace)
+
+	for _, value := range r.Set {
+		cmd.Args("--set", value)
+	}
+
+	if r.Kustomize != "" {
+		cmd.Args(
+			"--post-renderer", "squadron",
+			"--post-renderer-args", "post-renderer",
+			"--post-renderer-args", r.Kustomize,
+		)
+	}
+
+	cmd.Args("--values", "-").
+		Args(b.waitArgs()...).
+		Args(b.helmArgs...)
+
+	return cmd
+}
+
+// waitArgs returns the args of the typed options
+func (b *CLIBackend) waitArgs() []string {
+	var ret []string
+
+	if b.timeout > 0 {
+		ret = append(ret, "--timeout", b.timeout.String())
+	}
+
+	if b.wait {
+		ret = append(ret, "--wait")
+	}
+
+	return ret
+}
+
+func (b *CLIBackend) chartArgs(cmd *util.Cmd, r Release) *util.Cmd {
+	cmd.Args(r.Chart)
+
+	if r.Repository != "" {
+		cmd.Args("--repo", r.Repository)
+	}
+
+	if r.Version != "" {
+		cmd.Args("--version", r.Version)
+	}
+
+	return cmd
+}
+
+// wrapError maps the helm not found error output to ErrReleaseNotFound
+func (b *CLIBackend) wrapError(err error, out string) error {
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	if strings.HasSuffix(strings.TrimSpace(out), "release: not found") {
+		return ErrReleaseNotFound
+	}
+
+	return errors.Wrap(err, out)
+}
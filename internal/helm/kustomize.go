@@ -0,0 +1,56 @@
+package helm
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path"
+
+	"github.com/foomo/squadron/internal/util"
+	"github.com/pkg/errors"
+)
+
+// KustomizePostRenderer passes the rendered manifests through `kustomize build`
+type KustomizePostRenderer struct {
+	ctx  context.Context //nolint:containedctx
+	path string
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Constructor
+// ------------------------------------------------------------------------------------------------
+
+func NewKustomizePostRenderer(ctx context.Context, path string) *KustomizePostRenderer {
+	return &KustomizePostRenderer{
+		ctx:  ctx,
+		path: path,
+	}
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public methods
+// ------------------------------------------------------------------------------------------------
+
+// Run implements postrenderer.PostRenderer
+func (p *KustomizePostRenderer) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	var ret bytes.Buffer
+	if err := Kustomize(p.ctx, p.path, renderedManifests.Bytes(), &ret); err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+// Kustomize writes the manifests as `.chart.yaml` into the kustomize directory and runs `kustomize build`
+func Kustomize(ctx context.Context, dir string, manifests []byte, w io.Writer) error {
+	if err := os.WriteFile(path.Join(dir, ".chart.yaml"), manifests, 0600); err != nil {
+		return errors.Wrap(err, "failed to write chart manifests")
+	}
+
+	if out, err := util.NewCommand("kustomize").Args("build", dir).Stdout(w).Run(ctx); err != nil {
+		return errors.Wrap(err, out)
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package helm
+
+import (
+	"path"
+	"strings"
+)
+
+// Release describes a helm release to be installed or rendered
+type Release struct {
+	// Release name
+	Name string
+	// Release namespace
+	Namespace string
+	// Chart name or path to a local chart
+	Chart string
+	// Chart repository url
+	Repository string
+	// Chart version
+	Version string
+	// Values yaml
+	Values []byte
+	// Values to set (format: key=value)
+	Set []string
+	// Release description
+	Description string
+	// Path to the kustomize directory used as post renderer
+	Kustomize string
+	// Fail instead of downloading missing chart dependencies
+	Offline bool
+}
+
+// ReleaseStatus describes the status of an installed release
+type ReleaseStatus struct {
+	// Release name
+	Name string
+	// Release namespace
+	Namespace string
+	// Release revision
+	Revision int
+	// Release status
+	Status string
+	// Release description
+	Description string
+	// Last deployment timestamp
+	LastDeployed string
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Constructor
+// ------------------------------------------------------------------------------------------------
+
+// NewRelease returns a release for the given chart reference, treating `file://` repositories as local charts
+func NewRelease(name, namespace, chart, repository, version string) Release {
+	ret := Release{
+		Name:      name,
+		Namespace: namespace,
+	}
+
+	if after, ok := strings.CutPrefix(repository, "file://"); ok {
+		ret.Chart = path.Clean(after)
+	} else {
+		ret.Chart = chart
+		ret.Repository = repository
+		ret.Version = version
+	}
+
+	return ret
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public methods
+// ------------------------------------------------------------------------------------------------
+
+// Remote returns true if the chart needs to be pulled from a repository or registry
+func (r Release) Remote() bool {
+	return r.Repository != "" || strings.HasPrefix(r.Chart, "oci://")
+}
@@ -0,0 +1,466 @@
+package helm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/pterm/pterm"
+	"helm.sh/helm/v4/pkg/action"
+	chartx "helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chart/common"
+	"helm.sh/helm/v4/pkg/chart/loader"
+	"helm.sh/helm/v4/pkg/cli"
+	"helm.sh/helm/v4/pkg/downloader"
+	"helm.sh/helm/v4/pkg/getter"
+	"helm.sh/helm/v4/pkg/kube"
+	"helm.sh/helm/v4/pkg/registry"
+	releasex "helm.sh/helm/v4/pkg/release"
+	releasecommon "helm.sh/helm/v4/pkg/release/common"
+	releasev1 "helm.sh/helm/v4/pkg/release/v1"
+	"helm.sh/helm/v4/pkg/storage/driver"
+	"helm.sh/helm/v4/pkg/strvals"
+)
+
+const defaultTimeout = 300 * time.Second
+
+// SDKBackend executes release operations through the helm sdk
+type SDKBackend struct {
+	timeout time.Duration
+	wait    bool
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Constructor
+// ------------------------------------------------------------------------------------------------
+
+func NewSDKBackend() *SDKBackend {
+	return &SDKBackend{}
+}
+
+func newSDKBackend(opts Options) *SDKBackend {
+	return &SDKBackend{
+		timeout: opts.Timeout,
+		wait:    opts.Wait,
+	}
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public methods
+// ------------------------------------------------------------------------------------------------
+
+func (b *SDKBackend) Upgrade(ctx context.Context, r Release) error {
+	_, err := b.upgrade(ctx, r, action.DryRunNone)
+	return err
+}
+
+func (b *SDKBackend) DryRun(ctx context.Context, r Release) (string, error) {
+	rel, err := b.upgrade(ctx, r, action.DryRunClient)
+	if err != nil {
+		return "", err
+	}
+
+	return rel.Manifest, nil
+}
+
+func (b *SDKBackend) Template(ctx context.Context, r Release) (string, error) {
+	settings, cfg, err := b.configuration(r.Namespace)
+	if err != nil {
+		return "", err
+	}
+
+	client := action.NewInstall(cfg)
+	client.ReleaseName = r.Name
+	client.Namespace = r.Namespace
+	client.DryRunStrategy = action.DryRunClient
+	client.Replace = true
+	b.setChartPathOptions(&client.ChartPathOptions, r)
+
+	if r.Kustomize != "" {
+		client.PostRenderer = NewKustomizePostRenderer(ctx, r.Kustomize)
+	}
+
+	chart, values, err := b.load(ctx, settings, &client.ChartPathOptions, cfg.RegistryClient, r)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := client.RunWithContext(ctx, chart, values)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to render release")
+	}
+
+	rel, err := b.release(res)
+	if err != nil {
+		return "", err
+	}
+
+	// same output as `helm template`
+	var ret strings.Builder
+	ret.WriteString(strings.TrimSpace(rel.Manifest) + "\n")
+
+	for _, hook := range rel.Hooks {
+		_, _ = fmt.Fprintf(&ret, "---\n# Source: %s\n%s\n", hook.Path, hook.Manifest)
+	}
+
+	return ret.String(), nil
+}
+
+func (b *SDKBackend) Manifest(ctx context.Context, name, namespace string) (string, error) {
+	_, cfg, err := b.configuration(namespace)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := action.NewGet(cfg).Run(name)
+	if err != nil {
+		return "", b.wrapError(err, "failed to get release")
+	}
+
+	rel, err := b.release(res)
+	if err != nil {
+		return "", err
+	}
+
+	return rel.Manifest, nil
+}
+
+func (b *SDKBackend) Status(ctx context.Context, name, namespace string) (*ReleaseStatus, error) {
+	_, cfg, err := b.configuration(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := action.NewStatus(cfg).Run(name)
+	if err != nil {
+		return nil, b.wrapError(err, "failed to get release status")
+	}
+
+	rel, err := b.release(res)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &ReleaseStatus{
+		Name:      rel.Name,
+		Namespace: rel.Namespace,
+		Revision:  rel.Version,
+	}
+
+	if rel.Info != nil {
+		ret.Status = rel.Info.Status.String()
+		ret.Description = rel.Info.Description
+
+		if !rel.Info.LastDeployed.IsZero() {
+			ret.LastDeployed = rel.Info.LastDeployed.Format(time.RFC3339)
+		}
+	}
+
+	return ret, nil
+}
+
+func (b *SDKBackend) Rollback(ctx context.Context, name, namespace, revision string) error {
+	_, cfg, err := b.configuration(namespace)
+	if err != nil {
+		return err
+	}
+
+	client := action.NewRollback(cfg)
+	client.Timeout = b.timeoutOrDefault()
+	client.WaitStrategy = b.waitStrategy()
+
+	if revision != "" {
+		if client.Version, err = strconv.Atoi(revision); err != nil {
+			return errors.Wrapf(err, "invalid revision `%s`", revision)
+		}
+	}
+
+	if err := client.Run(name); err != nil {
+		return b.wrapError(err, "failed to rollback release")
+	}
+
+	return nil
+}
+
+func (b *SDKBackend) Uninstall(ctx context.Context, name, namespace string) error {
+	_, cfg, err := b.configuration(namespace)
+	if err != nil {
+		return err
+	}
+
+	client := action.NewUninstall(cfg)
+	client.Timeout = b.timeoutOrDefault()
+	client.WaitStrategy = b.waitStrategy()
+
+	if _, err := client.Run(name); err != nil {
+		return b.wrapError(err, "failed to uninstall release")
+	}
+
+	return nil
+}
+
+func (b *SDKBackend) DependencyUpdate(ctx context.Context, chartPath string) error {
+	settings, cfg, err := b.configuration("")
+	if err != nil {
+		return err
+	}
+
+	manager := &downloader.Manager{
+		Out:              b.writer(),
+		ChartPath:        chartPath,
+		SkipUpdate:       true,
+		Getters:          getter.All(settings),
+		RegistryClient:   cfg.RegistryClient,
+		RepositoryConfig: settings.RepositoryConfig,
+		RepositoryCache:  settings.RepositoryCache,
+		ContentCache:     settings.ContentCache,
+		Debug:            settings.Debug,
+	}
+
+	return errors.Wrap(manager.Update(), "failed to update dependencies")
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private methods
+// ------------------------------------------------------------------------------------------------
+
+// upgrade installs the release if it does not exist yet or upgrades it otherwise
+func (b *SDKBackend) upgrade(ctx context.Context, r Release, dryRun action.DryRunStrategy) (*releasev1.Release, error) {
+	settings, cfg, err := b.configuration(r.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	history := action.NewHistory(cfg)
+	history.Max = 1
+
+	versions, err := history.Run(r.Name)
+	if err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
+		return nil, errors.Wrap(err, "failed to get release history")
+	}
+
+	if install, replace := b.installable(versions, err); install {
+		client := action.NewInstall(cfg)
+		client.ReleaseName = r.Name
+		client.Namespace = r.Namespace
+		client.Description = r.Description
+		client.DryRunStrategy = dryRun
+		client.Replace = replace
+		client.Timeout = b.timeoutOrDefault()
+		client.WaitStrategy = b.waitStrategy()
+		client.HideNotes = true
+		b.setChartPathOptions(&client.ChartPathOptions, r)
+
+		if r.Kustomize != "" {
+			client.PostRenderer = NewKustomizePostRenderer(ctx, r.Kustomize)
+		}
+
+		chart, values, err := b.load(ctx, settings, &client.ChartPathOptions, cfg.RegistryClient, r)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := client.RunWithContext(ctx, chart, values)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to install release")
+		}
+
+		return b.release(res)
+	}
+
+	client := action.NewUpgrade(cfg)
+	client.Namespace = r.Namespace
+	client.Description = r.Description
+	client.DryRunStrategy = dryRun
+	client.Timeout = b.timeoutOrDefault()
+	client.WaitStrategy = b.waitStrategy()
+	client.HideNotes = true
+	b.setChartPathOptions(&client.ChartPathOptions, r)
+
+	if r.Kustomize != "" {
+		client.PostRenderer = NewKustomizePostRenderer(ctx, r.Kustomize)
+	}
+
+	chart, values, err := b.load(ctx, settings, &client.ChartPathOptions, cfg.RegistryClient, r)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := client.RunWithContext(ctx, r.Name, chart, values)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to upgrade release")
+	}
+
+	return b.release(res)
+}
+
+// timeoutOrDefault returns the timeout of the operations
+func (b *SDKBackend) timeoutOrDefault() time.Duration {
+	if b.timeout > 0 {
+		return b.timeout
+	}
+
+	return defaultTimeout
+}
+
+// waitStrategy waits for hooks only unless requested to wait for all resources
+func (b *SDKBackend) waitStrategy() kube.WaitStrategy {
+	if b.wait {
+		return kube.StatusWatcherStrategy
+	}
+
+	return kube.HookOnlyStrategy
+}
+
+// installable returns whether the release needs to be installed and if an uninstalled release should be replaced
+func (b *SDKBackend) installable(versions []releasex.Releaser, err error) (bool, bool) {
+	if errors.Is(err, driver.ErrReleaseNotFound) || len(versions) == 0 {
+		return true, false
+	}
+
+	for _, version := range versions {
+		if rel, err := b.release(version); err != nil || rel.Info == nil || rel.Info.Status != releasecommon.StatusUninstalled {
+			return false, false
+		}
+	}
+
+	return true, true
+}
+
+// load locates and loads the chart including its dependencies and returns it along with the release values
+func (b *SDKBackend) load(ctx context.Context, settings *cli.EnvSettings, options *action.ChartPathOptions, registryClient *registry.Client, r Release) (chartx.Charter, map[string]any, error) {
+	chartPath, err := options.LocateChart(r.Chart, settings)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to locate chart `%s`", r.Chart)
+	}
+
+	chart, err := loader.Load(chartPath)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to load chart `%s`", chartPath)
+	}
+
+	accessor, err := chartx.NewAccessor(chart)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if dependencies := accessor.MetaDependencies(); len(dependencies) > 0 {
+		if err := action.CheckDependencies(chart, dependencies); err != nil {
+			if r.Offline {
+				return nil, nil, errors.Wrapf(err, "missing dependencies of chart `%s` in offline mode", chartPath)
+			}
+
+			manager := &downloader.Manager{
+				Out:              b.writer(),
+				ChartPath:        chartPath,
+				Getters:          getter.All(settings),
+				RegistryClient:   registryClient,
+				RepositoryConfig: settings.RepositoryConfig,
+				RepositoryCache:  settings.RepositoryCache,
+				ContentCache:     settings.ContentCache,
+				Debug:            settings.Debug,
+			}
+			if err := manager.Update(); err != nil {
+				return nil, nil, errors.Wrapf(err, "failed to update dependencies of chart `%s`", chartPath)
+			}
+
+			if chart, err = loader.Load(chartPath); err != nil {
+				return nil, nil, errors.Wrapf(err, "failed to reload chart `%s`", chartPath)
+			}
+		}
+	}
+
+	values, err := common.ReadValues(r.Values)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to read values")
+	}
+
+	for _, value := range r.Set {
+		if err := strvals.ParseInto(value, values); err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to set value `%s`", value)
+		}
+	}
+
+	return chart, values, ctx.Err()
+}
+
+// configuration returns the helm settings and action configuration for the given namespace
+func (b *SDKBackend) configuration(namespace string) (*cli.EnvSettings, *action.Configuration, error) {
+	settings := cli.New()
+	if namespace != "" {
+		settings.SetNamespace(namespace)
+	}
+
+	handler := slog.DiscardHandler
+	if pterm.PrintDebugMessages {
+		handler = slog.Default().Handler()
+	}
+
+	registryClient, err := b.registryClient(settings)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cfg := action.NewConfiguration(action.ConfigurationSetLogger(handler))
+	cfg.RegistryClient = registryClient
+
+	if err := cfg.Init(settings.RESTClientGetter(), settings.Namespace(), os.Getenv("HELM_DRIVER")); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to initialize helm configuration")
+	}
+
+	return settings, cfg, nil
+}
+
+func (b *SDKBackend) registryClient(settings *cli.EnvSettings) (*registry.Client, error) {
+	ret, err := registry.NewClient(
+		registry.ClientOptDebug(settings.Debug),
+		registry.ClientOptEnableCache(true),
+		registry.ClientOptWriter(b.writer()),
+		registry.ClientOptCredentialsFile(settings.RegistryConfig),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create registry client")
+	}
+
+	return ret, nil
+}
+
+func (b *SDKBackend) setChartPathOptions(options *action.ChartPathOptions, r Release) {
+	options.RepoURL = r.Repository
+	options.Version = r.Version
+}
+
+// release converts the releaser into a v1 release
+func (b *SDKBackend) release(v releasex.Releaser) (*releasev1.Release, error) {
+	switch rel := v.(type) {
+	case *releasev1.Release:
+		return rel, nil
+	case releasev1.Release:
+		return &rel, nil
+	default:
+		return nil, errors.Errorf("unsupported release type: %T", v)
+	}
+}
+
+func (b *SDKBackend) writer() io.Writer {
+	if pterm.PrintDebugMessages {
+		return os.Stderr
+	}
+
+	return io.Discard
+}
+
+// wrapError maps the helm storage not found error to ErrReleaseNotFound
+func (b *SDKBackend) wrapError(err error, msg string) error {
+	if errors.Is(err, driver.ErrReleaseNotFound) {
+		return ErrReleaseNotFound
+	}
+
+	return errors.Wrap(err, msg)
+}
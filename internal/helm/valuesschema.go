@@ -0,0 +1,103 @@
+package helm
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"helm.sh/helm/v4/pkg/action"
+	chartx "helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chart/common/util"
+	chartv2 "helm.sh/helm/v4/pkg/chart/v2"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/cli"
+)
+
+// ValuesSchema is the values schema of a chart along with the values it applies to
+type ValuesSchema struct {
+	// Chart name
+	Chart string
+	// JSON pointer to the chart values within the release values
+	Path string
+	// Values schema json
+	Schema []byte
+	// Values coalesced with the chart defaults
+	Values map[string]any
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public methods
+// ------------------------------------------------------------------------------------------------
+
+// ValuesSchemas loads the chart of the release and returns the values schemas of the chart and its
+// enabled subcharts with the values helm would validate against them
+func (b *SDKBackend) ValuesSchemas(ctx context.Context, r Release) ([]ValuesSchema, error) {
+	settings := cli.New()
+
+	registryClient, err := b.registryClient(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := action.NewConfiguration()
+	cfg.RegistryClient = registryClient
+
+	client := action.NewInstall(cfg)
+	b.setChartPathOptions(&client.ChartPathOptions, r)
+
+	chart, values, err := b.load(ctx, settings, &client.ChartPathOptions, registryClient, r)
+	if err != nil {
+		return nil, err
+	}
+
+	if v2, ok := chart.(*chartv2.Chart); ok {
+		if err := chartutil.ProcessDependencies(v2, values); err != nil {
+			return nil, errors.Wrap(err, "failed to process chart dependencies")
+		}
+	}
+
+	coalesced, err := util.CoalesceValues(chart, values)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to coalesce values")
+	}
+
+	return b.valuesSchemas(chart, "", coalesced)
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private methods
+// ------------------------------------------------------------------------------------------------
+
+func (b *SDKBackend) valuesSchemas(chart chartx.Charter, path string, values map[string]any) ([]ValuesSchema, error) {
+	accessor, err := chartx.NewAccessor(chart)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := []ValuesSchema{{
+		Chart:  accessor.Name(),
+		Path:   path,
+		Schema: accessor.Schema(),
+		Values: values,
+	}}
+
+	for _, dependency := range accessor.Dependencies() {
+		sub, err := chartx.NewAccessor(dependency)
+		if err != nil {
+			return nil, err
+		}
+
+		subValues, ok := values[sub.Name()].(map[string]any)
+		if !ok {
+			continue
+		}
+
+		items, err := b.valuesSchemas(dependency, path+"/"+sub.Name(), subValues)
+		if err != nil {
+			return nil, err
+		}
+
+		ret = append(ret, items...)
+	}
+
+	return ret, nil
+}
@@ -11,36 +11,33 @@ import (
 	"github.com/pterm/pterm"
 )
 
+// Load fetches the raw JSON schema from a given URL or path
+func Load(ctx context.Context, url string) ([]byte, error) {
+	if !strings.HasPrefix(url, "http") {
+		return os.ReadFile(url)
+	}
+
+	pterm.Debug.Printfln("Loading map from %s", url)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return io.ReadAll(resp.Body)
+}
+
 // LoadMap fetches the JSON schema from a given URL
 func LoadMap(ctx context.Context, url string) (map[string]any, error) {
-	var (
-		err  error
-		body []byte
-	)
-
-	if strings.HasPrefix(url, "http") {
-		pterm.Debug.Printfln("Loading map from %s", url)
-
-		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-		if err != nil {
-			return nil, err
-		}
-
-		resp, err := http.DefaultClient.Do(req)
-		if err != nil {
-			return nil, err
-		}
-		defer resp.Body.Close()
-
-		body, err = io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		body, err = os.ReadFile(url)
-		if err != nil {
-			return nil, err
-		}
+	body, err := Load(ctx, url)
+	if err != nil {
+		return nil, err
 	}
 
 	var schema map[string]any
@@ -0,0 +1,101 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// Violation describes a value not matching its JSON schema
+type Violation struct {
+	// JSON pointer to the invalid value
+	Path string `json:"path" yaml:"path"`
+	// Violation message
+	Message string `json:"message" yaml:"message"`
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public functions
+// ------------------------------------------------------------------------------------------------
+
+// Validate validates the value against the given JSON schema and returns all violations sorted by path
+func Validate(url string, schema []byte, value any) ([]Violation, error) {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(schema))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal schema `%s`", url)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(url, doc); err != nil {
+		return nil, errors.Wrapf(err, "failed to add schema `%s`", url)
+	}
+
+	sch, err := compiler.Compile(url)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to compile schema `%s`", url)
+	}
+
+	// normalize the value into json types
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal value")
+	}
+
+	instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal value")
+	}
+
+	var validationErr *jsonschema.ValidationError
+	if err := sch.Validate(instance); err == nil {
+		return nil, nil
+	} else if !errors.As(err, &validationErr) {
+		return nil, errors.Wrap(err, "failed to validate value")
+	}
+
+	ret := violations(validationErr, message.NewPrinter(language.English))
+
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].Path < ret[j].Path
+	})
+
+	return ret, nil
+}
+
+// Pointer returns the JSON pointer for the given tokens
+func Pointer(tokens ...string) string {
+	var ret strings.Builder
+	for _, token := range tokens {
+		ret.WriteString("/")
+		ret.WriteString(strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1"))
+	}
+
+	return ret.String()
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private functions
+// ------------------------------------------------------------------------------------------------
+
+// violations flattens the validation error into its leaf causes
+func violations(err *jsonschema.ValidationError, p *message.Printer) []Violation {
+	if len(err.Causes) == 0 {
+		return []Violation{{
+			Path:    Pointer(err.InstanceLocation...),
+			Message: err.ErrorKind.LocalizedString(p),
+		}}
+	}
+
+	var ret []Violation
+	for _, cause := range err.Causes {
+		ret = append(ret, violations(cause, p)...)
+	}
+
+	return ret
+}
@@ -0,0 +1,64 @@
+package jsonschema_test
+
+import (
+	"testing"
+
+	testingx "github.com/foomo/go/testing"
+	tagx "github.com/foomo/go/testing/tag"
+	"github.com/foomo/squadron/internal/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const valuesSchema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "object",
+  "additionalProperties": false,
+  "required": ["image"],
+  "properties": {
+    "replicas": {"type": "integer"},
+    "image": {
+      "type": "object",
+      "properties": {
+        "tag": {"type": "string"}
+      }
+    }
+  }
+}`
+
+func TestValidate(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	t.Run("valid", func(t *testing.T) {
+		actual, err := jsonschema.Validate("file:///values.schema.json", []byte(valuesSchema), map[string]any{
+			"replicas": 2,
+			"image":    map[string]any{"tag": "latest"},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, actual)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		actual, err := jsonschema.Validate("file:///values.schema.json", []byte(valuesSchema), map[string]any{
+			"replicaCount": 2,
+			"image":        map[string]any{"tag": 1},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []jsonschema.Violation{
+			{Path: "", Message: "additional properties 'replicaCount' not allowed"},
+			{Path: "/image/tag", Message: "got number, want string"},
+		}, actual)
+	})
+
+	t.Run("invalid schema", func(t *testing.T) {
+		_, err := jsonschema.Validate("file:///values.schema.json", []byte(`{`), nil)
+		require.Error(t, err)
+	})
+}
+
+func TestPointer(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	assert.Empty(t, jsonschema.Pointer())
+	assert.Equal(t, "/values/a~1b/c~0d", jsonschema.Pointer("values", "a/b", "c~d"))
+}
@@ -0,0 +1,199 @@
+package lockfile
+
+import (
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/foomo/squadron/internal/helm"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// Filename of the lockfile next to the squadron files
+	Filename = "squadron.lock"
+	// Version of the lockfile format
+	Version = "1"
+)
+
+// Lockfile records the resolved versions and digests of remote charts
+type Lockfile struct {
+	// Lockfile format version
+	Version string `json:"version" yaml:"version"`
+	// Locked charts
+	Charts []Chart `json:"charts,omitempty" yaml:"charts,omitempty"`
+}
+
+type Chart struct {
+	// Chart name or oci reference
+	Name string `json:"name" yaml:"name"`
+	// Chart repository url
+	Repository string `json:"repository,omitempty" yaml:"repository,omitempty"`
+	// Requested chart version or constraint
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	// Resolved chart version
+	Resolved string `json:"resolved" yaml:"resolved"`
+	// Chart archive digest (format: sha256:<hex>)
+	Digest string `json:"digest" yaml:"digest"`
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Constructor
+// ------------------------------------------------------------------------------------------------
+
+// Load reads the lockfile and returns an empty one if it does not exist
+func Load(filename string) (*Lockfile, error) {
+	ret := &Lockfile{Version: Version}
+
+	value, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return ret, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to read lockfile")
+	}
+
+	if err := yaml.Unmarshal(value, ret); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal lockfile `%s`", filename)
+	}
+
+	if ret.Version != Version {
+		return nil, errors.Errorf("unsupported lockfile version `%s` in `%s`", ret.Version, filename)
+	}
+
+	return ret, nil
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public methods
+// ------------------------------------------------------------------------------------------------
+
+// Get returns the locked chart for the given reference
+func (l *Lockfile) Get(name, repository, version string) (Chart, bool) {
+	key := Chart{Name: name, Repository: repository, Version: version}.Key()
+	for _, c := range l.Charts {
+		if c.Key() == key {
+			return c, true
+		}
+	}
+
+	return Chart{}, false
+}
+
+// Set adds or replaces the locked chart
+func (l *Lockfile) Set(c Chart) {
+	l.Charts = slices.DeleteFunc(l.Charts, func(v Chart) bool {
+		return v.Key() == c.Key()
+	})
+	l.Charts = append(l.Charts, c)
+}
+
+// Retain removes all charts whose key is not given
+func (l *Lockfile) Retain(keys ...string) {
+	l.Charts = slices.DeleteFunc(l.Charts, func(v Chart) bool {
+		return !slices.Contains(keys, v.Key())
+	})
+}
+
+// Save writes the lockfile with its charts sorted by key
+func (l *Lockfile) Save(filename string) error {
+	slices.SortFunc(l.Charts, func(a, b Chart) int {
+		return strings.Compare(a.Key(), b.Key())
+	})
+
+	value, err := yaml.Marshal(l)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal lockfile")
+	}
+
+	return os.WriteFile(filename, append([]byte("# This file is generated by `squadron lock`. DO NOT EDIT.\n"), value...), 0600)
+}
+
+// Key returns the chart reference (format: repository/name@version)
+func (c Chart) Key() string {
+	ret := c.Name
+	if c.Repository != "" {
+		ret = strings.TrimSuffix(c.Repository, "/") + "/" + ret
+	}
+
+	if c.Version != "" {
+		ret += "@" + c.Version
+	}
+
+	return ret
+}
+
+// Filename returns the archive filename in the cache
+func (c Chart) Filename() string {
+	digest := strings.TrimPrefix(c.Digest, "sha256:")
+	if len(digest) > 12 {
+		digest = digest[:12]
+	}
+
+	return path.Base(c.Name) + "-" + c.Resolved + "-" + digest + ".tgz"
+}
+
+// Cached returns the path to the cached archive if its digest matches
+func (c Chart) Cached(cacheDir string) (string, error) {
+	filename := path.Join(cacheDir, c.Filename())
+
+	digest, err := helm.Digest(filename)
+	if err != nil {
+		return "", err
+	} else if digest != c.Digest {
+		return "", errors.Errorf("digest mismatch for cached chart `%s`: expected %s but got %s", filename, c.Digest, digest)
+	}
+
+	return filename, nil
+}
+
+// Store copies the archive into the cache after verifying its digest
+func (c Chart) Store(cacheDir string, archive helm.ChartArchive) (string, error) {
+	if archive.Digest != c.Digest {
+		return "", errors.Errorf("digest mismatch for chart `%s`: expected %s but got %s", c.Key(), c.Digest, archive.Digest)
+	}
+
+	if err := os.MkdirAll(cacheDir, 0700); err != nil {
+		return "", errors.Wrap(err, "failed to create chart cache")
+	}
+
+	filename := path.Join(cacheDir, c.Filename())
+
+	src, err := os.Open(archive.Path)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(filename)
+	if err != nil {
+		return "", err
+	}
+	defer dst.Close()
+
+	if _, err := io.Copy(dst, src); err != nil {
+		return "", errors.Wrapf(err, "failed to copy chart `%s` into cache", c.Key())
+	}
+
+	return filename, nil
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public functions
+// ------------------------------------------------------------------------------------------------
+
+// CacheDir returns the chart cache directory from `SQUADRON_CACHE_DIR` or the user cache dir
+func CacheDir() (string, error) {
+	if value := os.Getenv("SQUADRON_CACHE_DIR"); value != "" {
+		return path.Join(value, "charts"), nil
+	}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to determine cache dir")
+	}
+
+	return path.Join(dir, "squadron", "charts"), nil
+}
@@ -0,0 +1,96 @@
+package lockfile_test
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	testingx "github.com/foomo/go/testing"
+	tagx "github.com/foomo/go/testing/tag"
+	"github.com/foomo/squadron/internal/helm"
+	"github.com/foomo/squadron/internal/lockfile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockfile(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	filename := path.Join(t.TempDir(), lockfile.Filename)
+
+	lock, err := lockfile.Load(filename)
+	require.NoError(t, err)
+	assert.Equal(t, lockfile.Version, lock.Version)
+	assert.Empty(t, lock.Charts)
+
+	frontend := lockfile.Chart{
+		Name:       "frontend",
+		Repository: "https://charts.example.com/",
+		Version:    "^1.0.0",
+		Resolved:   "1.2.3",
+		Digest:     "sha256:0123456789abcdef",
+	}
+	backend := lockfile.Chart{
+		Name:     "oci://registry.example.com/charts/backend",
+		Version:  "2.0.0",
+		Resolved: "2.0.0",
+		Digest:   "sha256:fedcba9876543210",
+	}
+
+	lock.Set(frontend)
+	lock.Set(backend)
+	lock.Set(frontend)
+	require.NoError(t, lock.Save(filename))
+
+	lock, err = lockfile.Load(filename)
+	require.NoError(t, err)
+	require.Len(t, lock.Charts, 2)
+	assert.Equal(t, "https://charts.example.com/frontend@^1.0.0", frontend.Key())
+
+	chart, ok := lock.Get("frontend", "https://charts.example.com/", "^1.0.0")
+	require.True(t, ok)
+	assert.Equal(t, frontend, chart)
+
+	_, ok = lock.Get("frontend", "https://charts.example.com/", "^2.0.0")
+	assert.False(t, ok)
+
+	lock.Retain(backend.Key())
+	require.Len(t, lock.Charts, 1)
+	assert.Equal(t, backend, lock.Charts[0])
+
+	require.NoError(t, os.WriteFile(filename, []byte("version: '0'\n"), 0600))
+	_, err = lockfile.Load(filename)
+	require.Error(t, err)
+}
+
+func TestChart_Store(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	cacheDir := path.Join(t.TempDir(), "charts")
+	filename := path.Join(t.TempDir(), "frontend-1.2.3.tgz")
+	require.NoError(t, os.WriteFile(filename, []byte("archive"), 0600))
+
+	digest, err := helm.Digest(filename)
+	require.NoError(t, err)
+
+	chart := lockfile.Chart{Name: "frontend", Resolved: "1.2.3", Digest: digest}
+
+	_, err = chart.Cached(cacheDir)
+	require.Error(t, err)
+
+	cached, err := chart.Store(cacheDir, helm.ChartArchive{Path: filename, Version: "1.2.3", Digest: digest})
+	require.NoError(t, err)
+	assert.Equal(t, path.Join(cacheDir, chart.Filename()), cached)
+
+	value, err := chart.Cached(cacheDir)
+	require.NoError(t, err)
+	assert.Equal(t, cached, value)
+
+	// tampered cache
+	require.NoError(t, os.WriteFile(cached, []byte("tampered"), 0600))
+	_, err = chart.Cached(cacheDir)
+	require.ErrorContains(t, err, "digest mismatch")
+
+	_, err = chart.Store(cacheDir, helm.ChartArchive{Path: filename, Version: "1.2.3", Digest: "sha256:other"})
+	require.ErrorContains(t, err, "digest mismatch")
+}
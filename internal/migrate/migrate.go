@@ -0,0 +1,255 @@
+package migrate
+
+import (
+	"bytes"
+	"cmp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Migrator upgrades a squadron document from one schema version to the next
+type Migrator struct {
+	// Schema version to migrate from
+	From string
+	// Schema version to migrate to
+	To string
+	// Short description of the changes
+	Description string
+	// Migrate rewrites the document root mapping node in place
+	Migrate func(root *yaml.Node) error
+}
+
+// Registry holds the migrators by their source version
+type Registry struct {
+	migrators map[string]Migrator
+}
+
+// DefaultRegistry contains the migrators of the supported schema versions, i.e. from `2.2` on. Older
+// versions have no migrators and must be upgraded by hand.
+var DefaultRegistry = NewRegistry()
+
+func init() {
+	DefaultRegistry.Register(v22)
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Constructor
+// ------------------------------------------------------------------------------------------------
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		migrators: map[string]Migrator{},
+	}
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public methods
+// ------------------------------------------------------------------------------------------------
+
+// Register adds the migrator, replacing any migrator with the same source version
+func (r *Registry) Register(m Migrator) {
+	r.migrators[m.From] = m
+}
+
+// Chain returns the migrators to apply in order to get from one version to the other
+func (r *Registry) Chain(from, to string) ([]Migrator, error) {
+	var ret []Migrator
+
+	if oldest := r.Oldest(); oldest != "" && compareVersions(from, oldest) < 0 {
+		return nil, errors.Errorf("version `%s` is too old to be migrated, please upgrade it to version `%s` by hand first", from, oldest)
+	} else if compareVersions(from, to) > 0 {
+		return nil, errors.Errorf("version `%s` is newer than `%s`, please upgrade squadron", from, to)
+	}
+
+	for version := from; version != to; {
+		m, ok := r.migrators[version]
+		if !ok || len(ret) > len(r.migrators) {
+			return nil, errors.Errorf("no migration from version `%s` to `%s`", from, to)
+		}
+
+		ret = append(ret, m)
+		version = m.To
+	}
+
+	return ret, nil
+}
+
+// Migrate upgrades the yaml document to the given version, preserving comments and key order,
+// and returns the rewritten document along with the applied migrators
+func (r *Registry) Migrate(data []byte, to string) ([]byte, []Migrator, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to unmarshal yaml")
+	}
+
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return data, nil, nil
+	}
+
+	root := doc.Content[0]
+
+	version := Get(root, "version")
+	if version == nil || version.Value == to {
+		return data, nil, nil
+	}
+
+	migrators, err := r.Chain(version.Value, to)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, m := range migrators {
+		if err := m.Migrate(root); err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to migrate from version `%s` to `%s`", m.From, m.To)
+		}
+
+		version.Value = m.To
+	}
+
+	var out bytes.Buffer
+
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(&doc); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to marshal yaml")
+	}
+
+	if err := enc.Close(); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to marshal yaml")
+	}
+
+	return out.Bytes(), migrators, nil
+}
+
+// Oldest returns the oldest version that can be migrated
+func (r *Registry) Oldest() string {
+	var ret string
+
+	for version := range r.migrators {
+		if ret == "" || compareVersions(version, ret) < 0 {
+			ret = version
+		}
+	}
+
+	return ret
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public functions
+// ------------------------------------------------------------------------------------------------
+
+// Get returns the value node of the key in the mapping node
+func Get(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+// Rename renames the key in the mapping node, keeping its position
+func Rename(node *yaml.Node, from, to string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == from {
+			node.Content[i].Value = to
+		}
+	}
+}
+
+// Delete removes the key from the mapping node
+func Delete(node *yaml.Node, key string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// Sequence converts a scalar node into a sequence containing the scalar
+func Sequence(node *yaml.Node) {
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return
+	}
+
+	item := *node
+	item.LineComment = ""
+	item.HeadComment = ""
+	item.FootComment = ""
+
+	*node = yaml.Node{
+		Kind:        yaml.SequenceNode,
+		Tag:         "!!seq",
+		Line:        item.Line,
+		Column:      item.Column,
+		HeadComment: node.HeadComment,
+		LineComment: node.LineComment,
+		FootComment: node.FootComment,
+		Content:     []*yaml.Node{&item},
+	}
+}
+
+// Units calls the handler for every unit mapping node
+func Units(root *yaml.Node, handler func(unit *yaml.Node) error) error {
+	squadrons := Get(root, "squadron")
+	if squadrons == nil || squadrons.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 1; i < len(squadrons.Content); i += 2 {
+		units := squadrons.Content[i]
+		if units.Kind != yaml.MappingNode {
+			continue
+		}
+
+		for j := 1; j < len(units.Content); j += 2 {
+			if units.Content[j].Kind != yaml.MappingNode {
+				continue
+			}
+
+			if err := handler(units.Content[j]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private functions
+// ------------------------------------------------------------------------------------------------
+
+// compareVersions compares dot separated numeric versions like `2.3`, treating missing or invalid
+// parts as 0
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+
+	for i := range max(len(as), len(bs)) {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+
+		if x != y {
+			return cmp.Compare(x, y)
+		}
+	}
+
+	return 0
+}
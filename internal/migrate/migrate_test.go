@@ -0,0 +1,105 @@
+package migrate_test
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	testingx "github.com/foomo/go/testing"
+	tagx "github.com/foomo/go/testing/tag"
+	"github.com/foomo/squadron/config"
+	"github.com/foomo/squadron/internal/migrate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestRegistry_Migrate(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+	t.Setenv("PROJECT_ROOT", "../..")
+
+	// one directory per supported schema version
+	entries, err := os.ReadDir("testdata")
+	require.NoError(t, err)
+	require.NotEmpty(t, entries)
+
+	for _, entry := range entries {
+		t.Run(entry.Name(), func(t *testing.T) {
+			input, err := os.ReadFile(path.Join("testdata", entry.Name(), "squadron.yaml"))
+			require.NoError(t, err)
+
+			expected, err := os.ReadFile(path.Join("testdata", entry.Name(), "squadron.migrated.yaml"))
+			require.NoError(t, err)
+
+			actual, migrators, err := migrate.DefaultRegistry.Migrate(input, config.Version)
+			require.NoError(t, err)
+			require.NotEmpty(t, migrators)
+			assert.Equal(t, entry.Name(), migrators[0].From)
+			assert.Equal(t, config.Version, migrators[len(migrators)-1].To)
+			assert.Equal(t, string(expected), string(actual))
+
+			// the migrated document must be loadable and stable
+			var c config.Config
+			require.NoError(t, yaml.Unmarshal(actual, &c))
+
+			again, migrators, err := migrate.DefaultRegistry.Migrate(actual, config.Version)
+			require.NoError(t, err)
+			assert.Empty(t, migrators)
+			assert.Equal(t, string(actual), string(again))
+		})
+	}
+}
+
+func TestRegistry_Chain(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	var calls []string
+
+	r := migrate.NewRegistry()
+	for _, m := range [][2]string{{"1.0", "2.0"}, {"2.0", "2.1"}} {
+		r.Register(migrate.Migrator{
+			From: m[0],
+			To:   m[1],
+			Migrate: func(root *yaml.Node) error {
+				calls = append(calls, m[0])
+				return nil
+			},
+		})
+	}
+
+	out, migrators, err := r.Migrate([]byte("version: '1.0' # legacy\n"), "2.1")
+	require.NoError(t, err)
+	assert.Len(t, migrators, 2)
+	assert.Equal(t, []string{"1.0", "2.0"}, calls)
+	assert.Equal(t, "version: '2.1' # legacy\n", string(out))
+
+	_, _, err = r.Migrate([]byte("version: '0.9'\n"), "2.1")
+	require.EqualError(t, err, "version `0.9` is too old to be migrated, please upgrade it to version `1.0` by hand first")
+
+	_, _, err = r.Migrate([]byte("version: '1.5'\n"), "2.1")
+	require.EqualError(t, err, "no migration from version `1.5` to `2.1`")
+
+	out, migrators, err = r.Migrate([]byte("vars: {}\n"), "2.1")
+	require.NoError(t, err)
+	assert.Empty(t, migrators)
+	assert.Equal(t, "vars: {}\n", string(out))
+}
+
+func TestDefaultRegistry_unsupported(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	assert.Equal(t, "2.2", migrate.DefaultRegistry.Oldest())
+
+	tests := map[string]string{
+		"1.0": "version `1.0` is too old to be migrated, please upgrade it to version `2.2` by hand first",
+		"2.0": "version `2.0` is too old to be migrated, please upgrade it to version `2.2` by hand first",
+		"2.1": "version `2.1` is too old to be migrated, please upgrade it to version `2.2` by hand first",
+		"9.0": "version `9.0` is newer than `" + config.Version + "`, please upgrade squadron",
+	}
+	for version, want := range tests {
+		t.Run(version, func(t *testing.T) {
+			_, _, err := migrate.DefaultRegistry.Migrate([]byte("version: '"+version+"'\n"), config.Version)
+			require.EqualError(t, err, want)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package migrate
+
+import (
+	"gopkg.in/yaml.v3"
+)
+
+// v22 migrates the snake case build flags to their camel case names and turns the single value
+// flags that accept multiple values into lists, merging `image` into `tag`
+var v22 = Migrator{
+	From:        "2.2",
+	To:          "2.3",
+	Description: "camel case build flags, list values and image tags",
+	Migrate: func(root *yaml.Node) error {
+		builds := []*yaml.Node{Get(root, "builds")}
+
+		_ = Units(root, func(unit *yaml.Node) error {
+			builds = append(builds, Get(unit, "builds"))
+			return nil
+		})
+
+		for _, node := range builds {
+			if node == nil || node.Kind != yaml.MappingNode {
+				continue
+			}
+
+			for i := 1; i < len(node.Content); i += 2 {
+				if node.Content[i].Kind == yaml.MappingNode {
+					v22Build(node.Content[i])
+				}
+			}
+		}
+
+		return nil
+	},
+}
+
+var v22BuildKeys = map[string]string{
+	"add_host":        "addHost",
+	"build_arg":       "buildArg",
+	"build_context":   "buildContext",
+	"cache_from":      "cacheFrom",
+	"cache_to":        "cacheTo",
+	"cgroup_parent":   "cGroupParent",
+	"metadata_file":   "metadataFile",
+	"no_cache":        "noCache",
+	"no_cache_filter": "noCacheFilter",
+	"shm_size":        "shmSize",
+}
+
+var v22BuildLists = []string{"cacheFrom", "cacheTo", "output", "platform", "ssh", "tag"}
+
+func v22Build(build *yaml.Node) {
+	for from, to := range v22BuildKeys {
+		Rename(build, from, to)
+	}
+
+	if image := Get(build, "image"); image != nil && image.Kind == yaml.ScalarNode {
+		if tag := Get(build, "tag"); tag == nil {
+			build.Content = append(build.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "tag"},
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: image.Value, Style: image.Style},
+			)
+		} else if tag.Kind == yaml.ScalarNode {
+			tag.Value = image.Value + ":" + tag.Value
+			tag.Tag = "!!str"
+		}
+
+		Delete(build, "image")
+	}
+
+	for _, key := range v22BuildLists {
+		Sequence(Get(build, key))
+	}
+}
@@ -0,0 +1,526 @@
+package provenance
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Source is the file and line a value was defined at
+type Source struct {
+	// File name
+	File string `json:"file" yaml:"file"`
+	// Line number
+	Line int `json:"line" yaml:"line"`
+}
+
+// Value is the provenance of a merged leaf value
+type Value struct {
+	// Dot separated path of the value (format: a.b[0].c), dots and brackets in keys are escaped
+	// with a backslash (e.g. labels.app\.kubernetes\.io/name)
+	Path string `json:"path" yaml:"path"`
+	// Source of the merged value
+	Source Source `json:"source" yaml:"source"`
+	// Sources of the overridden values, oldest first
+	Overrides []Source `json:"overrides,omitempty" yaml:"overrides,omitempty"`
+	// scalar tag and value
+	tag   string
+	value string
+}
+
+// Merge defines how inherited values are merged into existing ones
+type Merge int
+
+const (
+	// MergeDeep merges maps and keeps existing sequences
+	MergeDeep Merge = iota
+	// MergeAppend merges maps and appends existing sequence items to the inherited ones
+	MergeAppend
+	// MergeAppendUnique appends the existing sequence items that are not inherited
+	MergeAppendUnique
+	// MergeReplace keeps any existing value as a whole
+	MergeReplace
+)
+
+// Provenance tracks the sources of the leaf values of merged yaml files
+type Provenance struct {
+	values map[string]*Value
+	// lengths of the merged sequences by path
+	lengths map[string]int
+}
+
+// escaper escapes the separators of keys in paths
+var escaper = strings.NewReplacer(`\`, `\\`, ".", `\.`, "[", `\[`)
+
+// ------------------------------------------------------------------------------------------------
+// ~ Constructor
+// ------------------------------------------------------------------------------------------------
+
+// New returns an empty provenance
+func New() *Provenance {
+	return &Provenance{
+		values:  map[string]*Value{},
+		lengths: map[string]int{},
+	}
+}
+
+// Load reads the files in merge order, following the conflate merge semantics: maps are merged,
+// sequences appended and scalars replaced
+func Load(files ...string) (*Provenance, error) {
+	ret := New()
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read `%s`", file)
+		}
+
+		if err := ret.Add(file, data); err != nil {
+			return nil, err
+		}
+	}
+
+	return ret, nil
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public methods
+// ------------------------------------------------------------------------------------------------
+
+func (s Source) String() string {
+	return fmt.Sprintf("%s:%d", s.File, s.Line)
+}
+
+// Add merges the yaml document of the given file
+func (p *Provenance) Add(file string, data []byte) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal `%s`", file)
+	}
+
+	if len(doc.Content) == 0 {
+		return nil
+	}
+
+	p.add(file, "", doc.Content[0])
+
+	return nil
+}
+
+// Overlay merges the values below the source path onto the target path, e.g. environment vars
+func (p *Provenance) Overlay(source, target string) {
+	for _, v := range p.Values(source) {
+		if v.Path == source {
+			continue
+		}
+
+		path := target + strings.TrimPrefix(v.Path, source)
+		if existing, ok := p.values[path]; ok {
+			p.values[path] = &Value{
+				Path:      path,
+				Source:    v.Source,
+				Overrides: append(slices.Clone(existing.Overrides), existing.Source),
+				tag:       v.tag,
+				value:     v.value,
+			}
+		} else {
+			p.values[path] = &Value{Path: path, Source: v.Source, tag: v.tag, value: v.value}
+		}
+	}
+}
+
+// Inherit merges the values below the source path into the target path with the existing target
+// values taking precedence, e.g. unit templates. The merge function returns how the values at the
+// given path relative to the target are merged.
+func (p *Provenance) Inherit(source, target string, merge func(path string) Merge) {
+	relative := func(path, root string) string {
+		return strings.TrimPrefix(strings.TrimPrefix(path, root), ".")
+	}
+
+	// replaced returns true if the relative path is below an existing value that is kept as a whole
+	replaced := func(rel string) bool {
+		for path := rel; path != ""; path = Parent(path) {
+			if merge(path) == MergeReplace && p.exists(Join(target, path)) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	sequences := make([]string, 0, len(p.lengths))
+	for path := range p.lengths {
+		if Contains(source, path) {
+			sequences = append(sequences, path)
+		}
+	}
+
+	slices.SortFunc(sequences, func(a, b string) int {
+		return len(a) - len(b)
+	})
+
+	// sequences of the source that are either copied or skipped as a whole
+	var copied, kept []string
+
+	for _, path := range sequences {
+		rel := relative(path, source)
+		n := p.lengths[path]
+		to := target + strings.TrimPrefix(path, source)
+
+		switch {
+		case slices.ContainsFunc(kept, func(s string) bool { return Contains(s, path) }):
+			continue
+		case slices.ContainsFunc(copied, func(s string) bool { return Contains(s, path) }):
+			p.lengths[to] = n
+			continue
+		case replaced(rel):
+			kept = append(kept, path)
+			continue
+		}
+
+		m, ok := p.lengths[to]
+		if !ok {
+			p.lengths[to] = n
+			copied = append(copied, path)
+
+			continue
+		}
+
+		switch merge(rel) {
+		case MergeAppend:
+			p.renumber(to, func(i int) int { return n + i })
+			p.lengths[to] = n + m
+			copied = append(copied, path)
+		case MergeAppendUnique:
+			inherited := map[string]bool{}
+			for i := range n {
+				if value, ok := p.values[Index(path, i)]; ok {
+					inherited[value.tag+":"+value.value] = true
+				}
+			}
+
+			next := n
+			p.renumber(to, func(i int) int {
+				if value, ok := p.values[Index(to, i)]; ok && inherited[value.tag+":"+value.value] {
+					return -1
+				}
+
+				next++
+
+				return next - 1
+			})
+			p.lengths[to] = next
+			copied = append(copied, path)
+		default:
+			kept = append(kept, path)
+		}
+	}
+
+	for _, v := range p.Values(source) {
+		if v.Path == source || replaced(relative(v.Path, source)) ||
+			slices.ContainsFunc(kept, func(s string) bool { return Contains(s, v.Path) }) {
+			continue
+		}
+
+		path := target + strings.TrimPrefix(v.Path, source)
+		if existing, ok := p.values[path]; ok {
+			existing.Overrides = append(append(slices.Clone(v.Overrides), v.Source), existing.Overrides...)
+			continue
+		}
+
+		// scalars don't merge with maps or sequences
+		if p.exists(path) || p.scalarAbove(target, path) {
+			continue
+		}
+
+		p.values[path] = &Value{Path: path, Source: v.Source, Overrides: slices.Clone(v.Overrides), tag: v.tag, value: v.value}
+	}
+}
+
+// Scalar returns the merged scalar value at the given path
+func (p *Provenance) Scalar(path string) (string, bool) {
+	if value, ok := p.values[path]; ok && value.tag != "!!null" {
+		return value.value, true
+	}
+
+	return "", false
+}
+
+// Get returns the provenance of the value at the given path or of its closest parent
+func (p *Provenance) Get(path string) (*Value, bool) {
+	for path != "" {
+		if value, ok := p.values[path]; ok {
+			return value, true
+		}
+
+		path = Parent(path)
+	}
+
+	return nil, false
+}
+
+// Values returns the provenance of all values at or below the given path sorted by path
+func (p *Provenance) Values(path string) []*Value {
+	var ret []*Value
+
+	for key, value := range p.values {
+		if path == "" || key == path || Contains(path, key) {
+			ret = append(ret, value)
+		}
+	}
+
+	slices.SortFunc(ret, func(a, b *Value) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	return ret
+}
+
+// Explain adds the source of every leaf value as line comment to the given yaml and returns the
+// annotated yaml below the given path along with the values overriding others
+func (p *Provenance) Explain(data []byte, path string) ([]byte, []*Value, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to unmarshal yaml")
+	}
+
+	if len(doc.Content) == 0 {
+		return data, nil, nil
+	}
+
+	overrides := map[string]*Value{}
+
+	node := p.annotate("", doc.Content[0], path, overrides)
+	if node == nil {
+		return nil, nil, errors.Errorf("unknown path `%s`", path)
+	}
+
+	var out bytes.Buffer
+
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(node); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to marshal yaml")
+	}
+
+	if err := enc.Close(); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to marshal yaml")
+	}
+
+	ret := make([]*Value, 0, len(overrides))
+	for _, value := range overrides {
+		ret = append(ret, value)
+	}
+
+	slices.SortFunc(ret, func(a, b *Value) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	return out.Bytes(), ret, nil
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public functions
+// ------------------------------------------------------------------------------------------------
+
+// Join returns the path of the escaped key below the given path
+func Join(path, key string) string {
+	key = escaper.Replace(key)
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+// Index returns the path of the sequence item below the given path
+func Index(path string, i int) string {
+	return fmt.Sprintf("%s[%d]", path, i)
+}
+
+// Parent returns the parent path of the given path
+func Parent(path string) string {
+	var ret string
+
+	for i := 0; i < len(path); i++ {
+		switch path[i] {
+		case '\\':
+			i++
+		case '.', '[':
+			ret = path[:i]
+		}
+	}
+
+	return ret
+}
+
+// Contains returns true if child is below the given path
+func Contains(path, child string) bool {
+	return strings.HasPrefix(child, path+".") || strings.HasPrefix(child, path+"[")
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private methods
+// ------------------------------------------------------------------------------------------------
+
+// exists returns true if there is a value at or below the given path
+func (p *Provenance) exists(path string) bool {
+	if _, ok := p.values[path]; ok {
+		return true
+	}
+
+	for key := range p.values {
+		if Contains(path, key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// scalarAbove returns true if there is a value between the given root and path
+func (p *Provenance) scalarAbove(root, path string) bool {
+	for path = Parent(path); Contains(root, path); path = Parent(path) {
+		if _, ok := p.values[path]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// renumber moves the items of the sequence at the given path to the returned index, dropping the
+// items with a negative one
+func (p *Provenance) renumber(path string, index func(i int) int) {
+	items := map[string]string{}
+	for i := range p.lengths[path] {
+		if j := index(i); j >= 0 {
+			items[Index(path, i)] = Index(path, j)
+		} else {
+			items[Index(path, i)] = ""
+		}
+	}
+
+	rename := func(key string) (string, bool) {
+		end := strings.Index(strings.TrimPrefix(key, path), "]")
+		if !strings.HasPrefix(key, path+"[") || end < 0 {
+			return key, true
+		}
+
+		item := key[:len(path)+end+1]
+		if to, ok := items[item]; !ok {
+			return key, true
+		} else if to != "" {
+			return to + key[len(item):], true
+		}
+
+		return "", false
+	}
+
+	values := make(map[string]*Value, len(p.values))
+	for key, value := range p.values {
+		if to, ok := rename(key); ok {
+			value.Path = to
+			values[to] = value
+		}
+	}
+
+	lengths := make(map[string]int, len(p.lengths))
+	for key, length := range p.lengths {
+		if to, ok := rename(key); ok {
+			lengths[to] = length
+		}
+	}
+
+	p.values = values
+	p.lengths = lengths
+}
+
+func (p *Provenance) add(file, path string, node *yaml.Node) {
+	switch node.Kind {
+	case yaml.AliasNode:
+		p.add(file, path, node.Alias)
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			p.add(file, Join(path, node.Content[i].Value), node.Content[i+1])
+		}
+	case yaml.SequenceNode:
+		offset := p.lengths[path]
+		for i, item := range node.Content {
+			p.add(file, Index(path, offset+i), item)
+		}
+
+		p.lengths[path] = offset + len(node.Content)
+	default:
+		// equal values are kept by conflate
+		if existing, ok := p.values[path]; ok && existing.tag == node.Tag && existing.value == node.Value {
+			return
+		}
+
+		value := &Value{Path: path, Source: Source{File: file, Line: node.Line}, tag: node.Tag, value: node.Value}
+
+		// an explicit null replaces the whole subtree
+		for _, existing := range p.Values(path) {
+			value.Overrides = append(value.Overrides, existing.Overrides...)
+			value.Overrides = append(value.Overrides, existing.Source)
+
+			if existing.Path != path && node.Tag == "!!null" {
+				delete(p.values, existing.Path)
+			}
+		}
+
+		if node.Tag == "!!null" {
+			delete(p.lengths, path)
+		}
+
+		p.values[path] = value
+	}
+}
+
+// annotate adds the line comments, collects the overriding values below the target and returns the target node
+func (p *Provenance) annotate(path string, node *yaml.Node, target string, overrides map[string]*Value) *yaml.Node {
+	var ret *yaml.Node
+	if path == target {
+		ret = node
+	}
+
+	// block style to fit the comments
+	node.Style &^= yaml.FlowStyle
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if n := p.annotate(Join(path, node.Content[i].Value), node.Content[i+1], target, overrides); n != nil {
+				ret = n
+			}
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			if n := p.annotate(Index(path, i), item, target, overrides); n != nil {
+				ret = n
+			}
+		}
+	case yaml.ScalarNode:
+		value, ok := p.Get(path)
+		if !ok {
+			break
+		}
+
+		node.LineComment = value.Source.String()
+		if len(value.Overrides) > 0 {
+			node.LineComment += " (overrides " + value.Overrides[len(value.Overrides)-1].String() + ")"
+
+			if target == "" || path == target || Contains(target, path) {
+				overrides[value.Path] = value
+			}
+		}
+	}
+
+	return ret
+}
@@ -0,0 +1,186 @@
+package provenance_test
+
+import (
+	"testing"
+
+	testingx "github.com/foomo/go/testing"
+	tagx "github.com/foomo/go/testing/tag"
+	"github.com/foomo/squadron/internal/provenance"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const base = `version: '2.3'
+vars:
+  replicas: 1
+  hosts:
+    - a.local
+  debug:
+    enabled: true
+`
+
+const override = `version: '2.3'
+vars:
+  replicas: 3
+  hosts:
+    - b.local
+  debug: ~
+`
+
+func TestProvenance_Get(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	p := provenance.New()
+	require.NoError(t, p.Add("base.yaml", []byte(base)))
+	require.NoError(t, p.Add("override.yaml", []byte(override)))
+
+	t.Run("override", func(t *testing.T) {
+		value, ok := p.Get("vars.replicas")
+		require.True(t, ok)
+		assert.Equal(t, "override.yaml:3", value.Source.String())
+		assert.Equal(t, []provenance.Source{{File: "base.yaml", Line: 3}}, value.Overrides)
+	})
+
+	t.Run("equal", func(t *testing.T) {
+		value, ok := p.Get("version")
+		require.True(t, ok)
+		assert.Equal(t, "base.yaml:1", value.Source.String())
+		assert.Empty(t, value.Overrides)
+	})
+
+	t.Run("append", func(t *testing.T) {
+		value, ok := p.Get("vars.hosts[1]")
+		require.True(t, ok)
+		assert.Equal(t, "override.yaml:5", value.Source.String())
+		assert.Empty(t, value.Overrides)
+	})
+
+	t.Run("null", func(t *testing.T) {
+		value, ok := p.Get("vars.debug.enabled")
+		require.True(t, ok)
+		assert.Equal(t, "vars.debug", value.Path)
+		assert.Equal(t, []provenance.Source{{File: "base.yaml", Line: 7}}, value.Overrides)
+	})
+}
+
+func TestProvenance_Explain(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	p := provenance.New()
+	require.NoError(t, p.Add("base.yaml", []byte(base)))
+	require.NoError(t, p.Add("override.yaml", []byte(override)))
+
+	merged := []byte("vars:\n  replicas: 3\n  hosts: [a.local, b.local]\n")
+
+	out, values, err := p.Explain(merged, "vars")
+	require.NoError(t, err)
+	assert.Equal(t, `replicas: 3 # override.yaml:3 (overrides base.yaml:3)
+hosts:
+  - a.local # base.yaml:5
+  - b.local # override.yaml:5
+`, string(out))
+	require.Len(t, values, 1)
+	assert.Equal(t, "vars.replicas", values[0].Path)
+
+	_, _, err = p.Explain(merged, "vars.unknown")
+	require.EqualError(t, err, "unknown path `vars.unknown`")
+}
+
+func TestProvenance_Overlay(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	p := provenance.New()
+	require.NoError(t, p.Add("base.yaml", []byte(base)))
+	require.NoError(t, p.Add("env.yaml", []byte("environments:\n  prod:\n    vars:\n      replicas: 5\n")))
+	p.Overlay("environments.prod.vars", "vars")
+
+	value, ok := p.Get("vars.replicas")
+	require.True(t, ok)
+	assert.Equal(t, "env.yaml:4", value.Source.String())
+	assert.Equal(t, []provenance.Source{{File: "base.yaml", Line: 3}}, value.Overrides)
+}
+
+func TestProvenance_escaped(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	p := provenance.New()
+	require.NoError(t, p.Add("labels.yaml", []byte("labels:\n  app.kubernetes.io/name: flat\n  app:\n    kubernetes:\n      io/name: nested\n")))
+
+	flat, ok := p.Get(`labels.app\.kubernetes\.io/name`)
+	require.True(t, ok)
+	assert.Equal(t, "labels.yaml:2", flat.Source.String())
+	assert.Empty(t, flat.Overrides)
+
+	nested, ok := p.Get("labels.app.kubernetes.io/name")
+	require.True(t, ok)
+	assert.Equal(t, "labels.yaml:5", nested.Source.String())
+	assert.Empty(t, nested.Overrides)
+
+	assert.Equal(t, "labels", provenance.Parent(flat.Path))
+	assert.Equal(t, "labels.app.kubernetes", provenance.Parent(nested.Path))
+	assert.Equal(t, `a.b\.c\[0]`, provenance.Join("a", "b.c[0]"))
+}
+
+const templates = `templates:
+  service:
+    chart: backend
+    tags: [service, go]
+    values:
+      image: nginx
+      env:
+        - LOG_LEVEL
+units:
+  backend:
+    chart:
+      name: frontend
+    tags: [go, backend]
+    values:
+      image: backend
+      env:
+        - STORE
+`
+
+func TestProvenance_Inherit(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	p := provenance.New()
+	require.NoError(t, p.Add("squadron.yaml", []byte(templates)))
+	p.Inherit("templates.service", "units.backend", func(path string) provenance.Merge {
+		switch {
+		case path == "chart":
+			return provenance.MergeReplace
+		case path == "tags":
+			return provenance.MergeAppendUnique
+		default:
+			return provenance.MergeAppend
+		}
+	})
+
+	tests := []struct {
+		path      string
+		source    string
+		overrides []provenance.Source
+	}{
+		{path: "units.backend.chart.name", source: "squadron.yaml:12"},
+		{path: "units.backend.tags[0]", source: "squadron.yaml:4"},
+		{path: "units.backend.tags[1]", source: "squadron.yaml:4"},
+		{path: "units.backend.tags[2]", source: "squadron.yaml:13"},
+		{path: "units.backend.values.image", source: "squadron.yaml:15", overrides: []provenance.Source{{File: "squadron.yaml", Line: 6}}},
+		{path: "units.backend.values.env[0]", source: "squadron.yaml:8"},
+		{path: "units.backend.values.env[1]", source: "squadron.yaml:17"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			value, ok := p.Get(tt.path)
+			require.True(t, ok)
+			assert.Equal(t, tt.path, value.Path)
+			assert.Equal(t, tt.source, value.Source.String())
+			assert.Equal(t, tt.overrides, value.Overrides)
+		})
+	}
+
+	_, ok := p.Get("units.backend.tags[3]")
+	assert.False(t, ok)
+	_, ok = p.Get("units.backend.chart")
+	assert.False(t, ok)
+}
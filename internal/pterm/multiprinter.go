@@ -17,7 +17,7 @@ func MustNewMultiPrinter() MultiPrinter {
 		value MultiPrinter
 	)
 
-	if _, ok := os.LookupEnv("CI"); ok {
+	if _, ok := os.LookupEnv("CI"); ok || !pterm.Output {
 		value, err = NewNoopMultiPrinter()
 	} else {
 		value, err = NewStandardMultiPrinter()
@@ -12,7 +12,6 @@ type Spinner interface {
 	Fail(message ...string)
 	Success(message ...string)
 	Write(p []byte) (int, error)
-	message(message ...string) string
 }
 
 func ContextWithSpinner(ctx context.Context, s Spinner) context.Context {
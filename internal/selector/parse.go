@@ -0,0 +1,316 @@
+package selector
+
+import (
+	"path"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// token kinds
+const (
+	tokenEOF = iota
+	tokenWord
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+type token struct {
+	kind  int
+	value string
+	pos   int
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public functions
+// ------------------------------------------------------------------------------------------------
+
+// Parse parses a selector expression, e.g.
+//
+//	squadron in (checkout,storefinder) && (tag:backend || tag:critical) && !tag:skip
+//
+// Terms are `squadron:<glob>`, `unit:<glob>`, `tag:<glob>`, `<field> in (<glob>,...)`,
+// `<squadron glob>/<unit glob>` or a bare `<squadron glob>`. They can be combined with `!`,
+// `&&`, `||` and parentheses, `&&` binding stronger than `||`.
+func Parse(expr string) (Selector, error) {
+	tokens, err := lex(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+
+	ret, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, p.unexpected(t)
+	}
+
+	return ret, nil
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private methods
+// ------------------------------------------------------------------------------------------------
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+
+	return t
+}
+
+func (p *parser) expect(kind int) (token, error) {
+	t := p.next()
+	if t.kind != kind {
+		return t, p.unexpected(t)
+	}
+
+	return t, nil
+}
+
+func (p *parser) unexpected(t token) error {
+	if t.kind == tokenEOF {
+		return errors.Errorf("unexpected end of selector at %d", t.pos)
+	}
+
+	return errors.Errorf("unexpected `%s` in selector at %d", t.value, t.pos)
+}
+
+func (p *parser) or() (Selector, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokenOr {
+		p.next()
+
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+
+		left = or{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) and() (Selector, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokenAnd {
+		p.next()
+
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+
+		left = and{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) unary() (Selector, error) {
+	switch t := p.next(); t.kind {
+	case tokenNot:
+		expr, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+
+		return not{expr: expr}, nil
+	case tokenLParen:
+		expr, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := p.expect(tokenRParen); err != nil {
+			return nil, err
+		}
+
+		return expr, nil
+	case tokenWord:
+		return p.term(t)
+	default:
+		return nil, p.unexpected(t)
+	}
+}
+
+func (p *parser) term(t token) (Selector, error) {
+	// <field> in (<glob>,...)
+	if next := p.peek(); next.kind == tokenWord && next.value == "in" {
+		field, err := parseField(t)
+		if err != nil {
+			return nil, err
+		}
+
+		p.next()
+
+		patterns, err := p.list()
+		if err != nil {
+			return nil, err
+		}
+
+		return term{field: field, patterns: patterns}, nil
+	}
+
+	// <field>:<glob>
+	if name, pattern, ok := strings.Cut(t.value, ":"); ok {
+		field, err := parseField(token{kind: tokenWord, value: name, pos: t.pos})
+		if err != nil {
+			return nil, err
+		}
+
+		if err := validate(pattern, t.pos+len(name)+1); err != nil {
+			return nil, err
+		}
+
+		return term{field: field, patterns: []string{pattern}}, nil
+	}
+
+	// <squadron glob>/<unit glob>
+	if squadron, unit, ok := strings.Cut(t.value, "/"); ok {
+		if err := validate(squadron, t.pos); err != nil {
+			return nil, err
+		}
+
+		if err := validate(unit, t.pos+len(squadron)+1); err != nil {
+			return nil, err
+		}
+
+		return unitTerm{squadron: squadron, unit: unit}, nil
+	}
+
+	if err := validate(t.value, t.pos); err != nil {
+		return nil, err
+	}
+
+	return term{field: FieldSquadron, patterns: []string{t.value}}, nil
+}
+
+func (p *parser) list() ([]string, error) {
+	if _, err := p.expect(tokenLParen); err != nil {
+		return nil, err
+	}
+
+	var ret []string
+
+	for {
+		t, err := p.expect(tokenWord)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := validate(t.value, t.pos); err != nil {
+			return nil, err
+		}
+
+		ret = append(ret, t.value)
+
+		if t := p.next(); t.kind == tokenRParen {
+			return ret, nil
+		} else if t.kind != tokenComma {
+			return nil, p.unexpected(t)
+		}
+	}
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private functions
+// ------------------------------------------------------------------------------------------------
+
+func lex(expr string) ([]token, error) {
+	var ret []token
+
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			ret = append(ret, token{kind: tokenLParen, value: "(", pos: i})
+			i++
+		case r == ')':
+			ret = append(ret, token{kind: tokenRParen, value: ")", pos: i})
+			i++
+		case r == ',':
+			ret = append(ret, token{kind: tokenComma, value: ",", pos: i})
+			i++
+		case r == '!':
+			ret = append(ret, token{kind: tokenNot, value: "!", pos: i})
+			i++
+		case r == '&' || r == '|':
+			if i+1 >= len(runes) || runes[i+1] != r {
+				return nil, errors.Errorf("unexpected `%c` in selector at %d, did you mean `%c%c`?", r, i, r, r)
+			}
+
+			kind := tokenAnd
+			if r == '|' {
+				kind = tokenOr
+			}
+
+			ret = append(ret, token{kind: kind, value: string([]rune{r, r}), pos: i})
+			i += 2
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune("(),!&|", runes[i]) {
+				i++
+			}
+
+			ret = append(ret, token{kind: tokenWord, value: string(runes[start:i]), pos: start})
+		}
+	}
+
+	return append(ret, token{kind: tokenEOF, pos: len(runes)}), nil
+}
+
+func parseField(t token) (Field, error) {
+	switch field := Field(t.value); field {
+	case FieldSquadron, FieldUnit, FieldTag:
+		return field, nil
+	default:
+		return "", errors.Errorf("unknown field `%s` in selector at %d, expected one of squadron, unit or tag", t.value, t.pos)
+	}
+}
+
+// validate checks the glob pattern
+func validate(pattern string, pos int) error {
+	if pattern == "" {
+		return errors.Errorf("empty pattern in selector at %d", pos)
+	}
+
+	if _, err := path.Match(pattern, ""); err != nil {
+		return errors.Wrapf(err, "invalid pattern `%s` in selector at %d", pattern, pos)
+	}
+
+	return nil
+}
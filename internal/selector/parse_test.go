@@ -0,0 +1,116 @@
+package selector_test
+
+import (
+	"testing"
+
+	testingx "github.com/foomo/go/testing"
+	tagx "github.com/foomo/go/testing/tag"
+	"github.com/foomo/squadron/internal/selector"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{expr: "checkout", want: "squadron:checkout"},
+		{expr: "checkout/*-worker", want: "checkout/*-worker"},
+		{expr: "tag:backend", want: "tag:backend"},
+		{expr: "unit in (frontend, backend)", want: "unit in (frontend,backend)"},
+		{expr: "!!tag:skip", want: "!!tag:skip"},
+		{expr: "tag:a || tag:b && tag:c", want: "(tag:a || (tag:b && tag:c))"},
+		{expr: "(tag:a || tag:b) && tag:c", want: "((tag:a || tag:b) && tag:c)"},
+		{
+			expr: "squadron in (checkout,storefinder) && (tag:backend || tag:critical) && !tag:skip",
+			want: "((squadron in (checkout,storefinder) && (tag:backend || tag:critical)) && !tag:skip)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := selector.Parse(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, s.String())
+
+			// the normalized expression parses to the same selector
+			again, err := selector.Parse(s.String())
+			require.NoError(t, err)
+			assert.Equal(t, s, again)
+		})
+	}
+}
+
+func TestParse_error(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{expr: "", want: "unexpected end of selector at 0"},
+		{expr: "tag:a &&", want: "unexpected end of selector at 8"},
+		{expr: "tag:a & tag:b", want: "unexpected `&` in selector at 6, did you mean `&&`?"},
+		{expr: "(tag:a", want: "unexpected end of selector at 6"},
+		{expr: "tag:a)", want: "unexpected `)` in selector at 5"},
+		{expr: "tag:a tag:b", want: "unexpected `tag:b` in selector at 6"},
+		{expr: "label:a", want: "unknown field `label` in selector at 0, expected one of squadron, unit or tag"},
+		{expr: "unit in (a,)", want: "unexpected `)` in selector at 11"},
+		{expr: "unit in a", want: "unexpected `a` in selector at 8"},
+		{expr: "tag:", want: "empty pattern in selector at 4"},
+		{expr: "checkout/[a", want: "invalid pattern `[a` in selector at 9: syntax error in pattern"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := selector.Parse(tt.expr)
+			require.EqualError(t, err, tt.want)
+		})
+	}
+}
+
+func TestSelector_Match(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	targets := []selector.Target{
+		{Squadron: "checkout", Unit: "api", Tags: []string{"backend", "critical"}},
+		{Squadron: "checkout", Unit: "mail-worker", Tags: []string{"backend", "skip"}},
+		{Squadron: "checkout", Unit: "order-worker", Tags: []string{"backend"}},
+		{Squadron: "storefinder", Unit: "frontend", Tags: []string{"frontend", "critical"}},
+		{Squadron: "storefinder", Unit: "backend", Tags: []string{"backend"}},
+		{Squadron: "admin", Unit: "backend", Tags: []string{"backend"}},
+	}
+
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{expr: "checkout", want: []string{"checkout/api", "checkout/mail-worker", "checkout/order-worker"}},
+		{expr: "checkout/*-worker", want: []string{"checkout/mail-worker", "checkout/order-worker"}},
+		{expr: "*/backend", want: []string{"storefinder/backend", "admin/backend"}},
+		{expr: "unit:*end", want: []string{"storefinder/frontend", "storefinder/backend", "admin/backend"}},
+		{expr: "tag:critical", want: []string{"checkout/api", "storefinder/frontend"}},
+		{expr: "!tag:backend", want: []string{"storefinder/frontend"}},
+		{
+			expr: "squadron in (checkout,storefinder) && (tag:backend || tag:critical) && !tag:skip",
+			want: []string{"checkout/api", "checkout/order-worker", "storefinder/frontend", "storefinder/backend"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := selector.Parse(tt.expr)
+			require.NoError(t, err)
+
+			var got []string
+
+			for _, target := range targets {
+				if s.Match(target) {
+					got = append(got, target.Squadron+"/"+target.Unit)
+				}
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package selector
+
+import (
+	"path"
+	"slices"
+	"strings"
+)
+
+// Field of a unit matched by a term
+type Field string
+
+const (
+	FieldSquadron Field = "squadron"
+	FieldUnit     Field = "unit"
+	FieldTag      Field = "tag"
+)
+
+// Target is the unit a selector is matched against
+type Target struct {
+	Squadron string
+	Unit     string
+	Tags     []string
+}
+
+// Selector is a parsed selector expression
+type Selector interface {
+	// Match returns true if the target is selected
+	Match(target Target) bool
+	// String returns the normalized expression
+	String() string
+}
+
+type (
+	and struct {
+		left, right Selector
+	}
+	or struct {
+		left, right Selector
+	}
+	not struct {
+		expr Selector
+	}
+	// term matches a field against a list of glob patterns
+	term struct {
+		field    Field
+		patterns []string
+	}
+	// unitTerm matches `squadron/unit` glob patterns
+	unitTerm struct {
+		squadron string
+		unit     string
+	}
+)
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public methods
+// ------------------------------------------------------------------------------------------------
+
+func (s and) Match(target Target) bool {
+	return s.left.Match(target) && s.right.Match(target)
+}
+
+func (s and) String() string {
+	return "(" + s.left.String() + " && " + s.right.String() + ")"
+}
+
+func (s or) Match(target Target) bool {
+	return s.left.Match(target) || s.right.Match(target)
+}
+
+func (s or) String() string {
+	return "(" + s.left.String() + " || " + s.right.String() + ")"
+}
+
+func (s not) Match(target Target) bool {
+	return !s.expr.Match(target)
+}
+
+func (s not) String() string {
+	return "!" + s.expr.String()
+}
+
+func (s term) Match(target Target) bool {
+	var values []string
+
+	switch s.field {
+	case FieldSquadron:
+		values = []string{target.Squadron}
+	case FieldUnit:
+		values = []string{target.Unit}
+	case FieldTag:
+		values = target.Tags
+	}
+
+	return slices.ContainsFunc(s.patterns, func(pattern string) bool {
+		return slices.ContainsFunc(values, func(value string) bool {
+			return glob(pattern, value)
+		})
+	})
+}
+
+func (s term) String() string {
+	if len(s.patterns) == 1 {
+		return string(s.field) + ":" + s.patterns[0]
+	}
+
+	return string(s.field) + " in (" + strings.Join(s.patterns, ",") + ")"
+}
+
+func (s unitTerm) Match(target Target) bool {
+	return glob(s.squadron, target.Squadron) && glob(s.unit, target.Unit)
+}
+
+func (s unitTerm) String() string {
+	return s.squadron + "/" + s.unit
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private methods
+// ------------------------------------------------------------------------------------------------
+
+// glob matches the value against the pattern, which has been validated while parsing
+func glob(pattern, value string) bool {
+	ok, _ := path.Match(pattern, value)
+	return ok
+}
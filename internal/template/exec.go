@@ -0,0 +1,47 @@
+package template
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ExecSecretProvider reads secrets from the output of a command, e.g. a password manager cli
+type ExecSecretProvider struct{}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Constructor
+// ------------------------------------------------------------------------------------------------
+
+func NewExecSecretProvider() *ExecSecretProvider {
+	return &ExecSecretProvider{}
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public methods
+// ------------------------------------------------------------------------------------------------
+
+// Secret runs the command and returns its trimmed stdout (args: command, [args...])
+// e.g. `exec "pass" "show" "my-app/password"`
+func (p *ExecSecretProvider) Secret(ctx context.Context, args ...string) (string, error) {
+	if len(args) == 0 {
+		return "", errors.New("missing command")
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...) //nolint:gosec
+	cmd.Env = os.Environ()
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", errors.Wrap(err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
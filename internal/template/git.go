@@ -1,29 +1,119 @@
 package template
 
 import (
-	"bytes"
 	"context"
-	"os/exec"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	gitx "github.com/foomo/squadron/internal/git"
+	"github.com/pkg/errors"
 )
 
-func git(ctx context.Context) func(action string) (string, error) {
-	return func(action string) (string, error) {
-		cmd := exec.CommandContext(ctx, "git")
+type contextKey string
+
+const contextKeyGit contextKey = "git"
+
+// gitRepository lazily opens the repository on first use
+type gitRepository struct {
+	once sync.Once
+	repo *gitx.Repository
+	err  error
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public functions
+// ------------------------------------------------------------------------------------------------
+
+// ContextWithGit returns a context sharing the results of the `git` template function, e.g. for
+// all templates of a render
+func ContextWithGit(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKeyGit, &gitRepository{})
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private functions
+// ------------------------------------------------------------------------------------------------
+
+// git returns metadata of the repository at `GIT_DIR`, `PROJECT_ROOT` or the working directory:
+//
+//	commitsha, abbrevcommitsha, describe, branch, tag, dirty, committime, committimestamp,
+//	author, authoremail, originurl, changed <rev> [paths...]
+//
+// Any other action returns the result of `describe`.
+func git(ctx context.Context) func(action string, args ...string) (any, error) {
+	return func(action string, args ...string) (any, error) {
+		repo, err := gitFromContext(ctx)
+		if err != nil {
+			return nil, err
+		}
 
 		switch action {
 		case "commitsha":
-			cmd.Args = append(cmd.Args, "rev-list", "-1", "HEAD")
+			return repo.Commit()
 		case "abbrevcommitsha":
-			cmd.Args = append(cmd.Args, "rev-list", "-1", "HEAD", "--abbrev-commit")
+			return repo.AbbrevCommit()
+		case "branch":
+			return repo.Branch()
+		case "tag":
+			return repo.Tag()
+		case "dirty":
+			return repo.Dirty()
+		case "committime":
+			t, err := repo.CommitTime()
+			if err != nil {
+				return nil, err
+			}
+
+			return t.UTC().Format(time.RFC3339), nil
+		case "committimestamp":
+			t, err := repo.CommitTime()
+			if err != nil {
+				return nil, err
+			}
+
+			return strconv.FormatInt(t.Unix(), 10), nil
+		case "author":
+			name, _, err := repo.Author()
+			return name, err
+		case "authoremail":
+			_, email, err := repo.Author()
+			return email, err
+		case "originurl":
+			return repo.OriginURL()
+		case "changed":
+			if len(args) == 0 {
+				return nil, errors.New("missing revision for git `changed`")
+			}
+
+			return repo.Changed(args[0], args[1:]...)
 		default:
-			cmd.Args = append(cmd.Args, "describe", "--tags", "--always")
+			// like `describe`, the default of previous versions
+			return repo.Describe()
 		}
+	}
+}
 
-		res, err := cmd.Output()
-		if err != nil {
-			return "", err
+// gitFromContext returns the repository shared through the context or opens a new one
+func gitFromContext(ctx context.Context) (*gitx.Repository, error) {
+	value, ok := ctx.Value(contextKeyGit).(*gitRepository)
+	if !ok {
+		value = &gitRepository{}
+	}
+
+	value.once.Do(func() {
+		dir := "."
+
+		for _, s := range []string{"GIT_DIR", "PROJECT_ROOT"} {
+			if v := os.Getenv(s); v != "" {
+				dir = v
+				break
+			}
 		}
 
-		return string(bytes.TrimSpace(res)), nil
-	}
+		value.repo, value.err = gitx.Open(dir)
+	})
+
+	return value.repo, value.err
 }
@@ -20,7 +20,8 @@ import (
 )
 
 var (
-	onePasswordCache map[string]map[string]string
+	// onePasswordCache holds the json encoded fields of the resolved items
+	onePasswordCache = NewSecretCache(0)
 	onePasswordUUID  = regexp.MustCompile(`^[a-z0-9]{26}$`)
 )
 
@@ -183,18 +184,21 @@ func isServiceAccount() bool {
 	return os.Getenv("OP_SERVICE_ACCOUNT_TOKEN") != ""
 }
 
-var onePasswordInitLock sync.Mutex
+var (
+	onePasswordInitLock sync.Mutex
+	onePasswordInitDone bool
+)
 
 func onePasswordInit(ctx context.Context, account string) error {
 	onePasswordInitLock.Lock()
 	defer onePasswordInitLock.Unlock()
 
-	// validate cache
-	if onePasswordCache != nil {
+	// validate init
+	if onePasswordInitDone {
 		return nil
 	}
 
-	onePasswordCache = map[string]map[string]string{}
+	onePasswordInitDone = true
 
 	// validate env
 	if isConnect() || isServiceAccount() {
@@ -226,10 +230,6 @@ func onePasswordInit(ctx context.Context, account string) error {
 
 func onePassword(ctx context.Context, templateVars any, errorOnMissing bool) func(account, vaultUUID, itemUUID, field string) (string, error) {
 	return func(account, vaultUUID, itemUUID, field string) (string, error) {
-		// init
-		if err := onePasswordInit(ctx, account); err != nil {
-			return "", err
-		}
 		// render uuid & field params
 		if value, err := onePasswordRender("op", itemUUID, templateVars, errorOnMissing); err != nil {
 			return "", err
@@ -243,79 +243,104 @@ func onePassword(ctx context.Context, templateVars any, errorOnMissing bool) fun
 			field = value
 		}
 
-		// create cache key
-		cacheKey := strings.Join([]string{account, vaultUUID, itemUUID}, "#")
-
-		if _, ok := onePasswordCache[cacheKey]; !ok {
-			if isConnect() {
-				client, err := connect.NewClientFromEnvironment()
-				if err != nil {
-					return "", err
-				}
-
-				if res, err := onePasswordConnectGet(client, vaultUUID, itemUUID); err != nil {
-					return "", err
-				} else {
-					onePasswordCache[cacheKey] = res
-				}
-			} else {
-				if res, err := onePasswordGet(ctx, account, vaultUUID, itemUUID); err != nil {
-					return "", err
-				} else {
-					onePasswordCache[cacheKey] = res
-				}
-			}
-		}
-
-		if value, ok := onePasswordCache[cacheKey][field]; !ok {
-			return "", nil
-		} else {
-			return value, nil
-		}
+		return DefaultSecretRegistry.Secret(ctx, "op", account, vaultUUID, itemUUID, field)
 	}
 }
 
 func onePasswordDocument(ctx context.Context, templateVars any, errorOnMissing bool) func(account, vaultUUID, itemUUID string) (string, error) {
 	return func(account, vaultUUID, itemUUID string) (string, error) {
-		// init
-		if err := onePasswordInit(ctx, account); err != nil {
-			return "", err
-		}
-
-		// render uuid & field params
+		// render uuid params
 		if value, err := onePasswordRender("op", itemUUID, templateVars, errorOnMissing); err != nil {
 			return "", err
 		} else {
 			itemUUID = value
 		}
 
-		// create cache key
-		cacheKey := strings.Join([]string{account, vaultUUID, itemUUID}, "#")
-
-		if _, ok := onePasswordCache[cacheKey]; !ok {
-			if isConnect() {
-				if client, err := connect.NewClientFromEnvironment(); err != nil {
-					return "", err
-				} else if res, err := onePasswordConnectGetDocument(client, vaultUUID, itemUUID); err != nil {
-					return "", err
-				} else {
-					onePasswordCache[cacheKey] = map[string]string{"document": res}
-				}
-			} else {
-				if res, err := onePasswordGetDocument(ctx, account, vaultUUID, itemUUID); err != nil {
-					return "", err
-				} else {
-					onePasswordCache[cacheKey] = map[string]string{"document": res}
-				}
+		return DefaultSecretRegistry.Secret(ctx, "opDoc", account, vaultUUID, itemUUID)
+	}
+}
+
+// onePasswordSecret resolves the field of an item (args: account, vault, item, field)
+func onePasswordSecret(ctx context.Context, args ...string) (string, error) {
+	if len(args) != 4 {
+		return "", errors.Errorf("expected 4 arguments (account, vault, item, field) but got %d", len(args))
+	}
+
+	account, vaultUUID, itemUUID, field := args[0], args[1], args[2], args[3]
+
+	// init
+	if err := onePasswordInit(ctx, account); err != nil {
+		return "", err
+	}
+
+	// create cache key
+	cacheKey := strings.Join([]string{"item", account, vaultUUID, itemUUID}, "#")
+
+	res, err := onePasswordCache.Get(ctx, cacheKey, func(ctx context.Context) (string, error) {
+		var (
+			fields map[string]string
+			err    error
+		)
+
+		if isConnect() {
+			client, cerr := connect.NewClientFromEnvironment()
+			if cerr != nil {
+				return "", cerr
 			}
-		}
 
-		if value, ok := onePasswordCache[cacheKey]["document"]; !ok {
-			return "", nil
+			fields, err = onePasswordConnectGet(client, vaultUUID, itemUUID)
 		} else {
-			return value, nil
+			fields, err = onePasswordGet(ctx, account, vaultUUID, itemUUID)
 		}
+
+		if err != nil {
+			return "", err
+		}
+
+		out, err := json.Marshal(fields)
+
+		return string(out), err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var fields map[string]string
+	if err := json.Unmarshal([]byte(res), &fields); err != nil {
+		return "", errors.Wrap(err, "failed to unmarshal item fields")
 	}
+
+	return fields[field], nil
+}
+
+// onePasswordDocumentSecret resolves a document (args: account, vault, item)
+func onePasswordDocumentSecret(ctx context.Context, args ...string) (string, error) {
+	if len(args) != 3 {
+		return "", errors.Errorf("expected 3 arguments (account, vault, item) but got %d", len(args))
+	}
+
+	account, vaultUUID, itemUUID := args[0], args[1], args[2]
+
+	// init
+	if err := onePasswordInit(ctx, account); err != nil {
+		return "", err
+	}
+
+	// create cache key
+	cacheKey := strings.Join([]string{"document", account, vaultUUID, itemUUID}, "#")
+
+	return onePasswordCache.Get(ctx, cacheKey, func(ctx context.Context) (string, error) {
+		if isConnect() {
+			client, err := connect.NewClientFromEnvironment()
+			if err != nil {
+				return "", err
+			}
+
+			return onePasswordConnectGetDocument(client, vaultUUID, itemUUID)
+		}
+
+		return onePasswordGetDocument(ctx, account, vaultUUID, itemUUID)
+	})
 }
 
 func onePasswordRender(name, text string, data any, errorOnMissing bool) (string, error) {
@@ -0,0 +1,98 @@
+package template
+
+import (
+	"slices"
+	"text/template/parse"
+)
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public functions
+// ------------------------------------------------------------------------------------------------
+
+// References returns the field chains of the template data used in the given text, e.g.
+// `<% .Vars.foo.bar %>` references `[Vars foo bar]`. Fields relative to a changed dot within
+// `range` and `with` blocks and within defined templates are not included.
+func References(text string) ([][]string, error) {
+	t := parse.New("squadron")
+	t.Mode = parse.SkipFuncCheck
+
+	if _, err := t.Parse(text, "<% ", " %>", map[string]*parse.Tree{}); err != nil {
+		return nil, err
+	}
+
+	var ret [][]string
+
+	add := func(ref []string) {
+		if len(ref) > 0 && !slices.ContainsFunc(ret, func(v []string) bool { return slices.Equal(v, ref) }) {
+			ret = append(ret, slices.Clone(ref))
+		}
+	}
+
+	var walk func(node parse.Node, root bool)
+
+	walk = func(node parse.Node, root bool) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+
+			for _, item := range n.Nodes {
+				walk(item, root)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe, root)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+
+			for _, cmd := range n.Cmds {
+				walk(cmd, root)
+			}
+		case *parse.CommandNode:
+			for _, arg := range n.Args {
+				walk(arg, root)
+			}
+		case *parse.FieldNode:
+			if root {
+				add(n.Ident)
+			}
+		case *parse.VariableNode:
+			if len(n.Ident) > 1 && n.Ident[0] == "$" {
+				add(n.Ident[1:])
+			}
+		case *parse.ChainNode:
+			switch base := n.Node.(type) {
+			case *parse.FieldNode:
+				if root {
+					add(append(slices.Clone(base.Ident), n.Field...))
+				}
+			case *parse.VariableNode:
+				if base.Ident[0] == "$" {
+					add(append(slices.Clone(base.Ident[1:]), n.Field...))
+				}
+			default:
+				walk(n.Node, root)
+			}
+		case *parse.IfNode:
+			walk(n.Pipe, root)
+			walk(n.List, root)
+			walk(n.ElseList, root)
+		case *parse.RangeNode:
+			walk(n.Pipe, root)
+			walk(n.List, false)
+			walk(n.ElseList, root)
+		case *parse.WithNode:
+			walk(n.Pipe, root)
+			walk(n.List, false)
+			walk(n.ElseList, root)
+		case *parse.TemplateNode:
+			walk(n.Pipe, root)
+		}
+	}
+
+	walk(t.Root, true)
+
+	return ret, nil
+}
@@ -0,0 +1,40 @@
+package template_test
+
+import (
+	"testing"
+
+	testingx "github.com/foomo/go/testing"
+	tagx "github.com/foomo/go/testing/tag"
+	"github.com/foomo/squadron/internal/template"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReferences(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	tests := []struct {
+		text string
+		want [][]string
+	}{
+		{text: "plain", want: nil},
+		{text: "<% .Vars.foo %>-<% .Vars.foo %>", want: [][]string{{"Vars", "foo"}}},
+		{text: `<% .Global.host | default "x" %>`, want: [][]string{{"Global", "host"}}},
+		{text: "<% index .Squadron.a.b.builds 0 %>", want: [][]string{{"Squadron", "a", "b", "builds"}}},
+		{text: "<% ($.Vars.map).key %>", want: [][]string{{"Vars", "map"}}},
+		{text: "<% toYaml .Vars %>", want: [][]string{{"Vars"}}},
+		{text: "<% if .Vars.a %><% .Vars.b %><% else %><% .Vars.c %><% end %>", want: [][]string{{"Vars", "a"}, {"Vars", "b"}, {"Vars", "c"}}},
+		{text: "<% range .Vars.list %><% .name %><% $.Global.x %><% end %>", want: [][]string{{"Vars", "list"}, {"Global", "x"}}},
+		{text: "<% unknownFunc .Env %>", want: [][]string{{"Env"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := template.References(tt.text)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := template.References("<% .Vars.foo")
+	require.Error(t, err)
+}
@@ -0,0 +1,156 @@
+package template
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// SecretProvider resolves secrets for the template function it is registered with
+type SecretProvider interface {
+	// Secret returns the secret for the given template function arguments
+	Secret(ctx context.Context, args ...string) (string, error)
+}
+
+// SecretProviderFunc adapts a function to the SecretProvider interface
+type SecretProviderFunc func(ctx context.Context, args ...string) (string, error)
+
+// SecretRegistry holds the secret providers by template function name and caches their results
+type SecretRegistry struct {
+	lock      sync.RWMutex
+	offline   bool
+	providers map[string]SecretProvider
+	cache     *SecretCache
+}
+
+// networkSecretProvider marks providers that are not available in offline mode
+type networkSecretProvider struct {
+	SecretProvider
+}
+
+// DefaultSecretRegistry is used by ExecuteFileTemplate
+var DefaultSecretRegistry = NewSecretRegistry()
+
+func init() {
+	DefaultSecretRegistry.Register("op", RequiresNetwork(SecretProviderFunc(onePasswordSecret)))
+	DefaultSecretRegistry.Register("opDoc", RequiresNetwork(SecretProviderFunc(onePasswordDocumentSecret)))
+	DefaultSecretRegistry.Register("kubeseal", RequiresNetwork(SecretProviderFunc(kubesealSecret)))
+	DefaultSecretRegistry.Register("vault", RequiresNetwork(NewVaultSecretProvider("", "")))
+	DefaultSecretRegistry.Register("sops", NewSOPSSecretProvider(""))
+	DefaultSecretRegistry.Register("exec", NewExecSecretProvider())
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Constructor
+// ------------------------------------------------------------------------------------------------
+
+// RequiresNetwork marks the provider as unavailable in offline mode
+func RequiresNetwork(provider SecretProvider) SecretProvider {
+	return networkSecretProvider{SecretProvider: provider}
+}
+
+func NewSecretRegistry() *SecretRegistry {
+	return &SecretRegistry{
+		providers: map[string]SecretProvider{},
+		cache:     NewSecretCache(0),
+	}
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public methods
+// ------------------------------------------------------------------------------------------------
+
+// Secret implements SecretProvider
+func (f SecretProviderFunc) Secret(ctx context.Context, args ...string) (string, error) {
+	return f(ctx, args...)
+}
+
+// Register adds or replaces the provider for the given template function name
+func (r *SecretRegistry) Register(name string, provider SecretProvider) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.providers[name] = provider
+
+	// drop cached values of the replaced provider
+	r.cache.Delete(name + "\x00")
+}
+
+// SetCache replaces the cache of resolved secrets
+func (r *SecretRegistry) SetCache(cache *SecretCache) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.cache = cache
+}
+
+// SetOffline makes providers requiring network access fail instead of resolving secrets
+func (r *SecretRegistry) SetOffline(offline bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.offline = offline
+}
+
+// Names returns the sorted template function names of all providers
+func (r *SecretRegistry) Names() []string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	ret := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		ret = append(ret, name)
+	}
+
+	sort.Strings(ret)
+
+	return ret
+}
+
+// Secret resolves the secret through the named provider, returning cached values if available
+func (r *SecretRegistry) Secret(ctx context.Context, name string, args ...string) (string, error) {
+	key := strings.Join(append([]string{name}, args...), "\x00")
+
+	r.lock.RLock()
+	offline := r.offline
+	cache := r.cache
+	provider, ok := r.providers[name]
+	r.lock.RUnlock()
+
+	if !ok {
+		return "", errors.Errorf("unknown secret provider `%s`", name)
+	}
+
+	return cache.Get(ctx, key, func(ctx context.Context) (string, error) {
+		if _, network := provider.(networkSecretProvider); network && offline {
+			return "", errors.Errorf("secret provider `%s` requires network access and is not available in offline mode", name)
+		}
+
+		value, err := provider.Secret(ctx, args...)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to resolve `%s` secret", name)
+		}
+
+		return value, nil
+	})
+}
+
+// Func returns the template function for the named provider
+func (r *SecretRegistry) Func(ctx context.Context, name string) func(args ...string) (string, error) {
+	return func(args ...string) (string, error) {
+		return r.Secret(ctx, name, args...)
+	}
+}
+
+// FuncMap returns the template functions of all providers
+func (r *SecretRegistry) FuncMap(ctx context.Context) map[string]any {
+	ret := map[string]any{}
+	for _, name := range r.Names() {
+		ret[name] = r.Func(ctx, name)
+	}
+
+	return ret
+}
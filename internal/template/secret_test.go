@@ -0,0 +1,179 @@
+package template_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+
+	testingx "github.com/foomo/go/testing"
+	tagx "github.com/foomo/go/testing/tag"
+	"github.com/foomo/squadron/internal/template"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSecretProvider is an in memory stand-in for a secret backend
+type fakeSecretProvider struct {
+	secrets map[string]string
+	calls   int
+}
+
+func (p *fakeSecretProvider) Secret(ctx context.Context, args ...string) (string, error) {
+	p.calls++
+
+	if value, ok := p.secrets[args[0]]; ok {
+		return value, nil
+	}
+
+	return "", errors.Errorf("secret `%s` not found", args[0])
+}
+
+func TestSecretRegistry(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	ctx := t.Context()
+	fake := &fakeSecretProvider{secrets: map[string]string{"foo": "bar"}}
+
+	r := template.NewSecretRegistry()
+	r.Register("fake", fake)
+
+	t.Run("cache", func(t *testing.T) {
+		for range 3 {
+			value, err := r.Secret(ctx, "fake", "foo")
+			require.NoError(t, err)
+			assert.Equal(t, "bar", value)
+		}
+
+		assert.Equal(t, 1, fake.calls)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		_, err := r.Secret(ctx, "fake", "baz")
+		require.EqualError(t, err, "failed to resolve `fake` secret: secret `baz` not found")
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		_, err := r.Secret(ctx, "unknown", "foo")
+		require.EqualError(t, err, "unknown secret provider `unknown`")
+	})
+
+	t.Run("offline", func(t *testing.T) {
+		r := template.NewSecretRegistry()
+		r.Register("local", fake)
+		r.Register("remote", template.RequiresNetwork(fake))
+		r.SetOffline(true)
+
+		value, err := r.Secret(ctx, "local", "foo")
+		require.NoError(t, err)
+		assert.Equal(t, "bar", value)
+
+		_, err = r.Secret(ctx, "remote", "foo")
+		require.EqualError(t, err, "secret provider `remote` requires network access and is not available in offline mode")
+	})
+
+	t.Run("template", func(t *testing.T) {
+		defer template.DefaultSecretRegistry.Register("vault", template.RequiresNetwork(template.NewVaultSecretProvider("", "")))
+
+		template.DefaultSecretRegistry.Register("vault", fake)
+
+		out, err := template.ExecuteFileTemplate(ctx, `password: <% vault "foo" "password" %>`, nil, true)
+		require.NoError(t, err)
+		assert.Equal(t, "password: bar", string(out))
+	})
+}
+
+func TestVaultSecretProvider(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v1/secret/data/app":
+			_, _ = w.Write([]byte(`{"data":{"data":{"password":"v2"},"metadata":{"version":1}}}`))
+		case "/v1/kv/app":
+			_, _ = w.Write([]byte(`{"data":{"password":"v1"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	p := template.NewVaultSecretProvider(server.URL, "token")
+
+	value, err := p.Secret(t.Context(), "secret/data/app", "password")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", value)
+
+	value, err = p.Secret(t.Context(), "kv/app", "password")
+	require.NoError(t, err)
+	assert.Equal(t, "v1", value)
+
+	_, err = p.Secret(t.Context(), "kv/app", "username")
+	require.EqualError(t, err, "missing field `username` in secret `kv/app`")
+
+	_, err = p.Secret(t.Context(), "kv/missing", "password")
+	require.Error(t, err)
+
+	_, err = template.NewVaultSecretProvider(server.URL, "invalid").Secret(t.Context(), "kv/app", "password")
+	require.Error(t, err)
+}
+
+func TestSOPSSecretProvider(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	// local stand-in for the sops binary
+	command := path.Join(t.TempDir(), "sops")
+	require.NoError(t, os.WriteFile(command, []byte(`#!/bin/sh
+if [ "$2" = "--output-type" ]; then
+  echo '{"database":{"password":"secret","port":5432},"hosts":["a","b"]}'
+else
+  echo 'database:'
+  echo '  password: secret'
+fi
+`), 0700)) //nolint:gosec
+
+	p := template.NewSOPSSecretProvider(command)
+
+	value, err := p.Secret(t.Context(), "secrets.enc.yaml", "database.password")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", value)
+
+	value, err = p.Secret(t.Context(), "secrets.enc.yaml", "database.port")
+	require.NoError(t, err)
+	assert.Equal(t, "5432", value)
+
+	value, err = p.Secret(t.Context(), "secrets.enc.yaml", "hosts.1")
+	require.NoError(t, err)
+	assert.Equal(t, "b", value)
+
+	value, err = p.Secret(t.Context(), "secrets.enc.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "database:\n  password: secret", value)
+
+	_, err = p.Secret(t.Context(), "secrets.enc.yaml", "database.username")
+	require.EqualError(t, err, "missing key `database.username` in `secrets.enc.yaml`")
+}
+
+func TestExecSecretProvider(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	p := template.NewExecSecretProvider()
+
+	value, err := p.Secret(t.Context(), "echo", "secret")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", value)
+
+	_, err = p.Secret(t.Context(), "false")
+	require.Error(t, err)
+
+	_, err = p.Secret(t.Context())
+	require.EqualError(t, err, "missing command")
+}
@@ -0,0 +1,210 @@
+package template
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/singleflight"
+)
+
+// SecretCache caches resolved secrets by key. Concurrent lookups of the same key are resolved only
+// once and entries expire after the TTL. Entries can be persisted to an encrypted file.
+type SecretCache struct {
+	lock    sync.RWMutex
+	group   singleflight.Group
+	ttl     time.Duration
+	entries map[string]secretCacheEntry
+	// persistence
+	filename string
+	gcm      cipher.AEAD
+}
+
+type secretCacheEntry struct {
+	Value   string    `json:"value"`
+	Expires time.Time `json:"expires,omitzero"`
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Constructor
+// ------------------------------------------------------------------------------------------------
+
+// NewSecretCache returns a cache with the given entry TTL, entries never expire if it is zero
+func NewSecretCache(ttl time.Duration) *SecretCache {
+	return &SecretCache{
+		ttl:     ttl,
+		entries: map[string]secretCacheEntry{},
+	}
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public methods
+// ------------------------------------------------------------------------------------------------
+
+// Persist loads the entries of the file encrypted with the given key and writes all resolved
+// entries to it. Requires a TTL so that secrets do not live on disk forever.
+func (c *SecretCache) Persist(filename, key string) error {
+	if key == "" {
+		return errors.New("missing secret cache key")
+	} else if c.ttl <= 0 {
+		return errors.New("persisting the secret cache requires a ttl")
+	}
+
+	sum := sha256.Sum256([]byte(key))
+
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return errors.Wrap(err, "failed to create secret cache cipher")
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return errors.Wrap(err, "failed to create secret cache cipher")
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.filename = filename
+	c.gcm = gcm
+
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "failed to read secret cache")
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return errors.New("failed to decrypt secret cache: invalid file")
+	}
+
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to decrypt secret cache, was the key changed?")
+	}
+
+	var entries map[string]secretCacheEntry
+	if err := json.Unmarshal(plain, &entries); err != nil {
+		return errors.Wrap(err, "failed to unmarshal secret cache")
+	}
+
+	now := time.Now()
+	for key, entry := range entries {
+		if _, ok := c.entries[key]; !ok && entry.Expires.After(now) {
+			c.entries[key] = entry
+		}
+	}
+
+	return nil
+}
+
+// Get returns the cached value or resolves it through the given function
+func (c *SecretCache) Get(ctx context.Context, key string, resolve func(ctx context.Context) (string, error)) (string, error) {
+	c.lock.RLock()
+	entry, ok := c.entries[key]
+	c.lock.RUnlock()
+
+	if ok && (entry.Expires.IsZero() || entry.Expires.After(time.Now())) {
+		return entry.Value, nil
+	}
+
+	value, err, _ := c.group.Do(key, func() (any, error) {
+		value, err := resolve(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		return value, c.set(key, value)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return value.(string), nil //nolint:forcetypeassert
+}
+
+// Delete removes all entries with the given key prefix
+func (c *SecretCache) Delete(prefix string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for key := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public functions
+// ------------------------------------------------------------------------------------------------
+
+// SecretCacheFilename returns the default secret cache file, configurable through `SQUADRON_CACHE_DIR`
+func SecretCacheFilename() (string, error) {
+	if value := os.Getenv("SQUADRON_CACHE_DIR"); value != "" {
+		return path.Join(value, "secrets"), nil
+	}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to determine cache dir")
+	}
+
+	return path.Join(dir, "squadron", "secrets"), nil
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private methods
+// ------------------------------------------------------------------------------------------------
+
+func (c *SecretCache) set(key, value string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entry := secretCacheEntry{Value: value}
+	if c.ttl > 0 {
+		entry.Expires = time.Now().Add(c.ttl)
+	}
+
+	c.entries[key] = entry
+
+	if c.gcm == nil {
+		return nil
+	}
+
+	return c.save()
+}
+
+// save writes the encrypted entries, the caller must hold the lock
+func (c *SecretCache) save() error {
+	plain, err := json.Marshal(c.entries)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal secret cache")
+	}
+
+	nonce := make([]byte, c.gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return errors.Wrap(err, "failed to create secret cache nonce")
+	}
+
+	if err := os.MkdirAll(path.Dir(c.filename), 0700); err != nil {
+		return errors.Wrap(err, "failed to create secret cache dir")
+	}
+
+	tmp := c.filename + ".tmp"
+	if err := os.WriteFile(tmp, c.gcm.Seal(nonce, nonce, plain, nil), 0600); err != nil {
+		return errors.Wrap(err, "failed to write secret cache")
+	}
+
+	return errors.Wrap(os.Rename(tmp, c.filename), "failed to write secret cache")
+}
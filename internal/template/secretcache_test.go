@@ -0,0 +1,189 @@
+package template_test
+
+import (
+	"context"
+	"os"
+	"path"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	testingx "github.com/foomo/go/testing"
+	tagx "github.com/foomo/go/testing/tag"
+	"github.com/foomo/squadron/internal/template"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretCache_Get(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	ctx := t.Context()
+
+	t.Run("parallel", func(t *testing.T) {
+		c := template.NewSecretCache(0)
+
+		var calls atomic.Int32
+
+		resolve := func(ctx context.Context) (string, error) {
+			calls.Add(1)
+			time.Sleep(10 * time.Millisecond)
+
+			return "bar", nil
+		}
+
+		var wg sync.WaitGroup
+		for range 50 {
+			wg.Go(func() {
+				value, err := c.Get(ctx, "foo", resolve)
+				assert.NoError(t, err)
+				assert.Equal(t, "bar", value)
+			})
+		}
+
+		wg.Wait()
+
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("ttl", func(t *testing.T) {
+		c := template.NewSecretCache(20 * time.Millisecond)
+
+		var calls int
+
+		resolve := func(ctx context.Context) (string, error) {
+			calls++
+			return "bar", nil
+		}
+
+		for range 2 {
+			_, err := c.Get(ctx, "foo", resolve)
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, 1, calls)
+
+		time.Sleep(30 * time.Millisecond)
+
+		_, err := c.Get(ctx, "foo", resolve)
+		require.NoError(t, err)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		c := template.NewSecretCache(0)
+
+		var calls int
+
+		resolve := func(ctx context.Context) (string, error) {
+			calls++
+			return "", errors.New("failed")
+		}
+
+		for range 2 {
+			_, err := c.Get(ctx, "foo", resolve)
+			require.EqualError(t, err, "failed")
+		}
+
+		// errors are not cached
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		c := template.NewSecretCache(0)
+
+		var calls int
+
+		resolve := func(ctx context.Context) (string, error) {
+			calls++
+			return "bar", nil
+		}
+
+		_, err := c.Get(ctx, "a\x00foo", resolve)
+		require.NoError(t, err)
+
+		c.Delete("a\x00")
+
+		_, err = c.Get(ctx, "a\x00foo", resolve)
+		require.NoError(t, err)
+		assert.Equal(t, 2, calls)
+	})
+}
+
+func TestSecretCache_Persist(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	ctx := t.Context()
+	filename := path.Join(t.TempDir(), "secrets")
+
+	resolve := func(ctx context.Context) (string, error) {
+		return "bar", nil
+	}
+
+	fail := func(ctx context.Context) (string, error) {
+		return "", errors.New("not cached")
+	}
+
+	c := template.NewSecretCache(time.Hour)
+	require.NoError(t, c.Persist(filename, "my-key"))
+
+	_, err := c.Get(ctx, "foo", resolve)
+	require.NoError(t, err)
+
+	t.Run("encrypted", func(t *testing.T) {
+		data, err := os.ReadFile(filename)
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "bar")
+	})
+
+	t.Run("load", func(t *testing.T) {
+		c := template.NewSecretCache(time.Hour)
+		require.NoError(t, c.Persist(filename, "my-key"))
+
+		value, err := c.Get(ctx, "foo", fail)
+		require.NoError(t, err)
+		assert.Equal(t, "bar", value)
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		c := template.NewSecretCache(time.Hour)
+		require.EqualError(t, c.Persist(filename, "other-key"), "failed to decrypt secret cache, was the key changed?: cipher: message authentication failed")
+	})
+
+	t.Run("no ttl", func(t *testing.T) {
+		c := template.NewSecretCache(0)
+		require.EqualError(t, c.Persist(filename, "my-key"), "persisting the secret cache requires a ttl")
+	})
+}
+
+func TestSecretRegistry_parallel(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	ctx := t.Context()
+
+	var calls atomic.Int32
+
+	r := template.NewSecretRegistry()
+	r.Register("fake", template.SecretProviderFunc(func(ctx context.Context, args ...string) (string, error) {
+		calls.Add(1)
+		time.Sleep(10 * time.Millisecond)
+
+		return args[0], nil
+	}))
+
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Go(func() {
+			key := []string{"a", "b"}[i%2]
+			value, err := r.Secret(ctx, "fake", key)
+			assert.NoError(t, err)
+			assert.Equal(t, key, value)
+		})
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, int32(2), calls.Load())
+}
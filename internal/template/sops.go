@@ -0,0 +1,104 @@
+package template
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// SOPSSecretProvider reads values from SOPS encrypted files
+type SOPSSecretProvider struct {
+	command string
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Constructor
+// ------------------------------------------------------------------------------------------------
+
+// NewSOPSSecretProvider returns a sops provider using the given command, defaults to `sops`
+func NewSOPSSecretProvider(command string) *SOPSSecretProvider {
+	if command == "" {
+		command = "sops"
+	}
+
+	return &SOPSSecretProvider{
+		command: command,
+	}
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public methods
+// ------------------------------------------------------------------------------------------------
+
+// Secret returns the decrypted file or the value at the dot separated key (args: file, [key])
+// e.g. `sops "secrets.enc.yaml" "database.password"`
+func (p *SOPSSecretProvider) Secret(ctx context.Context, args ...string) (string, error) {
+	if len(args) == 0 || len(args) > 2 {
+		return "", errors.Errorf("expected 1 or 2 arguments (file, [key]) but got %d", len(args))
+	}
+
+	filename := os.ExpandEnv(args[0])
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, p.command, "--decrypt") //nolint:gosec
+	if len(args) == 2 {
+		cmd.Args = append(cmd.Args, "--output-type", "json")
+	}
+
+	cmd.Args = append(cmd.Args, filename)
+	cmd.Env = os.Environ()
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", errors.Wrap(err, strings.TrimSpace(stderr.String()))
+	}
+
+	if len(args) == 1 {
+		return strings.TrimSpace(stdout.String()), nil
+	}
+
+	var data any
+	if err := json.Unmarshal(stdout.Bytes(), &data); err != nil {
+		return "", errors.Wrap(err, "failed to unmarshal decrypted file")
+	}
+
+	for _, key := range strings.Split(args[1], ".") {
+		switch v := data.(type) {
+		case map[string]any:
+			value, ok := v[key]
+			if !ok {
+				return "", errors.Errorf("missing key `%s` in `%s`", args[1], filename)
+			}
+
+			data = value
+		case []any:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(v) {
+				return "", errors.Errorf("invalid index `%s` in `%s`", key, args[1])
+			}
+
+			data = v[index]
+		default:
+			return "", errors.Errorf("missing key `%s` in `%s`", args[1], filename)
+		}
+	}
+
+	switch v := data.(type) {
+	case string:
+		return v, nil
+	case map[string]any, []any:
+		ret, err := json.Marshal(v)
+		return string(ret), err
+	default:
+		return fmt.Sprintf("%v", v), nil
+	}
+}
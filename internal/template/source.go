@@ -0,0 +1,281 @@
+package template
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// SourceError is a template error located in one of the source files
+type SourceError struct {
+	// Filename of the source file
+	Filename string
+	// Line and Column of the failing action, starting at 1
+	Line   int
+	Column int
+	// Message of the template error without its position
+	Message string
+	// Snippet of the source around the failing action
+	Snippet string
+	// Err is the original template error
+	Err error
+}
+
+var sourceErrorPosition = regexp.MustCompile(`template: [^:]+:(\d+)(?::(\d+))?: `)
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public methods
+// ------------------------------------------------------------------------------------------------
+
+func (e *SourceError) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s\n%s", e.Filename, e.Line, e.Column, e.Message, e.Snippet)
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public functions
+// ------------------------------------------------------------------------------------------------
+
+// LocateError maps the error of executing the given yaml text back to the source files it was
+// merged from. Later files win, like they do when merging. Errors without a position are returned
+// unchanged and errors that can't be mapped are located in the rendered text.
+func LocateError(err error, text string, filenames []string) error {
+	msg := err.Error()
+
+	match := sourceErrorPosition.FindStringSubmatchIndex(msg)
+	if match == nil {
+		return err
+	}
+
+	line, _ := strconv.Atoi(msg[match[2]:match[3]])
+
+	column := -1
+	if match[4] >= 0 {
+		column, _ = strconv.Atoi(msg[match[4]:match[5]])
+	}
+
+	lines := strings.Split(text, "\n")
+	if line < 1 || line > len(lines) {
+		return err
+	}
+
+	ret := &SourceError{
+		Message: strings.TrimPrefix(msg[match[1]:], `executing "squadron" `),
+		Err:     err,
+	}
+
+	action := sourceAction(lines[line-1], column)
+
+	var path []string
+
+	var doc yaml.Node
+	if yaml.Unmarshal([]byte(text), &doc) == nil {
+		path = sourcePath(&doc, line)
+	}
+
+	for _, filename := range slices.Backward(filenames) {
+		if l, c, ok := sourceLocate(filename, path, action); ok {
+			ret.Filename, ret.Line, ret.Column = filename, l, c
+			break
+		}
+	}
+
+	if ret.Filename == "" {
+		ret.Filename, ret.Line, ret.Column = "<rendered config>", line, max(column, 0)+1
+		if i := strings.Index(lines[line-1], action); action != "" && i >= 0 {
+			ret.Column = i + 1
+		}
+
+		ret.Snippet = sourceSnippet(lines, ret.Line, ret.Column)
+
+		return ret
+	}
+
+	data, _ := os.ReadFile(ret.Filename)
+	ret.Snippet = sourceSnippet(strings.Split(strings.TrimSuffix(string(data), "\n"), "\n"), ret.Line, ret.Column)
+
+	return ret
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private functions
+// ------------------------------------------------------------------------------------------------
+
+// sourceAction returns the template action at the given column or the first of the line
+func sourceAction(line string, column int) string {
+	start := strings.Index(line, "<% ")
+	if column >= 0 && column < len(line) {
+		if i := strings.LastIndex(line[:column+1], "<% "); i >= 0 {
+			start = i
+		}
+	}
+
+	if start < 0 {
+		return ""
+	}
+
+	end := strings.Index(line[start:], " %>")
+	if end < 0 {
+		return line[start:]
+	}
+
+	return line[start : start+end+3]
+}
+
+// sourcePath returns the keys of the deepest node containing the given line
+func sourcePath(node *yaml.Node, line int) []string {
+	var ret []string
+
+	for {
+		switch node.Kind {
+		case yaml.DocumentNode:
+			if len(node.Content) == 0 {
+				return ret
+			}
+
+			node = node.Content[0]
+		case yaml.MappingNode:
+			i := -1
+			for j := 0; j < len(node.Content); j += 2 {
+				if node.Content[j].Line <= line {
+					i = j
+				}
+			}
+
+			if i < 0 {
+				return ret
+			}
+
+			ret = append(ret, node.Content[i].Value)
+			node = node.Content[i+1]
+		case yaml.SequenceNode:
+			i := -1
+			for j, item := range node.Content {
+				if item.Line <= line {
+					i = j
+				}
+			}
+
+			if i < 0 {
+				return ret
+			}
+
+			ret = append(ret, strconv.Itoa(i))
+			node = node.Content[i]
+		default:
+			return ret
+		}
+	}
+}
+
+// sourceLocate returns the position of the action within the node at the given path of the file,
+// falling back to the position of the node or the first occurrence of the action in the file
+func sourceLocate(filename string, path []string, action string) (int, int, bool) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	lines := strings.Split(string(data), "\n")
+
+	find := func(start, end int) (int, int, bool) {
+		if action == "" {
+			return 0, 0, false
+		}
+
+		for i := max(start, 1); i <= min(end, len(lines)); i++ {
+			if j := strings.Index(lines[i-1], action); j >= 0 {
+				return i, j + 1, true
+			}
+		}
+
+		return 0, 0, false
+	}
+
+	var doc yaml.Node
+	if len(path) > 0 && yaml.Unmarshal(data, &doc) == nil {
+		if node, end, ok := sourceNode(&doc, path, len(lines)); ok {
+			if l, c, ok := find(node.Line, end); ok {
+				return l, c, true
+			}
+
+			return node.Line, node.Column, true
+		}
+	}
+
+	return find(1, len(lines))
+}
+
+// sourceNode returns the node at the given path and the last line it may span
+func sourceNode(node *yaml.Node, path []string, end int) (*yaml.Node, int, bool) {
+	if node.Kind == yaml.DocumentNode {
+		if len(node.Content) == 0 {
+			return nil, 0, false
+		}
+
+		node = node.Content[0]
+	}
+
+	for _, key := range path {
+		switch node.Kind {
+		case yaml.MappingNode:
+			found := false
+
+			for j := 0; j < len(node.Content); j += 2 {
+				if node.Content[j].Value != key {
+					continue
+				}
+
+				if j+2 < len(node.Content) {
+					end = node.Content[j+2].Line - 1
+				}
+
+				node = node.Content[j+1]
+				found = true
+
+				break
+			}
+
+			if !found {
+				return nil, 0, false
+			}
+		case yaml.SequenceNode:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node.Content) {
+				return nil, 0, false
+			}
+
+			if i+1 < len(node.Content) {
+				end = node.Content[i+1].Line - 1
+			}
+
+			node = node.Content[i]
+		default:
+			return nil, 0, false
+		}
+	}
+
+	return node, end, true
+}
+
+// sourceSnippet returns the lines around the given position with a marker below the column
+func sourceSnippet(lines []string, line, column int) string {
+	var ret strings.Builder
+
+	first, last := max(line-2, 1), min(line+2, len(lines))
+	width := len(strconv.Itoa(last))
+
+	for i := first; i <= last; i++ {
+		ret.WriteString(strings.TrimRight(fmt.Sprintf(" %*d | %s", width, i, lines[i-1]), " ") + "\n")
+
+		if i == line {
+			fmt.Fprintf(&ret, " %*s | %s^\n", width, "", strings.Repeat(" ", max(column-1, 0)))
+		}
+	}
+
+	return strings.TrimSuffix(ret.String(), "\n")
+}
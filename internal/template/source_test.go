@@ -0,0 +1,72 @@
+package template_test
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	testingx "github.com/foomo/go/testing"
+	tagx "github.com/foomo/go/testing/tag"
+	"github.com/foomo/squadron/internal/template"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocateError(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	ctx := t.Context()
+	dir := t.TempDir()
+
+	filename := path.Join(dir, "squadron.yaml")
+	require.NoError(t, os.WriteFile(filename, []byte(strings.Join([]string{
+		"squadron:",
+		"  site:",
+		"    frontend:",
+		"      values:",
+		"        hosts:",
+		"          - example.com",
+		"          - '<% env \"HOST\" | foo %>'",
+	}, "\n")), 0600))
+
+	// the rendered config is formatted differently than the source
+	text := strings.Join([]string{
+		"squadron:",
+		"  site:",
+		"    frontend:",
+		"      values:",
+		"        hosts:",
+		"        - example.com",
+		"        - <% env \"HOST\" | foo %>",
+	}, "\n")
+
+	t.Run("source", func(t *testing.T) {
+		_, err := template.ExecuteFileTemplate(ctx, text, nil, true)
+		require.Error(t, err)
+
+		var sourceErr *template.SourceError
+		require.ErrorAs(t, template.LocateError(err, text, []string{filename}), &sourceErr)
+		assert.Equal(t, filename, sourceErr.Filename)
+		assert.Equal(t, 7, sourceErr.Line)
+		assert.Equal(t, 14, sourceErr.Column)
+		assert.Equal(t, `function "foo" not defined`, sourceErr.Message)
+	})
+
+	t.Run("rendered", func(t *testing.T) {
+		_, err := template.ExecuteFileTemplate(ctx, text, nil, true)
+		require.Error(t, err)
+
+		var sourceErr *template.SourceError
+		require.ErrorAs(t, template.LocateError(err, text, nil), &sourceErr)
+		assert.Equal(t, "<rendered config>", sourceErr.Filename)
+		assert.Equal(t, 7, sourceErr.Line)
+		assert.Equal(t, 11, sourceErr.Column)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		err := errors.New("failed")
+		assert.Equal(t, err, template.LocateError(err, text, []string{filename}))
+	})
+}
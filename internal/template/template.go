@@ -3,12 +3,13 @@ package template
 import (
 	"bytes"
 	"context"
+	"maps"
 	"text/template"
 
 	"github.com/Masterminds/sprig/v3"
 )
 
-func ExecuteFileTemplate(ctx context.Context, text string, templateVars any, errorOnMissing bool) ([]byte, error) {
+func ExecuteFileTemplate(ctx context.Context, text string, templateVars any, errorOnMissing bool, funcMaps ...template.FuncMap) ([]byte, error) {
 	funcMap := sprig.TxtFuncMap()
 	delete(funcMap, "env")
 	delete(funcMap, "expandenv")
@@ -23,11 +24,13 @@ func ExecuteFileTemplate(ctx context.Context, text string, templateVars any, err
 	funcMap["base64"] = base64
 	funcMap["defaultIndex"] = defaultIndexValue
 
+	// secret providers
+	maps.Copy(funcMap, DefaultSecretRegistry.FuncMap(ctx))
+
 	funcMap["op"] = onePassword(ctx, templateVars, errorOnMissing)
 	funcMap["git"] = git(ctx)
 	funcMap["opDoc"] = onePasswordDocument(ctx, templateVars, errorOnMissing)
 	funcMap["file"] = file(ctx, templateVars, errorOnMissing)
-	funcMap["kubeseal"] = kubeseal(ctx)
 
 	funcMap["toToml"] = toTOML
 	funcMap["fromToml"] = fromTOML
@@ -39,6 +42,11 @@ func ExecuteFileTemplate(ctx context.Context, text string, templateVars any, err
 	funcMap["fromJson"] = fromJSON
 	funcMap["fromJsonArray"] = fromJSONArray
 
+	// additional functions, e.g. unit references
+	for _, m := range funcMaps {
+		maps.Copy(funcMap, m)
+	}
+
 	tpl, err := template.New("squadron").Delims("<% ", " %>").Funcs(funcMap).Parse(text)
 	if err != nil {
 		return nil, err
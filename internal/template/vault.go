@@ -0,0 +1,134 @@
+package template
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// VaultSecretProvider reads fields from HashiCorp Vault KV (v1 & v2) secrets
+type VaultSecretProvider struct {
+	address string
+	token   string
+	client  *http.Client
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Constructor
+// ------------------------------------------------------------------------------------------------
+
+// NewVaultSecretProvider returns a vault provider, falling back to `VAULT_ADDR`, `VAULT_TOKEN` and `~/.vault-token`
+func NewVaultSecretProvider(address, token string) *VaultSecretProvider {
+	return &VaultSecretProvider{
+		address: address,
+		token:   token,
+		client:  http.DefaultClient,
+	}
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public methods
+// ------------------------------------------------------------------------------------------------
+
+// Secret returns the field of the secret (args: path, field) e.g. `vault "secret/data/my-app" "password"`
+func (p *VaultSecretProvider) Secret(ctx context.Context, args ...string) (string, error) {
+	if len(args) != 2 {
+		return "", errors.Errorf("expected 2 arguments (path, field) but got %d", len(args))
+	}
+
+	secretPath, field := args[0], args[1]
+
+	address, token, err := p.credentials()
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(address, "/")+path.Join("/v1", secretPath), nil)
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("X-Vault-Token", token)
+
+	if namespace := os.Getenv("VAULT_NAMESPACE"); namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to request secret")
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read secret")
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("unexpected status %d for secret `%s`: %s", resp.StatusCode, secretPath, strings.TrimSpace(string(body)))
+	}
+
+	var res struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return "", errors.Wrap(err, "failed to unmarshal secret")
+	}
+
+	data := res.Data
+	// kv v2 nests the secret data
+	if value, ok := data["data"].(map[string]any); ok {
+		if _, ok := data["metadata"]; ok {
+			data = value
+		}
+	}
+
+	value, ok := data[field]
+	if !ok {
+		return "", errors.Errorf("missing field `%s` in secret `%s`", field, secretPath)
+	}
+
+	return fmt.Sprintf("%v", value), nil
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private methods
+// ------------------------------------------------------------------------------------------------
+
+func (p *VaultSecretProvider) credentials() (string, string, error) {
+	address, token := p.address, p.token
+
+	if address == "" {
+		address = os.Getenv("VAULT_ADDR")
+	}
+
+	if address == "" {
+		return "", "", errors.New("missing vault address, please set `VAULT_ADDR`")
+	}
+
+	if token == "" {
+		token = os.Getenv("VAULT_TOKEN")
+	}
+
+	if token == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			if value, err := os.ReadFile(path.Join(home, ".vault-token")); err == nil {
+				token = strings.TrimSpace(string(value))
+			}
+		}
+	}
+
+	if token == "" {
+		return "", "", errors.New("missing vault token, please set `VAULT_TOKEN` or run `vault login`")
+	}
+
+	return address, token, nil
+}
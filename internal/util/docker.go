@@ -24,3 +24,11 @@ func (c *DockerCmd) Build(workDir string) *Cmd {
 func (c *DockerCmd) Push(image string) *Cmd {
 	return c.Args("push", image)
 }
+
+func (c *DockerCmd) ImageLabel(image, label string) *Cmd {
+	return c.Args("image", "inspect", "--format", `{{ index .Config.Labels "`+label+`" }}`, image)
+}
+
+func (c *DockerCmd) ImageRepoDigests(image string) *Cmd {
+	return c.Args("image", "inspect", "--format", `{{ join .RepoDigests "\n" }}`, image)
+}
@@ -93,6 +93,41 @@ func HighlightHCL(source string) string {
 	return out.w.String()
 }
 
+func HighlightDiff(source string) string {
+	var out bytes.Buffer
+
+	// Determine lexer.
+	l := lexers.Get("diff")
+	if l == nil {
+		l = lexers.Fallback
+	}
+
+	l = chroma.Coalesce(l)
+
+	// Determine formatter.
+	f := formatters.Get("terminal256")
+	if f == nil {
+		f = formatters.Fallback
+	}
+
+	// Determine style.
+	s := styles.Get("monokai")
+	if s == nil {
+		s = styles.Fallback
+	}
+
+	it, err := l.Tokenise(nil, source)
+	if err != nil {
+		pterm.Error.Println(err.Error())
+	}
+
+	if err = f.Format(&out, s, it); err != nil {
+		pterm.Error.Println(err.Error())
+	}
+
+	return out.String()
+}
+
 type numberWriter struct {
 	w           *bytes.Buffer
 	currentLine uint64
@@ -19,13 +19,24 @@ func NewKubeCommand() *KubeCmd {
 	return &KubeCmd{*NewCommand("kubectl")}
 }
 
+// Namespace sets the namespace for the command
+func (c *KubeCmd) Namespace(namespace string) *KubeCmd {
+	c.Arg("--namespace", namespace)
+	return c
+}
+
 func (c KubeCmd) RollbackDeployment(deployment string) *Cmd {
 	return c.Args("rollout", "undo", fmt.Sprintf("deployment/%v", deployment))
 }
 
-func (c KubeCmd) WaitForRollout(deployment, timeout string) *Cmd {
-	return c.Args("rollout", "status", fmt.Sprintf("deployment/%v", deployment),
-		"-w", "--timeout", timeout)
+// WaitForRollout waits for the rollout of the given resource (format: kind/name), plain names
+// refer to deployments
+func (c KubeCmd) WaitForRollout(resource, timeout string) *Cmd {
+	if !strings.Contains(resource, "/") {
+		resource = fmt.Sprintf("deployment/%v", resource)
+	}
+
+	return c.Args("rollout", "status", resource, "-w", "--timeout", timeout)
 }
 
 func (c KubeCmd) GetMostRecentPodBySelectors(ctx context.Context, selectors map[string]string) (string, error) {
@@ -0,0 +1,272 @@
+package squadron
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sync"
+
+	"github.com/foomo/squadron/config"
+	"github.com/foomo/squadron/internal/helm"
+	"github.com/foomo/squadron/internal/lockfile"
+	ptermx "github.com/foomo/squadron/internal/pterm"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public methods
+// ------------------------------------------------------------------------------------------------
+
+// LockfilePath returns the path to the `squadron.lock` file
+func (sq *Squadron) LockfilePath() string {
+	return path.Join(sq.basePath, lockfile.Filename)
+}
+
+// LockCharts resolves the remote charts of all units, stores them in the chart cache and records
+// their versions and digests in the lockfile. Charts of other units are removed if prune is set.
+func (sq *Squadron) LockCharts(ctx context.Context, prune bool, parallel int) error {
+	if sq.offline {
+		return errors.New("locking charts requires network access and is not available in offline mode")
+	}
+
+	lock, err := lockfile.Load(sq.LockfilePath())
+	if err != nil {
+		return err
+	}
+
+	cacheDir, err := lockfile.CacheDir()
+	if err != nil {
+		return err
+	}
+
+	charts, err := sq.remoteCharts(ctx)
+	if err != nil {
+		return err
+	}
+
+	var m sync.Mutex
+
+	wg, ctx := errgroup.WithContext(ctx)
+	wg.SetLimit(parallel)
+
+	printer := sq.newProgress(OperationLock)
+	defer printer.Stop()
+
+	keys := make([]string, 0, len(charts))
+	for key, chart := range charts {
+		keys = append(keys, key)
+
+		wg.Go(func() error {
+			spinner := printer.NewTask("", "", key, fmt.Sprintf("🔒 | %s", key))
+			spinner.Start()
+			spinner.Play()
+
+			ctx := ptermx.ContextWithSpinner(ctx, spinner)
+			if err := ctx.Err(); err != nil {
+				spinner.Warning(err.Error())
+				return err
+			}
+
+			archive, err := helm.NewSDKBackend().Pull(ctx, helm.Release{
+				Chart:      chart.Name,
+				Repository: chart.Repository,
+				Version:    chart.Version,
+			})
+			if err != nil {
+				spinner.Fail(err.Error())
+				return err
+			}
+
+			chart.Resolved = archive.Version
+			chart.Digest = archive.Digest
+
+			if _, err := chart.Store(cacheDir, archive); err != nil {
+				spinner.Fail(err.Error())
+				return err
+			}
+
+			m.Lock()
+			lock.Set(chart)
+			m.Unlock()
+
+			spinner.Success(fmt.Sprintf("🔒 | %s ➜ %s", key, chart.Resolved))
+
+			return nil
+		})
+	}
+
+	if err := wg.Wait(); err != nil {
+		return err
+	}
+
+	if prune {
+		lock.Retain(keys...)
+	}
+
+	return lock.Save(sq.LockfilePath())
+}
+
+// FetchCharts populates the chart cache with all charts recorded in the lockfile
+func (sq *Squadron) FetchCharts(ctx context.Context, parallel int) error {
+	if sq.offline {
+		return errors.New("fetching charts requires network access and is not available in offline mode")
+	}
+
+	lock, err := lockfile.Load(sq.LockfilePath())
+	if err != nil {
+		return err
+	}
+
+	if len(lock.Charts) == 0 {
+		sq.warn(OperationFetch, fmt.Sprintf("No charts locked in %s, please run `squadron lock` first", sq.LockfilePath()))
+		return nil
+	}
+
+	cacheDir, err := lockfile.CacheDir()
+	if err != nil {
+		return err
+	}
+
+	wg, ctx := errgroup.WithContext(ctx)
+	wg.SetLimit(parallel)
+
+	printer := sq.newProgress(OperationFetch)
+	defer printer.Stop()
+
+	for _, chart := range lock.Charts {
+		wg.Go(func() error {
+			spinner := printer.NewTask("", "", chart.Key(), fmt.Sprintf("📥 | %s ➜ %s", chart.Key(), chart.Resolved))
+			spinner.Start()
+			spinner.Play()
+
+			ctx := ptermx.ContextWithSpinner(ctx, spinner)
+			if err := ctx.Err(); err != nil {
+				spinner.Warning(err.Error())
+				return err
+			}
+
+			if _, err := chart.Cached(cacheDir); err == nil {
+				spinner.Success()
+				return nil
+			}
+
+			archive, err := helm.NewSDKBackend().Pull(ctx, helm.Release{
+				Chart:      chart.Name,
+				Repository: chart.Repository,
+				Version:    chart.Resolved,
+			})
+			if err != nil {
+				spinner.Fail(err.Error())
+				return err
+			}
+
+			if _, err := chart.Store(cacheDir, archive); err != nil {
+				spinner.Fail(err.Error())
+				return err
+			}
+
+			spinner.Success()
+
+			return nil
+		})
+	}
+
+	return wg.Wait()
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private methods
+// ------------------------------------------------------------------------------------------------
+
+// release returns the helm release of the unit with its chart resolved through the lockfile
+func (sq *Squadron) release(key, k string, v *config.Unit, name, namespace string) (helm.Release, error) {
+	ret, err := unitRelease(v, name, key, k, namespace, sq.c.Global)
+	if err != nil {
+		return ret, err
+	}
+
+	ret.Offline = sq.offline
+
+	if !ret.Remote() {
+		return ret, nil
+	}
+
+	lock, err := lockfile.Load(sq.LockfilePath())
+	if err != nil {
+		return ret, err
+	}
+
+	chart, ok := lock.Get(ret.Chart, ret.Repository, ret.Version)
+	if !ok {
+		if sq.offline {
+			return ret, errors.Errorf("chart `%s` is not locked, please run `squadron lock`", lockfile.Chart{Name: ret.Chart, Repository: ret.Repository, Version: ret.Version}.Key())
+		}
+
+		return ret, nil
+	}
+
+	cacheDir, err := lockfile.CacheDir()
+	if err != nil {
+		return ret, err
+	}
+
+	if filename, err := chart.Cached(cacheDir); err == nil {
+		ret.Chart = filename
+		ret.Repository = ""
+		ret.Version = ""
+	} else if sq.offline {
+		return ret, errors.Wrapf(err, "chart `%s` is not cached, please run `squadron fetch`", chart.Key())
+	} else {
+		ret.Version = chart.Resolved
+	}
+
+	return ret, nil
+}
+
+// remoteCharts returns the unique remote charts of all units by key
+func (sq *Squadron) remoteCharts(ctx context.Context) (map[string]lockfile.Chart, error) {
+	ret := map[string]lockfile.Chart{}
+
+	err := sq.c.Squadrons.Iterate(ctx, func(ctx context.Context, key string, value config.Map[*config.Unit]) error {
+		return value.Iterate(ctx, func(ctx context.Context, k string, v *config.Unit) error {
+			r := helm.NewRelease("", "", v.Chart.Name, v.Chart.Repository, v.Chart.Version)
+			if !r.Remote() {
+				return nil
+			}
+
+			chart := lockfile.Chart{
+				Name:       r.Chart,
+				Repository: r.Repository,
+				Version:    r.Version,
+			}
+			ret[chart.Key()] = chart
+
+			return nil
+		})
+	})
+
+	return ret, err
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private functions
+// ------------------------------------------------------------------------------------------------
+
+// unitRelease returns the helm release for the unit
+func unitRelease(u *config.Unit, name, squadron, unit, namespace string, global map[string]any) (helm.Release, error) {
+	valueBytes, err := u.ValuesYAML(global)
+	if err != nil {
+		return helm.Release{}, err
+	}
+
+	ret := helm.NewRelease(name, namespace, u.Chart.Name, u.Chart.Repository, u.Chart.Version)
+	ret.Values = valueBytes
+	ret.Kustomize = u.Kustomize
+	ret.Set = []string{
+		"global.foomo.squadron.name=" + squadron,
+		"global.foomo.squadron.unit=" + unit,
+	}
+
+	return ret, nil
+}
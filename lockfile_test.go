@@ -0,0 +1,57 @@
+package squadron_test
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	testingx "github.com/foomo/go/testing"
+	tagx "github.com/foomo/go/testing/tag"
+	"github.com/foomo/squadron"
+	"github.com/foomo/squadron/internal/lockfile"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSquadron_Offline(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+	t.Setenv("SQUADRON_CACHE_DIR", t.TempDir())
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(path.Join(dir, "squadron.yaml"), []byte(`version: '2.3'
+squadron:
+  storefinder:
+    frontend:
+      chart:
+        name: frontend
+        repository: https://charts.example.com
+        version: 1.0.0
+`), 0600))
+
+	sq := squadron.New(dir, "default", []string{path.Join(dir, "squadron.yaml")}, squadron.WithOffline(true))
+	require.NoError(t, sq.MergeConfigFiles(t.Context()))
+
+	t.Run("not locked", func(t *testing.T) {
+		_, err := sq.Template(t.Context(), squadron.HelmOptions{}, 1)
+		require.ErrorContains(t, err, "chart `https://charts.example.com/frontend@1.0.0` is not locked, please run `squadron lock`")
+	})
+
+	t.Run("not cached", func(t *testing.T) {
+		lock := &lockfile.Lockfile{Version: lockfile.Version}
+		lock.Set(lockfile.Chart{
+			Name:       "frontend",
+			Repository: "https://charts.example.com",
+			Version:    "1.0.0",
+			Resolved:   "1.0.0",
+			Digest:     "sha256:0123456789abcdef",
+		})
+		require.NoError(t, lock.Save(sq.LockfilePath()))
+
+		_, err := sq.Template(t.Context(), squadron.HelmOptions{}, 1)
+		require.ErrorContains(t, err, "chart `https://charts.example.com/frontend@1.0.0` is not cached, please run `squadron fetch`")
+	})
+
+	t.Run("no network", func(t *testing.T) {
+		require.ErrorContains(t, sq.LockCharts(t.Context(), true, 1), "not available in offline mode")
+		require.ErrorContains(t, sq.FetchCharts(t.Context(), 1), "not available in offline mode")
+	})
+}
@@ -0,0 +1,79 @@
+package squadron
+
+import (
+	"time"
+)
+
+// Operation performed by squadron
+type Operation string
+
+const (
+	OperationMerge    Operation = "merge"
+	OperationRender   Operation = "render"
+	OperationBakefile Operation = "bakefile"
+	OperationBake     Operation = "bake"
+	OperationBuild    Operation = "build"
+	OperationPush     Operation = "push"
+	OperationUp       Operation = "up"
+	OperationDown     Operation = "down"
+	OperationDiff     Operation = "diff"
+	OperationStatus   Operation = "status"
+	OperationRollback Operation = "rollback"
+	OperationVerify   Operation = "verify"
+	OperationTemplate Operation = "template"
+	OperationValidate Operation = "validate"
+	OperationLock     Operation = "lock"
+	OperationFetch    Operation = "fetch"
+)
+
+// EventType of an observed event
+type EventType string
+
+const (
+	// EventStarted is emitted when a task starts running
+	EventStarted EventType = "started"
+	// EventOutput is emitted for every line of command output of a running task
+	EventOutput EventType = "output"
+	// EventSucceeded is emitted when a task completed
+	EventSucceeded EventType = "succeeded"
+	// EventWarned is emitted when a task was skipped or canceled, or for a warning of an operation
+	EventWarned EventType = "warned"
+	// EventFailed is emitted when a task failed
+	EventFailed EventType = "failed"
+)
+
+// Event reports the progress of an operation
+type Event struct {
+	// Event type
+	Type EventType `json:"type" yaml:"type"`
+	// Operation the event belongs to
+	Operation Operation `json:"operation" yaml:"operation"`
+	// Squadron name, empty for operations on the whole config
+	Squadron string `json:"squadron,omitempty" yaml:"squadron,omitempty"`
+	// Unit name, empty for operations on the whole config
+	Unit string `json:"unit,omitempty" yaml:"unit,omitempty"`
+	// Target within the unit, e.g. the build name or hook
+	Target string `json:"target,omitempty" yaml:"target,omitempty"`
+	// Human readable title of the task
+	Title string `json:"title" yaml:"title"`
+	// Output line, warning or error message
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+	// Time since the task started, set on completion
+	Duration time.Duration `json:"duration,omitempty" yaml:"duration,omitempty"`
+}
+
+// Observer receives the events of all operations, it must be safe for concurrent use
+type Observer interface {
+	Observe(event Event)
+}
+
+// ObserverFunc adapts a function to an Observer
+type ObserverFunc func(event Event)
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public methods
+// ------------------------------------------------------------------------------------------------
+
+func (f ObserverFunc) Observe(event Event) {
+	f(event)
+}
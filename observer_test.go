@@ -0,0 +1,88 @@
+package squadron_test
+
+import (
+	"os"
+	"path"
+	"sync"
+	"testing"
+
+	testingx "github.com/foomo/go/testing"
+	tagx "github.com/foomo/go/testing/tag"
+	"github.com/foomo/squadron"
+	"github.com/foomo/squadron/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSquadron_WithObserver(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+	t.Setenv("PROJECT_ROOT", ".")
+
+	bin := t.TempDir()
+	require.NoError(t, os.WriteFile(path.Join(bin, "helm"), []byte("#!/bin/sh\necho \"release \\\"$2\\\" uninstalled\"\n"), 0700)) //nolint:gosec
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("SQUADRON_HELM_BACKEND", "cli")
+
+	var (
+		cwd    string
+		m      sync.Mutex
+		events []squadron.Event
+	)
+
+	ctx := t.Context()
+	require.NoError(t, util.ValidatePath(".", &cwd))
+
+	sq := squadron.New(cwd, "demo", []string{path.Join("testdata", "simple", "squadron.yaml")},
+		squadron.WithObserver(squadron.ObserverFunc(func(event squadron.Event) {
+			m.Lock()
+			defer m.Unlock()
+
+			events = append(events, event)
+		})),
+	)
+	require.NoError(t, sq.MergeConfigFiles(ctx))
+	require.NoError(t, sq.RenderConfig(ctx))
+
+	results, err := sq.Down(ctx, squadron.HelmOptions{}, 1)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, squadron.OperationDown, results[0].Operation)
+	assert.Equal(t, "storefinder", results[0].Squadron)
+	assert.Equal(t, "frontend", results[0].Unit)
+	assert.Equal(t, squadron.ResultSucceeded, results[0].Status)
+
+	var types []string
+
+	for _, event := range events {
+		types = append(types, string(event.Operation)+":"+string(event.Type))
+	}
+
+	assert.Equal(t, []string{
+		"merge:started", "merge:succeeded",
+		"render:started", "render:succeeded",
+		"down:started", "down:output", "down:succeeded",
+	}, types)
+	assert.Equal(t, "storefinder", events[4].Squadron)
+	assert.Equal(t, "frontend", events[4].Unit)
+	assert.Equal(t, `release "storefinder-frontend" uninstalled`, events[5].Message)
+}
+
+func TestSquadron_WithObserver_failed(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+
+	filename := path.Join(t.TempDir(), "squadron.yaml")
+	require.NoError(t, os.WriteFile(filename, []byte("version: '2.2'\n"), 0600))
+
+	var events []squadron.Event
+
+	sq := squadron.New(t.TempDir(), "demo", []string{filename},
+		squadron.WithObserver(squadron.ObserverFunc(func(event squadron.Event) {
+			events = append(events, event)
+		})),
+	)
+	require.Error(t, sq.MergeConfigFiles(t.Context()))
+	require.Len(t, events, 2)
+	assert.Equal(t, squadron.EventFailed, events[1].Type)
+	assert.Equal(t, squadron.OperationMerge, events[1].Operation)
+	assert.Contains(t, events[1].Message, "version: \"2.2\"")
+}
@@ -0,0 +1,47 @@
+package squadron
+
+// Option configures a squadron
+type Option func(sq *Squadron)
+
+// ------------------------------------------------------------------------------------------------
+// ~ Options
+// ------------------------------------------------------------------------------------------------
+
+// WithEnv selects the environment to apply when merging the config files
+func WithEnv(name string) Option {
+	return func(sq *Squadron) {
+		sq.env = name
+	}
+}
+
+// WithOffline resolves remote charts from the chart cache only and fails if they are not locked or cached
+func WithOffline(offline bool) Option {
+	return func(sq *Squadron) {
+		sq.offline = offline
+	}
+}
+
+// WithObserver reports the progress of all operations to the observer instead of printing it
+func WithObserver(observer Observer) Option {
+	return func(sq *Squadron) {
+		sq.observer = observer
+	}
+}
+
+// WithDigests exposes the given image digests by tag to the templates as `.Builds.<name>.Digest`,
+// e.g. from a previous build
+func WithDigests(digests map[string]string) Option {
+	return func(sq *Squadron) {
+		for tag, digest := range digests {
+			sq.setDigest(digest, tag)
+		}
+	}
+}
+
+// WithBakeBuilds converts the unit builds and their global build dependencies into targets of the
+// generated bakefile
+func WithBakeBuilds(enabled bool) Option {
+	return func(sq *Squadron) {
+		sq.bakeBuilds = enabled
+	}
+}
@@ -0,0 +1,279 @@
+package squadron
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	ptermx "github.com/foomo/squadron/internal/pterm"
+	"github.com/pterm/pterm"
+)
+
+// ResultStatus of a task
+type ResultStatus string
+
+const (
+	ResultSucceeded ResultStatus = "succeeded"
+	ResultCanceled  ResultStatus = "canceled"
+	ResultFailed    ResultStatus = "failed"
+)
+
+// Result of a task of an operation
+type Result struct {
+	// Operation of the task
+	Operation Operation `json:"operation" yaml:"operation"`
+	// Squadron name
+	Squadron string `json:"squadron,omitempty" yaml:"squadron,omitempty"`
+	// Unit name
+	Unit string `json:"unit,omitempty" yaml:"unit,omitempty"`
+	// Target within the unit, e.g. the build name and tag
+	Target string `json:"target,omitempty" yaml:"target,omitempty"`
+	// Task status
+	Status ResultStatus `json:"status" yaml:"status"`
+	// Error message of canceled or failed tasks
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+	// Task duration
+	Duration time.Duration `json:"duration" yaml:"duration"`
+}
+
+// progress tracks the tasks of an operation, printing them as spinners unless an observer is set
+type progress struct {
+	operation Operation
+	observer  Observer
+	printer   ptermx.MultiPrinter
+	m         sync.Mutex
+	results   []Result
+}
+
+// task is a spinner reporting to the progress
+type task struct {
+	progress *progress
+	spinner  ptermx.Spinner
+	event    Event
+	start    time.Time
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private methods
+// ------------------------------------------------------------------------------------------------
+
+func (sq *Squadron) newProgress(operation Operation) *progress {
+	ret := &progress{
+		operation: operation,
+		observer:  sq.observer,
+	}
+
+	if sq.observer == nil {
+		ret.printer = ptermx.MustNewMultiPrinter()
+	}
+
+	return ret
+}
+
+// step reports a step on the whole config and returns a function to report its completion
+func (sq *Squadron) step(operation Operation, title string) func() {
+	start := time.Now()
+
+	if sq.observer == nil {
+		pterm.Info.Println(title)
+
+		return func() {
+			pterm.Success.Println(title + " ⏱ " + time.Since(start).Round(time.Millisecond).String())
+		}
+	}
+
+	sq.observer.Observe(Event{Type: EventStarted, Operation: operation, Title: title})
+
+	return func() {
+		sq.observer.Observe(Event{Type: EventSucceeded, Operation: operation, Title: title, Duration: time.Since(start)})
+	}
+}
+
+// warn reports a warning of the operation
+func (sq *Squadron) warn(operation Operation, message string) {
+	if sq.observer == nil {
+		pterm.Warning.Println(message)
+		return
+	}
+
+	sq.observer.Observe(Event{Type: EventWarned, Operation: operation, Title: message, Message: message})
+}
+
+// fail reports the details of a failed operation, e.g. the content that could not be parsed,
+// highlighted by the given function when printing
+func (sq *Squadron) fail(operation Operation, title, details string, highlight func(string) string) {
+	if sq.observer == nil {
+		pterm.Error.Println(title)
+		pterm.Println(highlight(details))
+
+		return
+	}
+
+	sq.observer.Observe(Event{Type: EventFailed, Operation: operation, Title: title, Message: details})
+}
+
+// info reports an informational message of the operation
+func (sq *Squadron) info(operation Operation, message string) {
+	if sq.observer == nil {
+		pterm.Info.Println(message)
+		return
+	}
+
+	sq.observer.Observe(Event{Type: EventOutput, Operation: operation, Title: message, Message: message})
+}
+
+// NewTask returns a spinner for the task of the given unit and target
+func (p *progress) NewTask(squadron, unit, target, title string) ptermx.Spinner {
+	return p.newTask(p.operation, squadron, unit, target, title)
+}
+
+// newTask returns a spinner for a task of another operation, e.g. verifications while installing
+func (p *progress) newTask(operation Operation, squadron, unit, target, title string) ptermx.Spinner {
+	ret := &task{
+		progress: p,
+		event: Event{
+			Operation: operation,
+			Squadron:  squadron,
+			Unit:      unit,
+			Target:    target,
+			Title:     title,
+		},
+	}
+
+	if p.printer != nil {
+		ret.spinner = p.printer.NewSpinner(title)
+	}
+
+	return ret
+}
+
+// Results returns the results of all completed tasks
+func (p *progress) Results() []Result {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	ret := slices.Clone(p.results)
+	slices.SortStableFunc(ret, func(a, b Result) int {
+		return strings.Compare(a.Squadron+"/"+a.Unit+"/"+a.Target, b.Squadron+"/"+b.Unit+"/"+b.Target)
+	})
+
+	return ret
+}
+
+func (p *progress) Stop() {
+	if p.printer != nil {
+		p.printer.Stop()
+	}
+}
+
+func (p *progress) observe(event Event) {
+	if p.observer != nil {
+		p.observer.Observe(event)
+	}
+}
+
+func (p *progress) complete(t *task, status ResultStatus, message string) {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	p.results = append(p.results, Result{
+		Operation: t.event.Operation,
+		Squadron:  t.event.Squadron,
+		Unit:      t.event.Unit,
+		Target:    t.event.Target,
+		Status:    status,
+		Error:     message,
+		Duration:  t.duration(),
+	})
+}
+
+func (t *task) Start(message ...string) {
+	if t.spinner != nil {
+		t.spinner.Start(message...)
+	}
+}
+
+func (t *task) Play() {
+	t.start = time.Now()
+
+	if t.spinner != nil {
+		t.spinner.Play()
+	}
+
+	t.progress.observe(t.with(EventStarted, ""))
+}
+
+func (t *task) Info(message ...string) {
+	if t.spinner != nil {
+		t.spinner.Info(message...)
+	}
+
+	t.progress.observe(t.with(EventSucceeded, strings.Join(message, " ")))
+	t.progress.complete(t, ResultSucceeded, "")
+}
+
+func (t *task) Warning(message ...string) {
+	if t.spinner != nil {
+		t.spinner.Warning(message...)
+	}
+
+	t.progress.observe(t.with(EventWarned, strings.Join(message, " ")))
+	t.progress.complete(t, ResultCanceled, strings.Join(message, " "))
+}
+
+func (t *task) Fail(message ...string) {
+	if t.spinner != nil {
+		t.spinner.Fail(message...)
+	}
+
+	t.progress.observe(t.with(EventFailed, strings.Join(message, " ")))
+	t.progress.complete(t, ResultFailed, strings.Join(message, " "))
+}
+
+func (t *task) Success(message ...string) {
+	if t.spinner != nil {
+		t.spinner.Success(message...)
+	}
+
+	t.progress.observe(t.with(EventSucceeded, strings.Join(message, " ")))
+	t.progress.complete(t, ResultSucceeded, "")
+}
+
+func (t *task) Write(p []byte) (int, error) {
+	if t.spinner != nil {
+		if _, err := t.spinner.Write(p); err != nil {
+			return 0, err
+		}
+	}
+
+	if t.progress.observer != nil {
+		for line := range strings.SplitSeq(string(p), "\n") {
+			if line := strings.TrimSpace(line); len(line) > 0 {
+				t.progress.observe(t.with(EventOutput, line))
+			}
+		}
+	}
+
+	return len(p), nil
+}
+
+func (t *task) with(eventType EventType, message string) Event {
+	ret := t.event
+	ret.Type = eventType
+	ret.Message = message
+
+	if eventType != EventStarted && eventType != EventOutput {
+		ret.Duration = t.duration()
+	}
+
+	return ret
+}
+
+func (t *task) duration() time.Duration {
+	if t.start.IsZero() {
+		return 0
+	}
+
+	return time.Since(t.start)
+}
@@ -0,0 +1,328 @@
+package squadron
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/foomo/squadron/config"
+	"github.com/foomo/squadron/dag"
+	templatex "github.com/foomo/squadron/internal/template"
+	"github.com/pkg/errors"
+	yamlv2 "gopkg.in/yaml.v2"
+	"gopkg.in/yaml.v3"
+)
+
+// resolver renders the vars, globals and referenced squadron values of the config in dependency
+// order so that references between them resolve at any depth
+type resolver struct {
+	sq   *Squadron
+	ctx  context.Context //nolint:containedctx
+	data map[string]any
+	// nodes by id, i.e. the joined path
+	nodes map[string][]string
+	graph *dag.Graph
+	// references of all templated strings
+	refs []resolverRef
+}
+
+// resolverRef holds the nodes referenced by a templated string of the config
+type resolverRef struct {
+	path    []string
+	targets []string
+}
+
+// renderedUnit wraps a unit at its position in the config to render it on its own
+type renderedUnit struct {
+	Squadrons map[string]map[string]*config.Unit `yaml:"squadron"`
+}
+
+// resolver sections and their template data names
+var resolverSections = map[string]string{
+	"vars":     "Vars",
+	"global":   "Global",
+	"squadron": "Squadron",
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private methods
+// ------------------------------------------------------------------------------------------------
+
+// resolveConfig resolves the given config data and returns the template vars with the final values
+func (sq *Squadron) resolveConfig(ctx context.Context, data map[string]any) (templatex.Vars, *unitRefs, error) {
+	r := &resolver{
+		sq:    sq,
+		ctx:   ctx,
+		data:  data,
+		nodes: map[string][]string{},
+		graph: dag.New(),
+	}
+
+	// every var and global is a node
+	for _, section := range []string{"vars", "global"} {
+		values, _ := data[section].(map[string]any)
+		for key := range values {
+			r.add([]string{section, key})
+		}
+	}
+
+	for _, section := range []string{"vars", "global", "squadron"} {
+		r.collect([]string{section}, data[section])
+	}
+
+	for id, path := range r.nodes {
+		for _, ref := range r.refs {
+			if !hasPathPrefix(ref.path, path) {
+				continue
+			}
+
+			// a value may look at all vars or globals including itself, e.g. with `hasKey .Vars`
+			for _, target := range ref.targets {
+				if target != id {
+					r.graph.Add(id, target)
+				}
+			}
+		}
+	}
+
+	waves, err := r.graph.Waves()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to resolve config")
+	}
+
+	tv, refs := r.vars()
+
+	for _, wave := range waves {
+		for _, id := range wave {
+			if err := r.render(r.nodes[id], tv, refs); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	return tv, refs, nil
+}
+
+// add adds the node at the given path
+func (r *resolver) add(path []string) string {
+	id := strings.Join(path, ".")
+	if _, ok := r.nodes[id]; !ok {
+		r.nodes[id] = path
+		r.graph.Add(id)
+	}
+
+	return id
+}
+
+// collect records the references of all templated keys and strings of the value
+func (r *resolver) collect(path []string, value any) {
+	switch v := value.(type) {
+	case string:
+		r.reference(path, v)
+	case map[string]any:
+		for key, item := range v {
+			p := append(slices.Clone(path), key)
+			r.reference(p, key)
+			r.collect(p, item)
+		}
+	case []any:
+		for i, item := range v {
+			r.collect(append(slices.Clone(path), strconv.Itoa(i)), item)
+		}
+	}
+}
+
+// reference records the nodes referenced by the templated string at the given path
+func (r *resolver) reference(path []string, value string) {
+	if !strings.Contains(value, "<%") {
+		return
+	}
+
+	// invalid templates are reported when rendering
+	refs, err := templatex.References(value)
+	if err != nil {
+		return
+	}
+
+	ret := resolverRef{path: path}
+
+	for _, ref := range refs {
+		ret.targets = append(ret.targets, r.targets(ref)...)
+	}
+
+	if len(ret.targets) > 0 {
+		r.refs = append(r.refs, ret)
+	}
+}
+
+// targets returns the nodes of the given template data reference, unknown references are left to
+// the template execution which reports them with their source location if they are actually used
+func (r *resolver) targets(ref []string) []string {
+	var section string
+
+	for key, name := range resolverSections {
+		if name == ref[0] {
+			section = key
+		}
+	}
+
+	if section == "" {
+		return nil
+	}
+
+	values, _ := r.data[section].(map[string]any)
+
+	// the squadrons are only resolved as far as they are referenced
+	if section == "squadron" {
+		path := []string{section}
+		value := any(values)
+
+		for _, key := range ref[1:] {
+			m, ok := value.(map[string]any)
+			if !ok {
+				break
+			}
+
+			if value, ok = m[key]; !ok {
+				break
+			}
+
+			path = append(path, key)
+		}
+
+		if len(path) == 1 {
+			return nil
+		}
+
+		return []string{r.add(path)}
+	}
+
+	// referencing all vars or globals
+	if len(ref) == 1 {
+		ret := make([]string, 0, len(values))
+		for key := range values {
+			ret = append(ret, section+"."+key)
+		}
+
+		sort.Strings(ret)
+
+		return ret
+	}
+
+	if _, ok := values[ref[1]]; !ok {
+		return nil
+	}
+
+	return []string{section + "." + ref[1]}
+}
+
+// vars returns the template vars and unit references backed by the config data
+func (r *resolver) vars() (templatex.Vars, *unitRefs) {
+	tv := templatex.Vars{}
+	tv.Add("Env", r.sq.env)
+	tv.Add("Builds", r.sq.buildImages(r.sq.c.Builds, nil))
+
+	for _, section := range []string{"global", "vars", "squadron"} {
+		if value, ok := r.data[section]; ok {
+			tv.Add(resolverSections[section], value)
+		}
+	}
+
+	return tv, r.sq.newUnitRefs(r.ctx, tv, r.data, true)
+}
+
+// render executes the templates of the value at the given path and replaces it with the result
+func (r *resolver) render(path []string, tv templatex.Vars, refs *unitRefs) error {
+	value := getPath(r.data, path)
+	if !hasTemplate(value) {
+		return nil
+	}
+
+	// render the value at its original position to keep indentations
+	var doc any = value
+	for i := len(path) - 1; i >= 0; i-- {
+		doc = map[string]any{path[i]: doc}
+	}
+
+	text, err := yamlv2.Marshal(doc)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal `%s`", strings.Join(path, "."))
+	}
+
+	out, err := templatex.ExecuteFileTemplate(r.ctx, string(text), tv, true, refs.FuncMap())
+	if err != nil {
+		return errors.Wrapf(templatex.LocateError(err, string(text), r.sq.merged), "failed to render `%s`", strings.Join(path, "."))
+	}
+
+	var ret map[string]any
+	if err := yaml.Unmarshal(out, &ret); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal `%s`", strings.Join(path, "."))
+	}
+
+	setPath(r.data, path, getPath(ret, path))
+
+	return nil
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private functions
+// ------------------------------------------------------------------------------------------------
+
+func newRenderedUnit(squadron, unit string, u *config.Unit) renderedUnit {
+	return renderedUnit{Squadrons: map[string]map[string]*config.Unit{squadron: {unit: u}}}
+}
+
+func (u renderedUnit) unit(squadron, unit string) *config.Unit {
+	if ret := u.Squadrons[squadron][unit]; ret != nil {
+		return ret
+	}
+
+	return &config.Unit{}
+}
+
+func hasPathPrefix(path, prefix []string) bool {
+	return len(path) >= len(prefix) && slices.Equal(path[:len(prefix)], prefix)
+}
+
+func getPath(data map[string]any, path []string) any {
+	var ret any = data
+
+	for _, key := range path {
+		m, ok := ret.(map[string]any)
+		if !ok {
+			return nil
+		}
+
+		ret = m[key]
+	}
+
+	return ret
+}
+
+func setPath(data map[string]any, path []string, value any) {
+	for _, key := range path[:len(path)-1] {
+		data, _ = data[key].(map[string]any)
+	}
+
+	data[path[len(path)-1]] = value
+}
+
+// hasTemplate returns true if any key or string of the value contains a template
+func hasTemplate(value any) bool {
+	switch v := value.(type) {
+	case string:
+		return strings.Contains(v, "<%")
+	case map[string]any:
+		for key, item := range v {
+			if strings.Contains(key, "<%") || hasTemplate(item) {
+				return true
+			}
+		}
+	case []any:
+		return slices.ContainsFunc(v, hasTemplate)
+	}
+
+	return false
+}
@@ -0,0 +1,71 @@
+package squadron_test
+
+import (
+	"path"
+	"testing"
+
+	testingx "github.com/foomo/go/testing"
+	tagx "github.com/foomo/go/testing/tag"
+	"github.com/foomo/squadron"
+	"github.com/foomo/squadron/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSquadron_RenderConfig_resolve(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+	t.Setenv("PROJECT_ROOT", ".")
+
+	var cwd string
+
+	ctx := t.Context()
+	require.NoError(t, util.ValidatePath(".", &cwd))
+
+	t.Run("resolved", func(t *testing.T) {
+		sq := squadron.New(cwd, "default", []string{path.Join("testdata", "resolve", "squadron.yaml")})
+		require.NoError(t, sq.MergeConfigFiles(ctx))
+		require.NoError(t, sq.RenderConfig(ctx))
+
+		c := sq.Config()
+		assert.Equal(t, "https://api.example.com/v2", c.Vars["url"])
+		assert.Equal(t, "v2", c.Global["path"])
+		assert.Equal(t, "v2", c.Vars["tag"])
+
+		unit := c.Squadrons["storefinder"]["backend"]
+		assert.Equal(t, []string{"api.example.com/backend:latest"}, unit.Builds["default"].Tag)
+		assert.Equal(t, map[string]any{
+			"url":   "https://api.example.com/v2",
+			"image": map[string]any{"repository": "api.example.com/backend:latest"},
+		}, unit.Values)
+	})
+
+	tests := []struct {
+		name string
+		file string
+		want string
+	}{
+		{
+			name: "cycle",
+			file: "cycle.yaml",
+			want: "failed to resolve config: dependency cycle detected: global.c -> vars.a -> vars.b -> global.c",
+		},
+		{
+			name: "unresolved",
+			file: "unresolved.yaml",
+			want: "failed to render `vars.a`: testdata/resolve/unresolved.yaml:4:6: at <.Vars.b>: map has no entry for key \"b\"\n" +
+				" 2 |\n" +
+				" 3 | vars:\n" +
+				" 4 |   a: <% .Vars.b %>\n" +
+				"   |      ^\n" +
+				" 5 |\n" +
+				" 6 | squadron:",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sq := squadron.New(cwd, "default", []string{path.Join("testdata", "resolve", tt.file)})
+			require.NoError(t, sq.MergeConfigFiles(ctx))
+			require.EqualError(t, sq.RenderConfig(ctx), tt.want)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package squadron_test
+
+import (
+	"path"
+	"testing"
+
+	testingx "github.com/foomo/go/testing"
+	tagx "github.com/foomo/go/testing/tag"
+	"github.com/foomo/squadron"
+	"github.com/foomo/squadron/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSquadron_SelectConfig(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+	t.Setenv("PROJECT_ROOT", ".")
+
+	var cwd string
+	require.NoError(t, util.ValidatePath(".", &cwd))
+
+	tests := []struct {
+		expr string
+		want map[string][]string
+	}{
+		{
+			expr: "tag:backend && !tag:skip",
+			want: map[string][]string{"checkout": {"backend"}},
+		},
+		{
+			expr: "squadron in (checkout,storefinder) && (tag:frontend || tag:skip)",
+			want: map[string][]string{"checkout": {"frontend"}, "storefinder": {"backend", "frontend"}},
+		},
+		{
+			expr: "*/front*",
+			want: map[string][]string{"checkout": {"frontend"}, "storefinder": {"frontend"}},
+		},
+		{
+			expr: "tag:none",
+			want: map[string][]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			ctx := t.Context()
+
+			sq := squadron.New(cwd, "default", []string{path.Join("testdata", "tags", "squadron.yaml")})
+			require.NoError(t, sq.MergeConfigFiles(ctx))
+			require.NoError(t, sq.SelectConfig(ctx, tt.expr))
+
+			got := map[string][]string{}
+			for key, units := range sq.Config().Squadrons {
+				got[key] = units.Keys()
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		sq := squadron.New(cwd, "default", []string{path.Join("testdata", "tags", "squadron.yaml")})
+		require.NoError(t, sq.MergeConfigFiles(t.Context()))
+		require.EqualError(t, sq.SelectConfig(t.Context(), "tag:a ||"), "failed to parse selector: unexpected end of selector at 8")
+	})
+}
@@ -6,54 +6,77 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
 	"path"
+	"path/filepath"
 	"slices"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/foomo/squadron/internal/config"
+	"github.com/foomo/squadron/config"
+	"github.com/foomo/squadron/dag"
+	"github.com/foomo/squadron/internal/diff"
 	"github.com/foomo/squadron/internal/git"
+	"github.com/foomo/squadron/internal/helm"
 	"github.com/foomo/squadron/internal/jsonschema"
 	ptermx "github.com/foomo/squadron/internal/pterm"
+	"github.com/foomo/squadron/internal/selector"
 	templatex "github.com/foomo/squadron/internal/template"
 	"github.com/foomo/squadron/internal/util"
 	"github.com/miracl/conflate"
 	"github.com/pkg/errors"
 	"github.com/pterm/pterm"
-	"github.com/sters/yaml-diff/yamldiff"
 	"golang.org/x/sync/errgroup"
 	yamlv2 "gopkg.in/yaml.v2"
 	"gopkg.in/yaml.v3"
 )
 
-const (
-	errHelmReleaseNotFound = "Error: release: not found"
-)
-
 type Squadron struct {
 	basePath  string
 	namespace string
 	files     []string
 	config    string
+	source    []byte
+	merged    []string
+	offline   bool
+	env       string
+	envConfig *config.Environment
+	observer  Observer
 	c         config.Config
+	// config before rendering to render again with the built images
+	unrendered string
+	// merged squadrons before filtering to resolve unit references to filtered units
+	unfiltered map[string]any
+	lock       sync.Mutex
+	// image digests by tag
+	digests map[string]string
+	// convert builds into bake targets
+	bakeBuilds bool
 }
 
-func New(basePath, namespace string, files []string) *Squadron {
-	return &Squadron{
+// New returns a squadron for the given config files, resolving relative paths from the base path
+func New(basePath, namespace string, files []string, opts ...Option) *Squadron {
+	ret := &Squadron{
 		basePath:  basePath,
 		namespace: namespace,
 		files:     files,
 		c:         config.Config{},
 	}
+
+	for _, opt := range opts {
+		opt(ret)
+	}
+
+	return ret
 }
 
 // ------------------------------------------------------------------------------------------------
 // ~ Getter
 // ------------------------------------------------------------------------------------------------
 
+// Namespace renders the namespace of the given unit, preferring the unit's own namespace over the
+// explicitly given one, the environment's namespace and finally `default`
 func (sq *Squadron) Namespace(ctx context.Context, squadron, unit string, u *config.Unit) (string, error) {
 	var tpl string
 
@@ -62,11 +85,18 @@ func (sq *Squadron) Namespace(ctx context.Context, squadron, unit string, u *con
 		tpl = u.Namespace
 	case sq.namespace != "":
 		tpl = sq.namespace
+	case sq.envConfig != nil && sq.envConfig.Namespace != "":
+		tpl = sq.envConfig.Namespace
 	default:
 		return "default", nil
 	}
 
-	return util.RenderTemplateString(tpl, map[string]string{"Squadron": squadron, "Unit": unit})
+	return util.RenderTemplateString(tpl, map[string]string{"Squadron": squadron, "Unit": unit, "Env": sq.env})
+}
+
+// Env returns the selected environment
+func (sq *Squadron) Env() string {
+	return sq.env
 }
 
 func (sq *Squadron) Config() config.Config {
@@ -77,46 +107,87 @@ func (sq *Squadron) ConfigYAML() string {
 	return sq.config
 }
 
+// UnitWaves returns the `squadron/unit` ids grouped by their dependencies
+func (sq *Squadron) UnitWaves(ctx context.Context) ([][]string, error) {
+	return sq.c.UnitGraph(ctx).Waves()
+}
+
 // ------------------------------------------------------------------------------------------------
 // ~ Public methods
 // ------------------------------------------------------------------------------------------------
 
 func (sq *Squadron) MergeConfigFiles(ctx context.Context) error {
-	start := time.Now()
-
-	pterm.Info.Println("📚 | merging configs")
+	done := sq.step(OperationMerge, "📚 | merging configs")
 
-	mergedFiles, err := conflate.FromFiles(sq.files...)
+	fileBytes, err := sq.mergeFiles(sq.files)
 	if err != nil {
-		return errors.Wrap(err, "failed to conflate files")
+		return err
 	}
 
-	fileBytes, err := mergedFiles.MarshalYAML()
-	if err != nil {
-		return errors.Wrap(err, "failed to marshal yaml")
-	}
+	var env *config.Environment
 
-	if err := yaml.Unmarshal(fileBytes, &sq.c); err != nil {
-		pterm.Error.Println(string(fileBytes))
-		return errors.Wrap(err, "failed to unmarshal yaml")
+	if sq.env != "" {
+		if env, err = sq.c.Environment(sq.env); err != nil {
+			return err
+		}
+
+		// merge again including the environment files
+		if len(env.Files) > 0 {
+			sq.c = config.Config{}
+			if fileBytes, err = sq.mergeFiles(append(slices.Clone(sq.files), env.Files...)); err != nil {
+				return err
+			}
+
+			if env, err = sq.c.Environment(sq.env); err != nil {
+				return err
+			}
+		}
 	}
 
+	sq.source = fileBytes
+
 	if sq.c.Version != config.Version {
-		pterm.Error.Println(string(fileBytes))
-		return errors.New("Please upgrade your YAML definition to from '" + sq.c.Version + "' to '" + config.Version + "'")
+		sq.fail(OperationMerge, "unsupported version", string(fileBytes), util.Highlight)
+		return errors.New("Please upgrade your YAML definition to from '" + sq.c.Version + "' to '" + config.Version + "' or run `squadron migrate`")
+	}
+
+	if err := sq.c.ResolveTemplates(); err != nil {
+		return err
 	}
 
 	sq.c.Trim(ctx)
 
+	// validate before the environment removes units, dependencies on removed units are ignored like
+	// with `FilterConfig`
+	if err := sq.c.UnitGraph(ctx).Validate(); err != nil {
+		return errors.Wrap(err, "invalid unit dependencies")
+	}
+
+	if env != nil {
+		if err := env.Apply(ctx, &sq.c); err != nil {
+			return errors.Wrapf(err, "failed to apply environment `%s`", sq.env)
+		}
+
+		sq.envConfig = env
+		sq.c.Environments = nil
+	}
+
 	value, err := yamlv2.Marshal(sq.c)
 	if err != nil {
-		pterm.Error.Println(string(fileBytes))
+		sq.fail(OperationMerge, "failed to marshal yaml", string(fileBytes), util.Highlight)
 		return errors.Wrap(err, "failed to marshal yaml")
 	}
 
 	sq.config = string(value)
 
-	pterm.Success.Println("📚 | merging configs ⏱ " + time.Since(start).Round(time.Millisecond).String())
+	var data map[string]any
+	if err := yaml.Unmarshal(value, &data); err != nil {
+		return errors.Wrap(err, "failed to unmarshal yaml")
+	}
+
+	sq.unfiltered, _ = data["squadron"].(map[string]any)
+
+	done()
 
 	return nil
 }
@@ -166,88 +237,45 @@ func (sq *Squadron) FilterConfig(ctx context.Context, squadron string, units, ta
 	return nil
 }
 
-func (sq *Squadron) RenderConfig(ctx context.Context) error {
-	start := time.Now()
-
-	pterm.Info.Println("📗 | rendering config")
-
-	var (
-		tv   templatex.Vars
-		vars map[string]any
-	)
-
-	if err := yaml.Unmarshal([]byte(sq.config), &vars); err != nil {
-		return errors.Wrap(err, "failed to render config")
-	}
-
-	// execute again with loaded template vars
-	tv = templatex.Vars{}
-	if value, ok := vars["global"]; ok {
-		tv.Add("Global", value)
-	}
-
-	if value, ok := vars["vars"]; ok {
-		tv.Add("Vars", value)
-	}
-
-	if value, ok := vars["squadron"]; ok {
-		tv.Add("Squadron", value)
-	}
-
-	out1, err := templatex.ExecuteFileTemplate(ctx, sq.config, tv, false)
-	if err != nil {
-		return errors.Wrapf(err, "failed to execute initial file template\n%s", util.Highlight(sq.config))
-	}
-
-	// re-execute for rendering copied values
-	out2, err := templatex.ExecuteFileTemplate(ctx, string(out1), tv, false)
+// SelectConfig removes all units not matching the given selector expression,
+// e.g. `squadron in (checkout,storefinder) && (tag:backend || tag:critical) && !tag:skip`
+func (sq *Squadron) SelectConfig(ctx context.Context, expr string) error {
+	s, err := selector.Parse(expr)
 	if err != nil {
-		fmt.Print(util.Highlight(string(out1)))
-		return errors.Wrap(err, "failed to re-execute initial file template")
-	}
-
-	if err := yaml.Unmarshal(out2, &vars); err != nil {
-		fmt.Print(util.Highlight(string(out2)))
-		return errors.Wrap(err, "failed to unmarshal vars")
+		return errors.Wrap(err, "failed to parse selector")
 	}
 
-	// execute again with loaded template vars
-	tv = templatex.Vars{}
-	if value, ok := vars["global"]; ok {
-		tv.Add("Global", value)
-	}
-
-	if value, ok := vars["vars"]; ok {
-		tv.Add("Vars", value)
+	if err := sq.Config().Squadrons.Iterate(ctx, func(ctx context.Context, key string, value config.Map[*config.Unit]) error {
+		return value.FilterFn(func(k string, v *config.Unit) bool {
+			return s.Match(selector.Target{Squadron: key, Unit: k, Tags: v.Tags.Strings()})
+		})
+	}); err != nil {
+		return err
 	}
 
-	if value, ok := vars["squadron"]; ok {
-		tv.Add("Squadron", value)
-	}
+	sq.c.Trim(ctx)
 
-	out3, err := templatex.ExecuteFileTemplate(ctx, sq.config, tv, true)
+	value, err := yamlv2.Marshal(sq.c)
 	if err != nil {
-		fmt.Print(util.Highlight(sq.config))
-		return errors.Wrap(err, "failed to execute second file template")
+		return err
 	}
 
-	if err := yaml.Unmarshal(out3, &sq.c); err != nil {
-		fmt.Print(util.Highlight(string(out3)))
-		return errors.Wrap(err, "failed to unmarshal vars")
-	}
+	sq.config = string(value)
 
-	sq.config = string(out3)
+	return nil
+}
 
-	pterm.Success.Println("📗 | rendering config ⏱ " + time.Since(start).Round(time.Millisecond).String())
+func (sq *Squadron) RenderConfig(ctx context.Context) error {
+	sq.unrendered = sq.config
 
-	return nil
+	return sq.renderConfig(ctx)
 }
 
-func (sq *Squadron) Push(ctx context.Context, pushArgs []string, parallel int) error {
-	wg, ctx := errgroup.WithContext(ctx)
+func (sq *Squadron) Push(ctx context.Context, pushArgs []string, parallel int) ([]Result, error) {
+	wg, wgCtx := errgroup.WithContext(ctx)
 	wg.SetLimit(parallel)
 
-	printer := ptermx.MustNewMultiPrinter()
+	printer := sq.newProgress(OperationPush)
 	defer printer.Stop()
 
 	type one struct {
@@ -265,7 +293,7 @@ func (sq *Squadron) Push(ctx context.Context, pushArgs []string, parallel int) e
 			for _, name := range v.BuildNames() {
 				build := v.Builds[name]
 				for _, tag := range build.Tag {
-					spinner := printer.NewSpinner(fmt.Sprintf("🚚 | %s/%s.%s %s", key, k, name, tag))
+					spinner := printer.NewTask(key, k, name+" "+tag, fmt.Sprintf("🚚 | %s/%s.%s %s", key, k, name, tag))
 					all = append(all, one{
 						spinner:  spinner,
 						squadron: key,
@@ -280,7 +308,7 @@ func (sq *Squadron) Push(ctx context.Context, pushArgs []string, parallel int) e
 			for _, name := range v.BakeNames() {
 				bake := v.Bakes[name]
 				for _, tag := range bake.Tags {
-					spinner := printer.NewSpinner(fmt.Sprintf("🚚 | %s/%s.%s (%s)", key, k, name, tag))
+					spinner := printer.NewTask(key, k, name+" "+tag, fmt.Sprintf("🚚 | %s/%s.%s (%s)", key, k, name, tag))
 					all = append(all, one{
 						spinner:  spinner,
 						squadron: key,
@@ -300,7 +328,7 @@ func (sq *Squadron) Push(ctx context.Context, pushArgs []string, parallel int) e
 		wg.Go(func() error {
 			a.spinner.Play()
 
-			ctx := ptermx.ContextWithSpinner(ctx, a.spinner)
+			ctx := ptermx.ContextWithSpinner(wgCtx, a.spinner)
 			if err := ctx.Err(); err != nil {
 				a.spinner.Warning(err.Error())
 				return err
@@ -318,91 +346,43 @@ func (sq *Squadron) Push(ctx context.Context, pushArgs []string, parallel int) e
 
 			pterm.Debug.Printfln("running docker push for %s", a.image)
 
-			if out, err := util.NewDockerCommand().Push(a.image).Args(cleanArgs...).Run(ctx); err != nil {
+			out, err := util.NewDockerCommand().Push(a.image).Args(cleanArgs...).Run(ctx)
+			if err != nil {
 				a.spinner.Fail(out)
 				return err
 			}
 
+			sq.setDigest(pushDigest(out), a.image)
+
 			a.spinner.Success()
 
 			return nil
 		})
 	}
 
-	return wg.Wait()
-}
-
-func (sq *Squadron) BuildDependencies(ctx context.Context, buildArgs []string, parallel int) error {
-	printer := ptermx.MustNewMultiPrinter()
-	defer printer.Stop()
-
-	dependencies := sq.c.BuildDependencies(ctx)
-
-	run := func(ctx context.Context, name string, build config.Build) error {
-		spinner := printer.NewSpinner(fmt.Sprintf("💾 | %s %s", name, build.Tag))
-		spinner.Start()
-		spinner.Play()
-
-		ctx = ptermx.ContextWithSpinner(ctx, spinner)
-		if err := ctx.Err(); err != nil {
-			spinner.Warning(err.Error())
-			return err
-		}
-
-		if out, err := build.Build(ctx, "", "", buildArgs); err != nil {
-			spinner.Fail(out)
-			return err
-		}
-
-		spinner.Success()
-
-		return nil
+	if err := wg.Wait(); err != nil {
+		return printer.Results(), err
 	}
 
-	{ // build sub-depencies
-		wg, ctx := errgroup.WithContext(ctx)
-		wg.SetLimit(parallel)
+	printer.Stop()
 
-		for _, build := range dependencies {
-			if len(build.Dependencies) > 0 {
-				for _, name := range build.Dependencies {
-					if b, ok := dependencies[name]; ok {
-						delete(dependencies, name)
-						wg.Go(func() error {
-							return run(ctx, name, b)
-						})
-					}
-				}
-			}
-		}
+	return printer.Results(), sq.rerenderConfig(ctx)
+}
 
-		if err := wg.Wait(); err != nil {
-			return err
-		}
+// BuildDependencies builds the global builds required by the units in dependency order
+func (sq *Squadron) BuildDependencies(ctx context.Context, buildArgs []string, parallel int) error {
+	graph, err := sq.buildGraph(ctx)
+	if err != nil {
+		return err
 	}
 
-	{ // build dependencies
-		wg, ctx := errgroup.WithContext(ctx)
-		wg.SetLimit(parallel)
-
-		for name, build := range dependencies {
-			wg.Go(func() error {
-				return run(ctx, name, build)
-			})
-		}
-
-		if err := wg.Wait(); err != nil {
-			return err
-		}
-	}
+	_, err = sq.build(ctx, graph, buildArgs, parallel, false)
 
-	return nil
+	return err
 }
 
 func (sq *Squadron) Bakefile(ctx context.Context) ([]byte, error) {
-	start := time.Now()
-
-	pterm.Info.Println("🔥 | generating bakefile")
+	done := sq.step(OperationBakefile, "🔥 | generating bakefile")
 
 	c := &config.Bake{
 		Groups:  nil,
@@ -419,91 +399,136 @@ func (sq *Squadron) Bakefile(ctx context.Context) ([]byte, error) {
 
 	now := time.Now()
 
-	err = sq.Config().Squadrons.Iterate(ctx, func(ctx context.Context, key string, value config.Map[*config.Unit]) error {
-		return value.Iterate(ctx, func(ctx context.Context, k string, v *config.Unit) error {
-			for _, name := range v.BakeNames() {
-				item := v.Bakes[name]
+	add := func(key, k, name string, item config.BakeTarget) error {
+		if key != "" {
+			if item.Args == nil {
+				item.Args = make(map[string]string)
+			}
 
-				item.Name = strings.Join([]string{"squadron", key, k, name}, "-")
-				if item.Args == nil {
-					item.Args = make(map[string]string)
-				}
+			item.Args["SQUADRON_NAME"] = key
+			item.Args["SQUADRON_UNIT_NAME"] = k
+		}
+
+		if item.Labels == nil {
+			item.Labels = make(map[string]string)
+		}
+
+		item.Labels["org.opencontainers.image.source"] = gitInfo.URL
+		item.Labels["org.opencontainers.image.version"] = gitInfo.Ref
+		item.Labels["org.opencontainers.image.created"] = now.Format(time.RFC3339)
+		item.Labels["org.opencontainers.image.revision"] = gitInfo.Commit
 
-				item.Args["SQUADRON_NAME"] = key
-				item.Args["SQUADRON_UNIT_NAME"] = k
+		// Workaround
+		if typ := os.Getenv("SQUADRON_BAKE_CACHE_TYPE"); typ != "" {
+			var scope string
+			if value := os.Getenv("SQUADRON_BAKE_CACHE_SCOPE"); value != "" {
+				scope = value + "-"
+			}
 
-				if item.Labels == nil {
-					item.Labels = make(map[string]string)
+			switch typ {
+			case "gha":
+				item.CacheFrom = append(item.CacheFrom, map[string]string{
+					"type":  typ,
+					"scope": scope + item.Name,
+				})
+				item.CacheTo = append(item.CacheTo, map[string]string{
+					"type":  typ,
+					"scope": scope + item.Name,
+					"mode":  "max",
+				})
+			case "local":
+				if src := os.Getenv("SQUADRON_BAKE_CACHE_FROM"); src != "" {
+					item.CacheFrom = append(item.CacheFrom, map[string]string{
+						"type": typ,
+						"src":  src + "/" + scope + item.Name,
+					})
 				}
 
-				item.Labels["org.opencontainers.image.source"] = gitInfo.URL
-				item.Labels["org.opencontainers.image.version"] = gitInfo.Ref
-				item.Labels["org.opencontainers.image.created"] = now.Format(time.RFC3339)
-				item.Labels["org.opencontainers.image.revision"] = gitInfo.Commit
+				if dest := os.Getenv("SQUADRON_BAKE_CACHE_TO"); dest != "" {
+					item.CacheTo = append(item.CacheTo, map[string]string{
+						"type": typ,
+						"dest": dest + "/" + scope + item.Name,
+						"mode": "max",
+					})
+				}
+			default:
+				data := map[string]any{"Squadron": key, "Unit": k, "Bake": item}
+
+				if src := os.Getenv("SQUADRON_BAKE_CACHE_FROM"); src != "" {
+					for s := range strings.SplitSeq(src, ";") {
+						str, err := util.RenderTemplateString(s, data)
+						if err != nil {
+							return errors.Wrap(err, "failed to render bake cache-from template: "+s)
+						}
 
-				// Workaround
-				if typ := os.Getenv("SQUADRON_BAKE_CACHE_TYPE"); typ != "" {
-					var scope string
-					if value := os.Getenv("SQUADRON_BAKE_CACHE_SCOPE"); value != "" {
-						scope = value + "-"
+						item.CacheFrom = append(item.CacheFrom, util.StringToMap("type="+typ+","+str))
 					}
+				}
 
-					switch typ {
-					case "gha":
-						item.CacheFrom = append(item.CacheFrom, map[string]string{
-							"type":  typ,
-							"scope": scope + item.Name,
-						})
-						item.CacheTo = append(item.CacheTo, map[string]string{
-							"type":  typ,
-							"scope": scope + item.Name,
-							"mode":  "max",
-						})
-					case "local":
-						if src := os.Getenv("SQUADRON_BAKE_CACHE_FROM"); src != "" {
-							item.CacheFrom = append(item.CacheFrom, map[string]string{
-								"type": typ,
-								"src":  src + "/" + scope + item.Name,
-							})
+				if src := os.Getenv("SQUADRON_BAKE_CACHE_TO"); src != "" {
+					for s := range strings.SplitSeq(src, ";") {
+						str, err := util.RenderTemplateString(s, data)
+						if err != nil {
+							return errors.Wrap(err, "failed to render bake cache-to template: "+s)
 						}
 
-						if dest := os.Getenv("SQUADRON_BAKE_CACHE_TO"); dest != "" {
-							item.CacheTo = append(item.CacheTo, map[string]string{
-								"type": typ,
-								"dest": dest + "/" + scope + item.Name,
-								"mode": "max",
-							})
-						}
-					default:
-						data := map[string]any{"Squadron": key, "Unit": k, "Bake": item}
-
-						if src := os.Getenv("SQUADRON_BAKE_CACHE_FROM"); src != "" {
-							for s := range strings.SplitSeq(src, ";") {
-								str, err := util.RenderTemplateString(s, data)
-								if err != nil {
-									return errors.Wrap(err, "failed to render bake cache-from template: "+s)
-								}
-
-								item.CacheFrom = append(item.CacheFrom, util.StringToMap("type="+typ+","+str))
-							}
-						}
+						item.CacheTo = append(item.CacheTo, util.StringToMap("type="+typ+","+str))
+					}
+				}
+			}
+		}
 
-						if src := os.Getenv("SQUADRON_BAKE_CACHE_TO"); src != "" {
-							for s := range strings.SplitSeq(src, ";") {
-								str, err := util.RenderTemplateString(s, data)
-								if err != nil {
-									return errors.Wrap(err, "failed to render bake cache-to template: "+s)
-								}
+		if key != "" {
+			sq.info(OperationBakefile, fmt.Sprintf("📦 | %s/%s.%s (%s)", key, k, name, strings.Join(item.Tags, ",")))
+		} else {
+			sq.info(OperationBakefile, fmt.Sprintf("💾 | %s (%s)", name, strings.Join(item.Tags, ",")))
+		}
 
-								item.CacheTo = append(item.CacheTo, util.StringToMap("type="+typ+","+str))
-							}
-						}
+		g.Targets = append(g.Targets, item.Name)
+		c.Targets = append(c.Targets, &item)
+
+		return nil
+	}
+
+	// convert the builds including the global builds they depend on
+	if sq.bakeBuilds {
+		graph, err := sq.buildGraph(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, id := range graph.Nodes() {
+			key, k, name, build := sq.graphBuild(id)
+
+			item := build.BakeTarget(bakeBuildTarget(key, k, name))
+
+			// reference the targets of the dependencies by their name and tags
+			for _, dependency := range graph.Dependencies(id) {
+				target := "target:" + bakeBuildTarget("", "", dependency)
+				for _, context := range append([]string{dependency}, sq.c.Builds[dependency].Tag...) {
+					if item.Contexts == nil {
+						item.Contexts = map[string]string{}
 					}
+
+					item.Contexts[context] = target
 				}
+			}
+
+			if err := add(key, k, name, item); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	err = sq.Config().Squadrons.Iterate(ctx, func(ctx context.Context, key string, value config.Map[*config.Unit]) error {
+		return value.Iterate(ctx, func(ctx context.Context, k string, v *config.Unit) error {
+			for _, name := range v.BakeNames() {
+				item := v.Bakes[name]
+				item.Name = strings.Join([]string{"squadron", key, k, name}, "-")
 
-				pterm.Info.Printfln("📦 | %s/%s.%s (%s)", key, k, name, strings.Join(item.Tags, ","))
-				g.Targets = append(g.Targets, item.Name)
-				c.Targets = append(c.Targets, &item)
+				if err := add(key, k, name, item); err != nil {
+					return err
+				}
 			}
 
 			return nil
@@ -519,7 +544,7 @@ func (sq *Squadron) Bakefile(ctx context.Context) ([]byte, error) {
 
 	c.Groups = append(c.Groups, g)
 
-	pterm.Success.Println("🔥 | generating bakefile ⏱ " + time.Since(start).Round(time.Millisecond).String())
+	done()
 
 	out, err := c.HCL()
 	if err != nil {
@@ -532,16 +557,23 @@ func (sq *Squadron) Bakefile(ctx context.Context) ([]byte, error) {
 }
 
 func (sq *Squadron) Bake(ctx context.Context, bakefile []byte, args []string) error {
-	start := time.Now()
-
 	var cleanArgs []string
 	for _, arg := range args {
 		cleanArgs = append(cleanArgs, strings.Split(arg, " ")...)
 	}
 
-	pterm.Info.Println("🔥 | baking targets")
+	done := sq.step(OperationBake, "🔥 | baking targets")
+
+	// capture the image digests from the bake metadata
+	tmp, err := os.MkdirTemp("", "squadron-bake-")
+	if err != nil {
+		return errors.Wrap(err, "failed to create bake metadata dir")
+	}
+	defer os.RemoveAll(tmp)
+
+	metadata := filepath.Join(tmp, "metadata.json")
 
-	cmd := util.NewDockerCommand().Bake(bytes.NewReader(bakefile)).Args(cleanArgs...)
+	cmd := util.NewDockerCommand().Bake(bytes.NewReader(bakefile)).Args(cleanArgs...).Args("--metadata-file", metadata)
 
 	if pterm.PrintDebugMessages ||
 		slices.Contains(cleanArgs, "--print") ||
@@ -555,148 +587,150 @@ func (sq *Squadron) Bake(ctx context.Context, bakefile []byte, args []string) er
 		if pterm.PrintDebugMessages {
 			return err
 		} else {
-			pterm.Println(util.HighlightHCL(string(bakefile) + "\n"))
+			sq.fail(OperationBake, "failed to bake", string(bakefile)+"\n", util.HighlightHCL)
 			return errors.Wrap(err, out)
 		}
 	}
 
-	pterm.Success.Println("🔥 | baking targets ⏱︎ " + time.Since(start).Round(time.Millisecond).String())
+	if digests, err := readBakeMetadata(metadata); err != nil {
+		pterm.Debug.Println(err.Error())
+	} else {
+		for tag, digest := range digests {
+			sq.setDigest(digest, tag)
+		}
+	}
+
+	done()
 
-	return nil
+	return sq.rerenderConfig(ctx)
 }
 
-func (sq *Squadron) Build(ctx context.Context, buildArgs []string, parallel int) error {
-	if err := sq.BuildDependencies(ctx, buildArgs, parallel); err != nil {
-		return err
+// Build builds the units and the global builds they depend on, starting every build as soon as
+// its dependencies are built
+func (sq *Squadron) Build(ctx context.Context, buildArgs []string, parallel int) ([]Result, error) {
+	graph, err := sq.buildGraph(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	wg, ctx := errgroup.WithContext(ctx)
-	wg.SetLimit(parallel)
+	results, err := sq.build(ctx, graph, buildArgs, parallel, true)
+	if err != nil {
+		return results, err
+	}
 
-	printer := ptermx.MustNewMultiPrinter()
-	defer printer.Stop()
+	return results, sq.rerenderConfig(ctx)
+}
 
-	type one struct {
-		spinner  ptermx.Spinner
-		squadron string
-		unit     string
-		item     config.Build
+func (sq *Squadron) Down(ctx context.Context, opts HelmOptions, parallel int) ([]Result, error) {
+	backend, err := opts.backend()
+	if err != nil {
+		return nil, err
 	}
 
-	var all []one
+	waves, err := sq.UnitWaves(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	_ = sq.Config().Squadrons.Iterate(ctx, func(ctx context.Context, key string, value config.Map[*config.Unit]) error {
-		return value.Iterate(ctx, func(ctx context.Context, k string, v *config.Unit) error {
-			for _, name := range v.BuildNames() {
-				item := v.Builds[name]
-				item.BuildArg = append(item.BuildArg,
-					"SQUADRON_NAME="+key,
-					"SQUADRON_UNIT_NAME="+k,
-				)
-				spinner := printer.NewSpinner(fmt.Sprintf("📦 | %s/%s.%s %s", key, k, name, item.Tag))
-				all = append(all, one{
-					spinner:  spinner,
-					squadron: key,
-					unit:     k,
-					item:     item,
-				})
-				spinner.Start()
-			}
+	// uninstall dependents first
+	slices.Reverse(waves)
 
-			return nil
-		})
-	})
+	printer := sq.newProgress(OperationDown)
+	defer printer.Stop()
 
-	now := time.Now()
+	err = sq.iterateWaves(ctx, waves, parallel, func(ctx context.Context, key, k string, v *config.Unit) error {
+		spinner := printer.NewTask(key, k, "", fmt.Sprintf("🗑️ | %s/%s", key, k))
+		spinner.Start()
+		spinner.Play()
 
-	gitInfo, err := git.GetInfo(ctx)
-	if err != nil {
-		return err
-	}
+		ctx = ptermx.ContextWithSpinner(ctx, spinner)
+		if err := ctx.Err(); err != nil {
+			spinner.Warning(err.Error())
+			return err
+		}
 
-	for _, a := range all {
-		wg.Go(func() error {
-			a.spinner.Play()
+		name := sq.getReleaseName(key, k, v)
 
-			ctx := ptermx.ContextWithSpinner(ctx, a.spinner)
-			if err := ctx.Err(); err != nil {
-				a.spinner.Warning(err.Error())
-				return nil
-			}
+		namespace, err := sq.Namespace(ctx, key, k, v)
+		if err != nil {
+			return err
+		}
 
-			a.item.Label = append(a.item.Label,
-				"org.opencontainers.image.source="+gitInfo.URL,
-				"org.opencontainers.image.version="+gitInfo.Ref,
-				"org.opencontainers.image.created="+now.Format(time.RFC3339),
-				"org.opencontainers.image.revision="+gitInfo.Commit,
-			)
+		if err := sq.runHooks(ctx, config.HookPreDown, key, k, v); err != nil {
+			spinner.Fail(err.Error())
+			return err
+		}
 
-			if out, err := a.item.Build(ctx, a.squadron, a.unit, buildArgs); errors.Is(ctx.Err(), context.Canceled) {
-				a.spinner.Warning(ctx.Err().Error())
-				return nil
-			} else if err != nil {
-				a.spinner.Fail(out)
-				return err
-			}
+		if err := backend.Uninstall(ctx, name, namespace); err != nil && !errors.Is(err, helm.ErrReleaseNotFound) {
+			spinner.Fail(err.Error())
+			return err
+		}
 
-			a.spinner.Success()
+		if err := sq.runHooks(ctx, config.HookPostDown, key, k, v); err != nil {
+			spinner.Fail(err.Error())
+			return err
+		}
 
-			return nil
-		})
-	}
+		spinner.Success()
 
-	return wg.Wait()
+		return nil
+	}, nil)
+
+	return printer.Results(), err
 }
 
-func (sq *Squadron) Down(ctx context.Context, helmArgs []string, parallel int) error {
-	wg, ctx := errgroup.WithContext(ctx)
-	wg.SetLimit(parallel)
-
-	printer := ptermx.MustNewMultiPrinter()
-	defer printer.Stop()
+func (sq *Squadron) List(ctx context.Context) ([]UnitInfo, error) {
+	var ret []UnitInfo
 
-	_ = sq.Config().Squadrons.Iterate(ctx, func(ctx context.Context, key string, value config.Map[*config.Unit]) error {
+	err := sq.Config().Squadrons.Iterate(ctx, func(ctx context.Context, key string, value config.Map[*config.Unit]) error {
 		return value.Iterate(ctx, func(ctx context.Context, k string, v *config.Unit) error {
-			wg.Go(func() error {
-				spinner := printer.NewSpinner(fmt.Sprintf("🗑️ | %s/%s", key, k))
-				spinner.Start()
-				spinner.Play()
+			item := UnitInfo{
+				Squadron:  key,
+				Unit:      k,
+				Name:      sq.getReleaseName(key, k, v),
+				Tags:      v.Tags.SortedStrings(),
+				Priority:  v.Priority,
+				DependsOn: v.DependsOn,
+			}
 
-				ctx := ptermx.ContextWithSpinner(ctx, spinner)
-				if err := ctx.Err(); err != nil {
-					spinner.Warning(err.Error())
-					return err
+			if v.Chart.Name != "" {
+				item.Chart = v.Chart.String()
+			}
+
+			for _, name := range v.BuildNames() {
+				if item.Builds == nil {
+					item.Builds = map[string][]string{}
 				}
 
-				name := sq.getReleaseName(key, k, v)
+				item.Builds[name] = v.Builds[name].Tag
 
-				namespace, err := sq.Namespace(ctx, key, k, v)
-				if err != nil {
-					return err
+				if len(v.Builds[name].Dependencies) > 0 {
+					if item.BuildDependencies == nil {
+						item.BuildDependencies = map[string][]string{}
+					}
+
+					item.BuildDependencies[name] = v.Builds[name].Dependencies
 				}
+			}
 
-				if out, err := util.NewHelmCommand().Args("uninstall", name).
-					Args("--namespace", namespace).
-					Args(helmArgs...).
-					Run(ctx); errors.Is(err, context.Canceled) {
-					spinner.Fail(err.Error())
-					return err
-				} else if err != nil &&
-					strings.TrimSpace(out) != fmt.Sprintf("Error: uninstall: Release not loaded: %s: release: not found", name) {
-					spinner.Fail(out)
-					return err
+			for _, name := range v.BakeNames() {
+				if item.Bakes == nil {
+					item.Bakes = map[string][]string{}
 				}
 
-				spinner.Success()
+				item.Bakes[name] = v.Bakes[name].Tags
+			}
 
-				return nil
-			})
+			ret = append(ret, item)
 
 			return nil
 		})
 	})
+	if err != nil {
+		return nil, err
+	}
 
-	return wg.Wait()
+	return ret, nil
 }
 
 func (sq *Squadron) RenderSchema(ctx context.Context, baseSchema string) (string, error) {
@@ -724,31 +758,34 @@ func (sq *Squadron) RenderSchema(ctx context.Context, baseSchema string) (string
 	return js.PrettyString()
 }
 
-func (sq *Squadron) Diff(ctx context.Context, helmArgs []string, parallel int) (string, error) {
+func (sq *Squadron) Diff(ctx context.Context, opts HelmOptions, maskSecrets bool, parallel int) ([]UnitDiff, error) {
 	var (
 		m   sync.Mutex
-		ret bytes.Buffer
+		ret []UnitDiff
 	)
 
-	write := func(b []byte) error {
+	backend, err := opts.backend()
+	if err != nil {
+		return nil, err
+	}
+
+	write := func(v UnitDiff) {
 		m.Lock()
 		defer m.Unlock()
 
-		_, err := ret.Write(b)
-
-		return err
+		ret = append(ret, v)
 	}
 
 	wg, ctx := errgroup.WithContext(ctx)
 	wg.SetLimit(parallel)
 
-	printer := ptermx.MustNewMultiPrinter()
+	printer := sq.newProgress(OperationDiff)
 	defer printer.Stop()
 
 	_ = sq.Config().Squadrons.Iterate(ctx, func(ctx context.Context, key string, value config.Map[*config.Unit]) error {
 		return value.Iterate(ctx, func(ctx context.Context, k string, v *config.Unit) error {
 			wg.Go(func() error {
-				spinner := printer.NewSpinner(fmt.Sprintf("🔍 | %s/%s", key, k))
+				spinner := printer.NewTask(key, k, "", fmt.Sprintf("🔍 | %s/%s", key, k))
 				spinner.Start()
 				spinner.Play()
 
@@ -765,73 +802,53 @@ func (sq *Squadron) Diff(ctx context.Context, helmArgs []string, parallel int) (
 					return err
 				}
 
-				valueBytes, err := v.ValuesYAML(sq.c.Global)
+				release, err := sq.release(key, k, v, name, namespace)
 				if err != nil {
+					spinner.Fail(err.Error())
 					return err
 				}
 
-				manifest, err := exec.CommandContext(ctx, "helm", "get", "manifest", name, "--namespace", namespace).CombinedOutput()
-				if err != nil && string(bytes.TrimSpace(manifest)) != errHelmReleaseNotFound {
-					spinner.Fail(string(manifest))
+				manifest, err := backend.Manifest(ctx, name, namespace)
+				if err != nil && !errors.Is(err, helm.ErrReleaseNotFound) {
+					spinner.Fail(err.Error())
 					return err
 				}
 
-				cmd := exec.CommandContext(ctx, "helm", "upgrade", name,
-					"--install",
-					"--namespace", namespace,
-					"--set", "global.foomo.squadron.name="+key,
-					"--set", "global.foomo.squadron.unit="+k,
-					"--hide-notes",
-					"--values", "-",
-					"--dry-run",
-				)
-				cmd.Args = append(cmd.Args, v.PostRendererArgs()...)
-				cmd.Stdin = bytes.NewReader(valueBytes)
-
-				if after, ok := strings.CutPrefix(v.Chart.Repository, "file://"); ok {
-					cmd.Args = append(cmd.Args, path.Clean(after))
-				} else {
-					cmd.Args = append(cmd.Args, v.Chart.Name)
-					if v.Chart.Repository != "" {
-						cmd.Args = append(cmd.Args, "--repo", v.Chart.Repository)
-					}
-
-					if v.Chart.Version != "" {
-						cmd.Args = append(cmd.Args, "--version", v.Chart.Version)
-					}
-				}
-
-				cmd.Args = append(cmd.Args, helmArgs...)
-
-				out, err := cmd.CombinedOutput()
+				out, err := backend.DryRun(ctx, release)
 				if err != nil {
-					spinner.Fail(string(out))
+					spinner.Fail(err.Error())
 					return err
 				}
 
-				yamls1, err := yamldiff.Load(string(manifest))
+				changes, err := diff.Manifests(manifest, out, namespace, maskSecrets)
 				if err != nil {
-					spinner.Fail(string(manifest))
-					return errors.Wrap(err, "failed to load yaml diff")
+					spinner.Fail(err.Error())
+					return err
 				}
 
-				outStr := strings.Split(string(out), "\n")
-
-				yamls2, err := yamldiff.Load(strings.Join(outStr[10:], "\n"))
-				if err != nil {
-					spinner.Fail(string(out))
-					return errors.Wrap(err, "failed to load yaml diff")
+				item := UnitDiff{
+					Squadron:  key,
+					Unit:      k,
+					Name:      name,
+					Namespace: namespace,
 				}
 
 				var res strings.Builder
-				for _, diff := range yamldiff.Do(yamls1, yamls2) {
-					res.WriteString(diff.Dump() + "  ---\n")
+				for _, change := range changes {
+					res.WriteString(change.Diff)
+
+					item.Resources = append(item.Resources, ResourceDiff{
+						Kind:      change.Kind,
+						Namespace: change.Namespace,
+						Name:      change.Name,
+						Action:    change.Action,
+						Diff:      change.Diff,
+					})
 				}
 
-				if err := write([]byte(res.String())); err != nil {
-					spinner.Fail(res.String())
-					return err
-				}
+				item.Diff = res.String()
+
+				write(item)
 
 				spinner.Success()
 
@@ -843,52 +860,42 @@ func (sq *Squadron) Diff(ctx context.Context, helmArgs []string, parallel int) (
 	})
 
 	if err := wg.Wait(); err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return ret.String(), nil
+	slices.SortFunc(ret, func(a, b UnitDiff) int {
+		return strings.Compare(a.Squadron+"/"+a.Unit, b.Squadron+"/"+b.Unit)
+	})
+
+	return ret, nil
 }
 
-func (sq *Squadron) Status(ctx context.Context, helmArgs []string, parallel int) error {
-	var m sync.Mutex
+func (sq *Squadron) Status(ctx context.Context, opts HelmOptions, parallel int) ([]UnitStatus, error) {
+	var (
+		m   sync.Mutex
+		ret []UnitStatus
+	)
 
-	tbd := pterm.TableData{
-		{"Name", "Revision", "Status", "User", "Branch", "Commit", "Squadron", "Last deployed", "Notes"},
+	backend, err := opts.backend()
+	if err != nil {
+		return nil, err
 	}
-	write := func(b []string) {
+
+	write := func(v UnitStatus) {
 		m.Lock()
 		defer m.Unlock()
 
-		tbd = append(tbd, b)
-	}
-
-	type statusType struct {
-		Name      string `json:"name"`
-		Version   int    `json:"version"`
-		Namespace string `json:"namespace"`
-		Info      struct {
-			Status        string `json:"status"`
-			FirstDeployed string `json:"first_deployed"`
-			Deleted       string `json:"deleted"`
-			LastDeployed  string `json:"last_deployed"`
-			Description   string `json:"description"`
-		} `json:"info"`
-		user     string `json:"-"`
-		branch   string `json:"-"`
-		commit   string `json:"-"`
-		squadron string `json:"-"`
+		ret = append(ret, v)
 	}
 
 	wg, ctx := errgroup.WithContext(ctx)
 	wg.SetLimit(parallel)
 
-	printer := ptermx.MustNewMultiPrinter()
+	printer := sq.newProgress(OperationStatus)
 	defer printer.Stop()
 
 	_ = sq.Config().Squadrons.Iterate(ctx, func(ctx context.Context, key string, value config.Map[*config.Unit]) error {
 		return value.Iterate(ctx, func(ctx context.Context, k string, v *config.Unit) error {
-			var status statusType
-
 			name := sq.getReleaseName(key, k, v)
 
 			namespace, err := sq.Namespace(ctx, key, k, v)
@@ -897,7 +904,7 @@ func (sq *Squadron) Status(ctx context.Context, helmArgs []string, parallel int)
 			}
 
 			wg.Go(func() error {
-				spinner := printer.NewSpinner(fmt.Sprintf("📄 | %s/%s", key, k))
+				spinner := printer.NewTask(key, k, "", fmt.Sprintf("📄 | %s/%s", key, k))
 				spinner.Start()
 				spinner.Play()
 
@@ -907,57 +914,40 @@ func (sq *Squadron) Status(ctx context.Context, helmArgs []string, parallel int)
 					return err
 				}
 
-				stdErr := bytes.NewBuffer([]byte{})
-				out, err := util.NewHelmCommand().Args("status", name).
-					Stderr(stdErr).
-					Args("--namespace", namespace, "--output", "json", "--show-desc").
-					Args(helmArgs...).Run(ctx)
+				item := UnitStatus{
+					Squadron:  key,
+					Unit:      k,
+					Name:      name,
+					Namespace: namespace,
+				}
 
-				if errors.Is(err, context.Canceled) {
-					spinner.Fail(err.Error())
-					return err
-				} else if err != nil && string(bytes.TrimSpace(stdErr.Bytes())) == errHelmReleaseNotFound {
-					tbd = append(tbd, []string{name, "0", "not installed", "", ""})
+				status, err := backend.Status(ctx, name, namespace)
+				if errors.Is(err, helm.ErrReleaseNotFound) {
+					item.Status = "not installed"
+					write(item)
+					spinner.Success()
+
+					return nil
 				} else if err != nil {
-					spinner.Fail(stdErr.String())
+					spinner.Fail(err.Error())
 					return err
 				}
 
-				if err := json.Unmarshal([]byte(out), &status); err != nil {
-					spinner.Fail(out)
-					return errors.Errorf("failed to retrieve status: %s/%s", key, k)
-				}
-
-				var (
-					notes             []string
-					statusDescription Status
-				)
-
-				if err := json.Unmarshal([]byte(status.Info.Description), &statusDescription); err == nil {
-					status.user = statusDescription.User
-					status.branch = statusDescription.Branch
-					status.commit = statusDescription.Commit
-					status.squadron = statusDescription.Squadron
+				var statusDescription Status
+				if err := json.Unmarshal([]byte(status.Description), &statusDescription); err == nil {
+					item.User = statusDescription.User
+					item.Branch = statusDescription.Branch
+					item.Commit = statusDescription.Commit
+					item.Version = statusDescription.Squadron
 				} else {
-					notes = append(notes, status.Info.Description)
+					item.Notes = status.Description
 				}
 
-				lastDeployed := status.Info.LastDeployed
-				if t, err := time.Parse(time.RFC3339, status.Info.LastDeployed); err == nil {
-					lastDeployed = t.Format(time.RFC822)
-				}
+				item.Revision = status.Revision
+				item.Status = status.Status
+				item.LastDeployed = status.LastDeployed
 
-				write([]string{
-					status.Name,
-					fmt.Sprintf("%d", status.Version),
-					status.Info.Status,
-					status.user,
-					status.branch,
-					status.commit,
-					status.squadron,
-					lastDeployed,
-					strings.Join(notes, "\n"),
-				})
+				write(item)
 
 				spinner.Success()
 
@@ -969,83 +959,85 @@ func (sq *Squadron) Status(ctx context.Context, helmArgs []string, parallel int)
 	})
 
 	if err := wg.Wait(); err != nil {
-		return err
-	}
-
-	printer.Stop()
-
-	out, err := pterm.DefaultTable.WithHasHeader().WithData(tbd).Srender()
-	if err != nil {
-		return err
+		return nil, err
 	}
 
-	pterm.Println(out)
+	slices.SortFunc(ret, func(a, b UnitStatus) int {
+		return strings.Compare(a.Squadron+"/"+a.Unit, b.Squadron+"/"+b.Unit)
+	})
 
-	return nil
+	return ret, nil
 }
 
-func (sq *Squadron) Rollback(ctx context.Context, revision string, helmArgs []string, parallel int) error {
-	if revision != "" {
-		helmArgs = append([]string{revision}, helmArgs...)
+func (sq *Squadron) Rollback(ctx context.Context, revision string, opts HelmOptions, parallel int) ([]Result, error) {
+	backend, err := opts.backend()
+	if err != nil {
+		return nil, err
 	}
 
-	wg, ctx := errgroup.WithContext(ctx)
-	wg.SetLimit(parallel)
+	waves, err := sq.UnitWaves(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	printer := ptermx.MustNewMultiPrinter()
+	printer := sq.newProgress(OperationRollback)
 	defer printer.Stop()
 
-	_ = sq.Config().Squadrons.Iterate(ctx, func(ctx context.Context, key string, value config.Map[*config.Unit]) error {
-		return value.Iterate(ctx, func(ctx context.Context, k string, v *config.Unit) error {
-			name := sq.getReleaseName(key, k, v)
+	err = sq.iterateWaves(ctx, waves, parallel, func(ctx context.Context, key, k string, v *config.Unit) error {
+		spinner := printer.NewTask(key, k, "", fmt.Sprintf("♻️ | %s/%s", key, k))
+		spinner.Start()
+		spinner.Play()
 
-			namespace, err := sq.Namespace(ctx, key, k, v)
-			if err != nil {
-				return err
-			}
+		ctx = ptermx.ContextWithSpinner(ctx, spinner)
+		if err := ctx.Err(); err != nil {
+			spinner.Warning(err.Error())
+			return err
+		}
 
-			wg.Go(func() error {
-				spinner := printer.NewSpinner(fmt.Sprintf("♻️ | %s/%s", key, k))
-				spinner.Start()
-				spinner.Play()
+		if err := sq.rollback(ctx, backend, key, k, v, revision); err != nil && !errors.Is(err, helm.ErrReleaseNotFound) {
+			spinner.Fail(err.Error())
+			return err
+		}
 
-				ctx := ptermx.ContextWithSpinner(ctx, spinner)
-				if err := ctx.Err(); err != nil {
-					spinner.Warning(err.Error())
-					return err
-				}
+		spinner.Success()
 
-				stdErr := bytes.NewBuffer([]byte{})
+		return nil
+	}, nil)
 
-				out, err := util.NewHelmCommand().Args("rollback", name).
-					Stderr(stdErr).
-					Args(helmArgs...).
-					Args("--namespace", namespace).
-					Run(ctx)
-				if errors.Is(err, context.Canceled) {
-					spinner.Fail(err.Error())
-					return err
-				} else if err != nil &&
-					string(bytes.TrimSpace(stdErr.Bytes())) != fmt.Sprintf("Error: uninstall: Release not loaded: %s: release: not found", name) {
-					spinner.Fail(stdErr.String())
-					return err
-				}
+	return printer.Results(), err
+}
 
-				spinner.Success(out)
+// Verify waits for the rollout of the workloads of each unit within its verify timeout and rolls
+// back failed units to their previous revision
+func (sq *Squadron) Verify(ctx context.Context, opts HelmOptions, parallel int) ([]UnitVerification, error) {
+	backend, err := opts.backend()
+	if err != nil {
+		return nil, err
+	}
 
-				return nil
-			})
+	waves, err := sq.UnitWaves(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-			return nil
-		})
-	})
+	printer := sq.newProgress(OperationVerify)
+	defer printer.Stop()
 
-	return wg.Wait()
+	verifications := &unitVerifications{}
+
+	err = sq.iterateWaves(ctx, waves, parallel, sq.verifyTask(printer, backend, verifications), nil)
+
+	return verifications.sorted(), err
 }
 
 // UpdateLocalDependencies work around
 // https://stackoverflow.com/questions/59210148/error-found-in-chart-yaml-but-missing-in-charts-directory-mysql
 func (sq *Squadron) UpdateLocalDependencies(ctx context.Context, parallel int) error {
+	if sq.offline {
+		pterm.Debug.Println("skipping local dependency update in offline mode")
+		return nil
+	}
+
 	// collect unique entrie
 	repositories := map[string]struct{}{}
 
@@ -1062,6 +1054,11 @@ func (sq *Squadron) UpdateLocalDependencies(ctx context.Context, parallel int) e
 		return err
 	}
 
+	backend, err := helm.NewReleaseBackend(helm.Options{})
+	if err != nil {
+		return err
+	}
+
 	wg, ctx := errgroup.WithContext(ctx)
 	wg.SetLimit(parallel)
 
@@ -1069,162 +1066,53 @@ func (sq *Squadron) UpdateLocalDependencies(ctx context.Context, parallel int) e
 		wg.Go(func() error {
 			pterm.Debug.Printfln("running helm dependency update for %s", repository)
 
-			if out, err := util.NewHelmCommand().
-				Cwd(path.Clean(strings.TrimPrefix(repository, "file://"))).
-				Args("dependency", "update", "--skip-refresh", "--debug").
-				Run(ctx); err != nil {
-				return errors.Wrap(err, out)
-			} else {
-				pterm.Debug.Println(out)
-			}
-
-			return nil
+			return backend.DependencyUpdate(ctx, path.Clean(strings.TrimPrefix(repository, "file://")))
 		})
 	}
 
 	return wg.Wait()
 }
 
-func (sq *Squadron) Up(ctx context.Context, helmArgs []string, status Status, parallel int) error {
-	description, err := json.Marshal(status)
-	if err != nil {
-		return err
-	}
-
-	wg, ctx := errgroup.WithContext(ctx)
-	wg.SetLimit(parallel)
-
-	printer := ptermx.MustNewMultiPrinter()
-	defer printer.Stop()
-
-	type one struct {
-		spinner  ptermx.Spinner
-		squadron string
-		unit     string
-		item     *config.Unit
-	}
-
-	var all []one
-
-	_ = sq.Config().Squadrons.Iterate(ctx, func(ctx context.Context, key string, value config.Map[*config.Unit]) error {
-		return value.Iterate(ctx, func(ctx context.Context, k string, v *config.Unit) error {
-			var priority string
-			if v.Priority != 0 {
-				priority = fmt.Sprintf(" ☝︎ %d", v.Priority)
-			}
-
-			spinner := printer.NewSpinner(fmt.Sprintf("🚀 | %s/%s", key, k) + priority)
-			all = append(all, one{
-				spinner:  spinner,
-				squadron: key,
-				unit:     k,
-				item:     v,
-			})
-			spinner.Start()
-
-			return nil
-		})
-	})
-
-	sort.Slice(all, func(i, j int) bool {
-		return all[i].item.Priority > all[j].item.Priority
-	})
-
-	for _, a := range all {
-		wg.Go(func() error {
-			a.spinner.Play()
-
-			ctx := ptermx.ContextWithSpinner(ctx, a.spinner)
-			if err := ctx.Err(); err != nil {
-				a.spinner.Warning(err.Error())
-				return err
-			}
-
-			name := sq.getReleaseName(a.squadron, a.unit, a.item)
-
-			namespace, err := sq.Namespace(ctx, a.squadron, a.unit, a.item)
-			if err != nil {
-				a.spinner.Fail(err.Error())
-				return err
-			}
-
-			valueBytes, err := a.item.ValuesYAML(sq.c.Global)
-			if err != nil {
-				a.spinner.Fail(err.Error())
-				return err
-			}
-
-			// install chart
-			cmd := util.NewHelmCommand().
-				Stdin(bytes.NewReader(valueBytes)).
-				Args("upgrade", name, "--install").
-				Args("--set", "global.foomo.squadron.name="+a.squadron).
-				Args("--set", "global.foomo.squadron.unit="+a.unit).
-				Args("--description", string(description)).
-				Args("--namespace", namespace).
-				Args("--dependency-update").
-				Args(a.item.PostRendererArgs()...).
-				Args("--install").
-				Args("--values", "-").
-				Args(helmArgs...)
-
-			if after, ok := strings.CutPrefix(a.item.Chart.Repository, "file://"); ok {
-				cmd.Args(path.Clean(after))
-			} else {
-				cmd.Args(a.item.Chart.Name)
-
-				if a.item.Chart.Repository != "" {
-					cmd.Args("--repo", a.item.Chart.Repository)
-				}
-
-				if a.item.Chart.Version != "" {
-					cmd.Args("--version", a.item.Chart.Version)
-				}
-			}
-
-			out, err := cmd.Run(ctx)
-			if errors.Is(err, context.Canceled) {
-				a.spinner.Fail(err.Error())
-				return err
-			} else if err != nil {
-				a.spinner.Fail(out)
-				return err
-			}
-
-			a.spinner.Success()
+func (sq *Squadron) Up(ctx context.Context, opts HelmOptions, status Status, parallel int) ([]Result, error) {
+	results, _, err := sq.up(ctx, opts, status, parallel, false)
 
-			return nil
-		})
-	}
+	return results, err
+}
 
-	return wg.Wait()
+// UpAndVerify installs the units like Up but verifies each dependency wave like Verify before
+// installing the next one, so that no unit is rolled out on top of a failed dependency
+func (sq *Squadron) UpAndVerify(ctx context.Context, opts HelmOptions, status Status, parallel int) ([]Result, []UnitVerification, error) {
+	return sq.up(ctx, opts, status, parallel, true)
 }
 
-func (sq *Squadron) Template(ctx context.Context, helmArgs []string, parallel int) (string, error) {
+func (sq *Squadron) Template(ctx context.Context, opts HelmOptions, parallel int) (UnitTemplates, error) {
 	var (
 		m   sync.Mutex
-		ret bytes.Buffer
+		ret UnitTemplates
 	)
 
-	write := func(b []byte) error {
+	backend, err := opts.backend()
+	if err != nil {
+		return nil, err
+	}
+
+	write := func(v UnitTemplate) {
 		m.Lock()
 		defer m.Unlock()
 
-		_, err := ret.Write(b)
-
-		return err
+		ret = append(ret, v)
 	}
 
 	wg, ctx := errgroup.WithContext(ctx)
 	wg.SetLimit(parallel)
 
-	printer := ptermx.MustNewMultiPrinter()
+	printer := sq.newProgress(OperationTemplate)
 	defer printer.Stop()
 
 	_ = sq.Config().Squadrons.Iterate(ctx, func(ctx context.Context, key string, value config.Map[*config.Unit]) error {
 		return value.Iterate(ctx, func(ctx context.Context, k string, v *config.Unit) error {
 			wg.Go(func() error {
-				spinner := printer.NewSpinner(fmt.Sprintf("🧾 | %s/%s", key, k))
+				spinner := printer.NewTask(key, k, "", fmt.Sprintf("🧾 | %s/%s", key, k))
 				spinner.Start()
 				spinner.Play()
 
@@ -1242,17 +1130,26 @@ func (sq *Squadron) Template(ctx context.Context, helmArgs []string, parallel in
 					return errors.Errorf("failed to retrieve namsspace: %s/%s", key, k)
 				}
 
-				out, err := v.Template(ctx, name, key, k, namespace, sq.c.Global, helmArgs)
+				release, err := sq.release(key, k, v, name, namespace)
 				if err != nil {
-					spinner.Fail(string(out))
+					spinner.Fail(err.Error())
 					return err
 				}
 
-				if err := write(out); err != nil {
-					spinner.Fail(string(out))
+				out, err := backend.Template(ctx, release)
+				if err != nil {
+					spinner.Fail(err.Error())
 					return err
 				}
 
+				write(UnitTemplate{
+					Squadron:  key,
+					Unit:      k,
+					Name:      name,
+					Namespace: namespace,
+					Manifest:  out,
+				})
+
 				spinner.Success()
 
 				return nil
@@ -1263,10 +1160,687 @@ func (sq *Squadron) Template(ctx context.Context, helmArgs []string, parallel in
 	})
 
 	if err := wg.Wait(); err != nil {
-		return "", err
+		return nil, err
+	}
+
+	slices.SortFunc(ret, func(a, b UnitTemplate) int {
+		return strings.Compare(a.Squadron+"/"+a.Unit, b.Squadron+"/"+b.Unit)
+	})
+
+	return ret, nil
+}
+
+// up installs the units wave by wave, verifying each wave before starting the next one if enabled
+func (sq *Squadron) up(ctx context.Context, opts HelmOptions, status Status, parallel int, verify bool) ([]Result, []UnitVerification, error) {
+	description, err := json.Marshal(status)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	backend, err := opts.backend()
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return ret.String(), nil
+	waves, err := sq.UnitWaves(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	printer := sq.newProgress(OperationUp)
+	defer printer.Stop()
+
+	type one struct {
+		spinner  ptermx.Spinner
+		squadron string
+		unit     string
+		item     *config.Unit
+	}
+
+	all := map[string]one{}
+
+	for _, wave := range waves {
+		for _, id := range sq.sortByPriority(wave) {
+			key, k, _ := strings.Cut(id, "/")
+			v := sq.c.Squadrons[key][k]
+
+			var priority string
+			if v.Priority != 0 {
+				priority = fmt.Sprintf(" ☝︎ %d", v.Priority)
+			}
+
+			spinner := printer.NewTask(key, k, "", fmt.Sprintf("🚀 | %s/%s", key, k)+priority)
+			all[id] = one{
+				spinner:  spinner,
+				squadron: key,
+				unit:     k,
+				item:     v,
+			}
+			spinner.Start()
+		}
+	}
+
+	verifications := &unitVerifications{}
+
+	// verify each wave before installing the units depending on it
+	var after func(ctx context.Context, wave []string) error
+	if verify {
+		after = func(ctx context.Context, wave []string) error {
+			if err := sq.iterateWave(ctx, wave, parallel, sq.verifyTask(printer, backend, verifications)); err != nil {
+				return err
+			}
+
+			if failed := verifications.failed(wave); failed > 0 {
+				return errors.Errorf("%d of %d units failed verification", failed, len(wave))
+			}
+
+			return nil
+		}
+	}
+
+	err = sq.iterateWaves(ctx, waves, parallel, func(ctx context.Context, key, k string, v *config.Unit) error {
+		a := all[key+"/"+k]
+		a.spinner.Play()
+
+		ctx = ptermx.ContextWithSpinner(ctx, a.spinner)
+		if err := ctx.Err(); err != nil {
+			a.spinner.Warning(err.Error())
+			return err
+		}
+
+		name := sq.getReleaseName(a.squadron, a.unit, a.item)
+
+		namespace, err := sq.Namespace(ctx, a.squadron, a.unit, a.item)
+		if err != nil {
+			a.spinner.Fail(err.Error())
+			return err
+		}
+
+		release, err := sq.release(a.squadron, a.unit, a.item, name, namespace)
+		if err != nil {
+			a.spinner.Fail(err.Error())
+			return err
+		}
+
+		release.Description = string(description)
+
+		if err := sq.runHooks(ctx, config.HookPreUp, a.squadron, a.unit, a.item); err != nil {
+			a.spinner.Fail(err.Error())
+			return err
+		}
+
+		if err := backend.Upgrade(ctx, release); err != nil {
+			a.spinner.Fail(err.Error())
+			return err
+		}
+
+		if err := sq.runHooks(ctx, config.HookPostUp, a.squadron, a.unit, a.item); err != nil {
+			a.spinner.Fail(err.Error())
+			return err
+		}
+
+		a.spinner.Success()
+
+		return nil
+	}, after)
+
+	return printer.Results(), verifications.sorted(), err
+}
+
+// verifyTask returns the handler verifying a unit, reporting to the given progress and collecting
+// the results
+func (sq *Squadron) verifyTask(printer *progress, backend helm.ReleaseBackend, verifications *unitVerifications) func(ctx context.Context, key, k string, v *config.Unit) error {
+	return func(ctx context.Context, key, k string, v *config.Unit) error {
+		spinner := printer.newTask(OperationVerify, key, k, "", fmt.Sprintf("🩺 | %s/%s", key, k))
+		spinner.Start()
+		spinner.Play()
+
+		ctx = ptermx.ContextWithSpinner(ctx, spinner)
+		if err := ctx.Err(); err != nil {
+			spinner.Warning(err.Error())
+			return err
+		}
+
+		item, err := sq.verify(ctx, backend, key, k, v)
+		if err != nil {
+			spinner.Fail(err.Error())
+			return err
+		}
+
+		verifications.add(item)
+
+		switch item.Status {
+		case VerificationVerified:
+			spinner.Success()
+		case VerificationReverted:
+			spinner.Warning("reverted: " + item.Error)
+		default:
+			spinner.Fail(item.Error)
+		}
+
+		return nil
+	}
+}
+
+// iterateWaves runs the handler for all units wave by wave, running the units of each wave in
+// parallel and calling `after` if given with each completed wave before starting the next one
+func (sq *Squadron) iterateWaves(ctx context.Context, waves [][]string, parallel int, handler func(ctx context.Context, key, k string, v *config.Unit) error, after func(ctx context.Context, wave []string) error) error {
+	for _, wave := range waves {
+		if err := sq.iterateWave(ctx, wave, parallel, handler); err != nil {
+			return err
+		}
+
+		if after != nil {
+			if err := after(ctx, wave); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// iterateWave runs the handler for the units of the wave in parallel
+func (sq *Squadron) iterateWave(ctx context.Context, wave []string, parallel int, handler func(ctx context.Context, key, k string, v *config.Unit) error) error {
+	wg, ctx := errgroup.WithContext(ctx)
+	wg.SetLimit(parallel)
+
+	for _, id := range sq.sortByPriority(wave) {
+		key, k, _ := strings.Cut(id, "/")
+		v := sq.c.Squadrons[key][k]
+
+		wg.Go(func() error {
+			return handler(ctx, key, k, v)
+		})
+	}
+
+	return wg.Wait()
+}
+
+// runHooks runs the global and then the unit commands of the given hook
+func (sq *Squadron) runHooks(ctx context.Context, hook, key, k string, v *config.Unit) error {
+	if len(sq.c.Hooks.Commands(hook)) == 0 && len(v.Hooks.Commands(hook)) == 0 {
+		return nil
+	}
+
+	namespace, err := sq.Namespace(ctx, key, k, v)
+	if err != nil {
+		return err
+	}
+
+	data := config.HookData{
+		Squadron:  key,
+		Unit:      k,
+		Namespace: namespace,
+		Release:   sq.getReleaseName(key, k, v),
+	}
+
+	pterm.Debug.Printfln("running %s hooks for %s/%s", hook, key, k)
+
+	if err := sq.c.Hooks.Run(ctx, hook, data); err != nil {
+		return err
+	}
+
+	return v.Hooks.Run(ctx, hook, data)
+}
+
+// buildGraph returns the validated build graph
+func (sq *Squadron) buildGraph(ctx context.Context) (*dag.Graph, error) {
+	graph := sq.c.BuildGraph(ctx)
+	if err := graph.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid build dependencies")
+	}
+
+	return graph, nil
+}
+
+// build runs the global builds and optionally the unit builds of the graph
+func (sq *Squadron) build(ctx context.Context, graph *dag.Graph, buildArgs []string, parallel int, units bool) ([]Result, error) {
+	printer := sq.newProgress(OperationBuild)
+	defer printer.Stop()
+
+	now := time.Now()
+
+	gitInfo, err := git.GetInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := sq.buildState(graph)
+	if err != nil {
+		return nil, err
+	}
+
+	// capture the image digests from the build metadata
+	tmp, err := os.MkdirTemp("", "squadron-build-")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create build metadata dir")
+	}
+	defer os.RemoveAll(tmp)
+
+	hashes := newBuildHashes(sq, graph)
+	tasks := map[string]func(ctx context.Context) error{}
+
+	for _, id := range graph.Nodes() {
+		var (
+			spinner ptermx.Spinner
+			unit    *config.Unit
+		)
+
+		key, k, name, item := sq.graphBuild(id)
+
+		switch {
+		case key == "":
+			spinner = printer.NewTask("", "", name, fmt.Sprintf("💾 | %s %s", name, item.Tag))
+		case units:
+			unit = sq.c.Squadrons[key][k]
+			item.BuildArg = append(slices.Clone(item.BuildArg),
+				"SQUADRON_NAME="+key,
+				"SQUADRON_UNIT_NAME="+k,
+			)
+			item.Label = append(slices.Clone(item.Label),
+				"org.opencontainers.image.source="+gitInfo.URL,
+				"org.opencontainers.image.version="+gitInfo.Ref,
+				"org.opencontainers.image.created="+now.Format(time.RFC3339),
+				"org.opencontainers.image.revision="+gitInfo.Commit,
+			)
+			spinner = printer.NewTask(key, k, name, fmt.Sprintf("📦 | %s/%s.%s %s", key, k, name, item.Tag))
+		default:
+			continue
+		}
+
+		metadata := item.MetadataFile
+		if metadata == "" {
+			metadata = filepath.Join(tmp, strings.NewReplacer("/", "-", ".", "-").Replace(id)+".json")
+			item.MetadataFile = metadata
+		} else if !filepath.IsAbs(metadata) {
+			metadata = filepath.Join(item.Context, metadata)
+		}
+
+		spinner.Start()
+
+		tasks[id] = func(ctx context.Context) error {
+			spinner.Play()
+
+			if err := ctx.Err(); err != nil {
+				spinner.Warning(err.Error())
+				return err
+			}
+
+			var hash string
+
+			if item.SkipUnchanged && len(item.Tag) > 0 {
+				var err error
+				if hash, err = hashes.get(id); err != nil {
+					spinner.Fail(err.Error())
+					return err
+				}
+
+				if state.unchanged(ctx, item.Tag[0], hash) {
+					sq.setDigest(state.digest(ctx, item.Tag[0], hash), item.Tag...)
+					spinner.Info("unchanged, skipping build")
+
+					return nil
+				}
+
+				item.Label = append(slices.Clone(item.Label), BuildHashLabel+"="+hash)
+			}
+
+			ctx = ptermx.ContextWithSpinner(ctx, spinner)
+
+			// run the hooks of the unit around each of its builds
+			if unit != nil {
+				if err := sq.runHooks(ctx, config.HookPreBuild, key, k, unit); err != nil {
+					spinner.Fail(err.Error())
+					return err
+				}
+			}
+
+			if out, err := item.Build(ctx, key, k, buildArgs); errors.Is(ctx.Err(), context.Canceled) {
+				spinner.Warning(ctx.Err().Error())
+				return ctx.Err()
+			} else if err != nil {
+				spinner.Fail(out)
+				return err
+			}
+
+			digest, err := readBuildMetadata(metadata)
+			if err != nil {
+				pterm.Debug.Println(err.Error())
+			}
+
+			sq.setDigest(digest, item.Tag...)
+
+			if hash != "" {
+				state.set(item.Tag[0], hash, digest)
+			}
+
+			if unit != nil {
+				if err := sq.runHooks(ctx, config.HookPostBuild, key, k, unit); err != nil {
+					spinner.Fail(err.Error())
+					return err
+				}
+			}
+
+			spinner.Success()
+
+			return nil
+		}
+	}
+
+	err = graph.Run(ctx, parallel, func(ctx context.Context, id string) error {
+		if task, ok := tasks[id]; ok {
+			return task(ctx)
+		}
+
+		return nil
+	})
+
+	printer.Stop()
+
+	if err := state.save(); err != nil {
+		sq.warn(OperationBuild, err.Error())
+	}
+
+	return printer.Results(), err
+}
+
+// buildState returns the state of previous builds if any build of the graph skips unchanged builds
+func (sq *Squadron) buildState(graph *dag.Graph) (*buildState, error) {
+	for _, id := range graph.Nodes() {
+		if _, _, _, build := sq.graphBuild(id); build.SkipUnchanged {
+			filename, err := BuildStateFilename()
+			if err != nil {
+				return nil, err
+			}
+
+			return loadBuildState(filename)
+		}
+	}
+
+	return &buildState{images: map[string]buildStateImage{}}, nil
+}
+
+// renderConfig renders the config, exposing the images of the global builds and the unit's own
+// builds and bakes as `.Builds`
+func (sq *Squadron) renderConfig(ctx context.Context) error {
+	done := sq.step(OperationRender, "📗 | rendering config")
+
+	// share git metadata between all templates of the render
+	ctx = templatex.ContextWithGit(ctx)
+
+	var data map[string]any
+	if err := yaml.Unmarshal([]byte(sq.config), &data); err != nil {
+		return errors.Wrap(err, "failed to render config")
+	}
+
+	// resolve vars, globals and referenced values in dependency order
+	tv, refs, err := sq.resolveConfig(ctx, data)
+	if err != nil {
+		return err
+	}
+
+	c := sq.c
+	c.Vars, _ = data["vars"].(map[string]any)
+	c.Global, _ = data["global"].(map[string]any)
+	c.Squadrons = nil
+
+	// render all but the units against the resolved values
+	var ret config.Config
+	if err := sq.renderTemplate(ctx, c, sq.withBuilds(tv, sq.buildImages(c.Builds, nil)), refs, &ret); err != nil {
+		return err
+	}
+
+	global := sq.buildImages(ret.Builds, nil)
+
+	if err := sq.c.Squadrons.Iterate(ctx, func(ctx context.Context, key string, value config.Map[*config.Unit]) error {
+		return value.Iterate(ctx, func(ctx context.Context, k string, v *config.Unit) error {
+			// render the builds and bakes of the unit first to expose their final tags
+			var builds renderedUnit
+			if err := sq.renderTemplate(ctx, newRenderedUnit(key, k, &config.Unit{Builds: v.Builds, Bakes: v.Bakes}), sq.withBuilds(tv, global), refs, &builds); err != nil {
+				return err
+			}
+
+			unit := builds.unit(key, k)
+
+			var rendered renderedUnit
+			if err := sq.renderTemplate(ctx, newRenderedUnit(key, k, v), sq.withBuilds(tv, global, sq.buildImages(unit.Builds, unit.Bakes)), refs, &rendered); err != nil {
+				return err
+			}
+
+			if ret.Squadrons == nil {
+				ret.Squadrons = config.Map[config.Map[*config.Unit]]{}
+			}
+
+			if ret.Squadrons[key] == nil {
+				ret.Squadrons[key] = config.Map[*config.Unit]{}
+			}
+
+			ret.Squadrons[key][k] = rendered.unit(key, k)
+
+			return nil
+		})
+	}); err != nil {
+		return err
+	}
+
+	out, err := yamlv2.Marshal(ret)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal config")
+	}
+
+	sq.c = ret
+	sq.config = string(out)
+
+	done()
+
+	return nil
+}
+
+// rerenderConfig renders the config again if it references the built images
+func (sq *Squadron) rerenderConfig(ctx context.Context) error {
+	if sq.unrendered == "" || !strings.Contains(sq.unrendered, ".Builds") {
+		return nil
+	}
+
+	var c config.Config
+	if err := yaml.Unmarshal([]byte(sq.unrendered), &c); err != nil {
+		return errors.Wrap(err, "failed to unmarshal config")
+	}
+
+	sq.c = c
+	sq.config = sq.unrendered
+
+	return sq.renderConfig(ctx)
+}
+
+// renderTemplate executes the templates of the marshaled value and unmarshals the result
+func (sq *Squadron) renderTemplate(ctx context.Context, value any, tv templatex.Vars, refs *unitRefs, ret any) error {
+	text, err := yamlv2.Marshal(value)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal config")
+	}
+
+	out, err := templatex.ExecuteFileTemplate(ctx, string(text), tv, true, refs.FuncMap())
+	if err != nil {
+		return errors.Wrap(templatex.LocateError(err, string(text), sq.merged), "failed to execute file template")
+	}
+
+	if err := yaml.Unmarshal(out, ret); err != nil {
+		sq.fail(OperationRender, "failed to unmarshal config", string(out), util.Highlight)
+		return errors.Wrap(err, "failed to unmarshal config")
+	}
+
+	return nil
+}
+
+// bakeBuildTarget returns the bake target name of a unit or global build
+func bakeBuildTarget(squadron, unit, name string) string {
+	if squadron == "" {
+		return "squadron-builds-" + name
+	}
+
+	return strings.Join([]string{"squadron", squadron, unit, "build", name}, "-")
+}
+
+// graphBuild returns the build of the given build graph node, global builds have no squadron and
+// unit
+func (sq *Squadron) graphBuild(id string) (string, string, string, config.Build) {
+	if build, ok := sq.c.Builds[id]; ok {
+		return "", "", id, build
+	}
+
+	key, rest, _ := strings.Cut(id, "/")
+	k, name, _ := strings.Cut(rest, ".")
+
+	return key, k, name, sq.c.Squadrons[key][k].Builds[name]
+}
+
+// mergeFiles conflates the given files into the config and returns the merged yaml
+func (sq *Squadron) mergeFiles(files []string) ([]byte, error) {
+	mergedFiles, err := conflate.FromFiles(files...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to conflate files")
+	}
+
+	fileBytes, err := mergedFiles.MarshalYAML()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal yaml")
+	}
+
+	if err := yaml.Unmarshal(fileBytes, &sq.c); err != nil {
+		sq.fail(OperationMerge, "failed to unmarshal yaml", string(fileBytes), util.Highlight)
+		return nil, errors.Wrap(err, "failed to unmarshal yaml")
+	}
+
+	sq.merged = files
+
+	return fileBytes, nil
+}
+
+// rollback rolls back the release of the unit to the given or previous revision
+func (sq *Squadron) rollback(ctx context.Context, backend helm.ReleaseBackend, key, k string, v *config.Unit, revision string) error {
+	name := sq.getReleaseName(key, k, v)
+
+	namespace, err := sq.Namespace(ctx, key, k, v)
+	if err != nil {
+		return err
+	}
+
+	return backend.Rollback(ctx, name, namespace, revision)
+}
+
+// verify waits for the workload rollouts of the unit and reverts the release if they do not succeed
+func (sq *Squadron) verify(ctx context.Context, backend helm.ReleaseBackend, key, k string, v *config.Unit) (UnitVerification, error) {
+	timeout, err := v.Verify.TimeoutDuration()
+	if err != nil {
+		return UnitVerification{}, err
+	}
+
+	name := sq.getReleaseName(key, k, v)
+
+	namespace, err := sq.Namespace(ctx, key, k, v)
+	if err != nil {
+		return UnitVerification{}, err
+	}
+
+	ret := UnitVerification{
+		Squadron:  key,
+		Unit:      k,
+		Name:      name,
+		Namespace: namespace,
+	}
+
+	status, err := backend.Status(ctx, name, namespace)
+	if err != nil {
+		return ret, err
+	}
+
+	ret.Revision = status.Revision
+
+	manifest, err := backend.Manifest(ctx, name, namespace)
+	if err != nil {
+		return ret, err
+	}
+
+	resources, err := diff.Split(manifest, namespace)
+	if err != nil {
+		return ret, errors.Wrap(err, "failed to parse release manifest")
+	}
+
+	workloads := make([]diff.Resource, 0, len(resources))
+	for _, resource := range resources {
+		switch resource.Kind {
+		case "Deployment", "StatefulSet", "DaemonSet":
+			workloads = append(workloads, resource)
+		}
+	}
+
+	slices.SortFunc(workloads, func(a, b diff.Resource) int {
+		return strings.Compare(a.Key(), b.Key())
+	})
+
+	deadline := time.Now().Add(timeout)
+
+	var rolloutErr error
+
+	for _, workload := range workloads {
+		resource := strings.ToLower(workload.Kind) + "/" + workload.Name
+		ret.Workloads = append(ret.Workloads, resource)
+
+		if rolloutErr != nil {
+			continue
+		}
+
+		remaining := time.Until(deadline).Round(time.Second)
+		if remaining <= 0 {
+			rolloutErr = errors.Errorf("timed out after %s waiting for %s", timeout, resource)
+			continue
+		}
+
+		if out, err := util.NewKubeCommand().Namespace(workload.Namespace).WaitForRollout(resource, remaining.String()).Run(ctx); err != nil {
+			rolloutErr = errors.Errorf("rollout of %s failed: %s", resource, strings.TrimSpace(out))
+		}
+	}
+
+	if rolloutErr == nil {
+		ret.Status = VerificationVerified
+		return ret, nil
+	}
+
+	ret.Status = VerificationFailed
+	ret.Error = rolloutErr.Error()
+
+	if err := ctx.Err(); err != nil {
+		return ret, err
+	}
+
+	if status.Revision <= 1 {
+		ret.Error += " (no previous revision to roll back to)"
+		return ret, nil
+	}
+
+	if err := sq.rollback(ctx, backend, key, k, v, ""); err != nil {
+		ret.Error += fmt.Sprintf(" (rollback failed: %s)", err.Error())
+		return ret, nil
+	}
+
+	ret.Status = VerificationReverted
+
+	return ret, nil
+}
+
+// sortByPriority returns the unit ids sorted by their priority, higher comes first
+func (sq *Squadron) sortByPriority(ids []string) []string {
+	ret := slices.Clone(ids)
+	sort.SliceStable(ret, func(i, j int) bool {
+		iKey, iUnit, _ := strings.Cut(ret[i], "/")
+		jKey, jUnit, _ := strings.Cut(ret[j], "/")
+
+		return sq.c.Squadrons[iKey][iUnit].Priority > sq.c.Squadrons[jKey][jUnit].Priority
+	})
+
+	return ret
 }
 
 func (sq *Squadron) getReleaseName(squadron, unit string, u *config.Unit) string {
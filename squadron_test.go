@@ -8,9 +8,11 @@ import (
 	testingx "github.com/foomo/go/testing"
 	tagx "github.com/foomo/go/testing/tag"
 	"github.com/foomo/squadron"
+	templatex "github.com/foomo/squadron/internal/template"
 	"github.com/foomo/squadron/internal/testutils"
 	"github.com/foomo/squadron/internal/util"
 	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -64,6 +66,10 @@ func TestConfigSimpleSnapshot(t *testing.T) {
 			name:  "bake",
 			files: []string{"squadron.yaml"},
 		},
+		{
+			name:  "templates",
+			files: []string{"squadron.yaml"},
+		},
 	}
 
 	for _, test := range tests {
@@ -107,9 +113,9 @@ func runTestConfig(t *testing.T, name string, files []string, squadronName strin
 	})
 
 	t.Run("template", func(tt *testing.T) {
-		out, err := sq.Template(ctx, nil, 1)
+		out, err := sq.Template(ctx, squadron.HelmOptions{}, 1)
 		require.NoError(t, err)
-		testutils.Snapshot(t, path.Join("testdata", name, "snapshop-template.yaml"), out)
+		testutils.Snapshot(t, path.Join("testdata", name, "snapshop-template.yaml"), out.String())
 	})
 
 	t.Run("bakefile", func(tt *testing.T) {
@@ -133,3 +139,37 @@ func runTestConfig(t *testing.T, name string, files []string, squadronName strin
 		testutils.Snapshot(t, path.Join("testdata", name, "snapshop-bakefile.hcl"), string(bakefile))
 	})
 }
+
+func TestSquadron_RenderConfig_sourceError(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+	t.Setenv("PROJECT_ROOT", ".")
+
+	var cwd string
+
+	ctx := t.Context()
+	require.NoError(t, util.ValidatePath(".", &cwd))
+
+	files := []string{
+		path.Join("testdata", "template-error", "squadron.yaml"),
+		path.Join("testdata", "template-error", "squadron.override.yaml"),
+	}
+
+	sq := squadron.New(cwd, "default", files)
+	require.NoError(t, sq.MergeConfigFiles(ctx))
+
+	err := sq.RenderConfig(ctx)
+	require.Error(t, err)
+
+	var sourceErr *templatex.SourceError
+	require.ErrorAs(t, err, &sourceErr)
+	assert.Equal(t, files[1], sourceErr.Filename)
+	assert.Equal(t, 7, sourceErr.Line)
+	assert.Equal(t, 16, sourceErr.Column)
+	assert.Equal(t, `at <.Vars.missing>: map has no entry for key "missing"`, sourceErr.Message)
+	assert.Equal(t, strings.Join([]string{
+		" 5 |         image:",
+		" 6 |           repository: example/backend",
+		" 7 |           tag: <% .Vars.missing %>",
+		"   |                ^",
+	}, "\n"), sourceErr.Snippet)
+}
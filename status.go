@@ -1,8 +1,36 @@
 package squadron
 
+import (
+	"fmt"
+	"strings"
+)
+
 type Status struct {
 	User     string `json:"user,omitempty"`
 	Branch   string `json:"branch,omitempty"`
 	Commit   string `json:"commit,omitempty"`
 	Squadron string `json:"squadron,omitempty"`
 }
+
+// String returns a human readable description of the holder
+func (s Status) String() string {
+	ret := s.User
+	if ret == "" {
+		ret = "unknown"
+	}
+
+	var details []string
+	if s.Branch != "" {
+		details = append(details, "branch: "+s.Branch)
+	}
+
+	if s.Commit != "" {
+		details = append(details, "commit: "+s.Commit)
+	}
+
+	if len(details) > 0 {
+		ret += fmt.Sprintf(" (%s)", strings.Join(details, ", "))
+	}
+
+	return ret
+}
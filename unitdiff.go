@@ -0,0 +1,42 @@
+package squadron
+
+type UnitDiff struct {
+	// Squadron name
+	Squadron string `json:"squadron" yaml:"squadron"`
+	// Unit name
+	Unit string `json:"unit" yaml:"unit"`
+	// Helm release name
+	Name string `json:"name" yaml:"name"`
+	// Helm release namespace
+	Namespace string `json:"namespace" yaml:"namespace"`
+	// Unified diff between the installed and the local manifest
+	Diff string `json:"diff" yaml:"diff"`
+	// Changed resources
+	Resources []ResourceDiff `json:"resources,omitempty" yaml:"resources,omitempty"`
+}
+
+type ResourceDiff struct {
+	// Resource kind
+	Kind string `json:"kind" yaml:"kind"`
+	// Resource namespace
+	Namespace string `json:"namespace" yaml:"namespace"`
+	// Resource name
+	Name string `json:"name" yaml:"name"`
+	// Change action (added, removed, changed)
+	Action string `json:"action" yaml:"action"`
+	// Unified diff of the resource
+	Diff string `json:"diff" yaml:"diff"`
+}
+
+// Count returns the number of resources with the given action
+func (d UnitDiff) Count(action string) int {
+	var ret int
+
+	for _, resource := range d.Resources {
+		if resource.Action == action {
+			ret++
+		}
+	}
+
+	return ret
+}
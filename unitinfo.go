@@ -0,0 +1,24 @@
+package squadron
+
+type UnitInfo struct {
+	// Squadron name
+	Squadron string `json:"squadron" yaml:"squadron"`
+	// Unit name
+	Unit string `json:"unit" yaml:"unit"`
+	// Helm release name
+	Name string `json:"name" yaml:"name"`
+	// Chart reference
+	Chart string `json:"chart,omitempty" yaml:"chart,omitempty"`
+	// List of tags
+	Tags []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	// Installation priority
+	Priority int `json:"priority,omitempty" yaml:"priority,omitempty"`
+	// List of units this unit depends on
+	DependsOn []string `json:"dependsOn,omitempty" yaml:"dependsOn,omitempty"`
+	// Map of build names and their image tags
+	Builds map[string][]string `json:"builds,omitempty" yaml:"builds,omitempty"`
+	// Map of build names and their dependencies
+	BuildDependencies map[string][]string `json:"buildDependencies,omitempty" yaml:"buildDependencies,omitempty"`
+	// Map of bake names and their image tags
+	Bakes map[string][]string `json:"bakes,omitempty" yaml:"bakes,omitempty"`
+}
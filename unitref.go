@@ -0,0 +1,295 @@
+package squadron
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/foomo/squadron/config"
+	templatex "github.com/foomo/squadron/internal/template"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// unitRefs resolves references to other units while rendering the config
+type unitRefs struct {
+	sq             *Squadron
+	ctx            context.Context //nolint:containedctx
+	vars           templatex.Vars
+	squadrons      map[string]any
+	builds         map[string]any
+	errorOnMissing bool
+	// references currently being resolved
+	stack []string
+	// resolved references
+	cache map[string]any
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private methods
+// ------------------------------------------------------------------------------------------------
+
+func (sq *Squadron) newUnitRefs(ctx context.Context, vars templatex.Vars, data map[string]any, errorOnMissing bool) *unitRefs {
+	ret := &unitRefs{
+		sq:             sq,
+		ctx:            ctx,
+		vars:           vars,
+		errorOnMissing: errorOnMissing,
+		cache:          map[string]any{},
+	}
+	ret.squadrons, _ = data["squadron"].(map[string]any)
+	ret.builds, _ = data["builds"].(map[string]any)
+
+	// units removed by filtering may still be referenced
+	if sq.unfiltered != nil {
+		ret.squadrons = sq.unfiltered
+	}
+
+	return ret
+}
+
+// FuncMap returns the unit reference template functions
+func (r *unitRefs) FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"unitRelease":   r.release,
+		"unitNamespace": r.namespace,
+		"unitValue":     r.value,
+		"unitBuildHash": r.buildHash,
+	}
+}
+
+// release returns the resolved release name of the unit
+func (r *unitRefs) release(squadron, unit string) (string, error) {
+	value, err := r.resolve(squadron+"/"+unit+".name", func() (any, error) {
+		name, err := r.field(squadron, unit, "name")
+		if err != nil {
+			return nil, err
+		}
+
+		return r.sq.getReleaseName(squadron, unit, &config.Unit{Name: name}), nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return value.(string), nil //nolint:forcetypeassert
+}
+
+// namespace returns the resolved namespace of the unit
+func (r *unitRefs) namespace(squadron, unit string) (string, error) {
+	value, err := r.resolve(squadron+"/"+unit+".namespace", func() (any, error) {
+		namespace, err := r.field(squadron, unit, "namespace")
+		if err != nil {
+			return nil, err
+		}
+
+		return r.sq.Namespace(r.ctx, squadron, unit, &config.Unit{Namespace: namespace})
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return value.(string), nil //nolint:forcetypeassert
+}
+
+// value returns the rendered chart value of the unit at the given path (format: a.b.0.c)
+func (r *unitRefs) value(squadron, unit, path string) (any, error) {
+	return r.resolve(squadron+"/"+unit+".values."+path, func() (any, error) {
+		u, err := r.unit(squadron, unit)
+		if err != nil {
+			return nil, err
+		}
+
+		value := lookup(u["values"], strings.Split(path, "."))
+		if value == nil {
+			return nil, errors.Errorf("unknown value `%s` of unit `%s/%s`", path, squadron, unit)
+		}
+
+		return r.render(value)
+	})
+}
+
+// buildHash returns the content hash of the unit build
+func (r *unitRefs) buildHash(squadron, unit, build string) (string, error) {
+	value, err := r.resolve(squadron+"/"+unit+".builds."+build+".hash", func() (any, error) {
+		u, err := r.unit(squadron, unit)
+		if err != nil {
+			return nil, err
+		}
+
+		builds, _ := u["builds"].(map[string]any)
+
+		value, ok := builds[build]
+		if !ok {
+			return nil, errors.Errorf("unknown build `%s` of unit `%s/%s`", build, squadron, unit)
+		}
+
+		return r.hash(value)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return value.(string), nil //nolint:forcetypeassert
+}
+
+// hash returns the content hash of the given build including the hashes of its dependencies
+func (r *unitRefs) hash(value any) (string, error) {
+	m, _ := value.(map[string]any)
+
+	// only render the hashed fields as others like tags may reference the hash
+	fields := map[string]any{}
+
+	for _, key := range []string{"context", "file", "buildArg", "platform", "target", "dependencies"} {
+		if item, ok := m[key]; ok {
+			rendered, err := r.render(item)
+			if err != nil {
+				return "", err
+			}
+
+			fields[key] = rendered
+		}
+	}
+
+	out, err := yaml.Marshal(fields)
+	if err != nil {
+		return "", err
+	}
+
+	var build config.Build
+	if err := yaml.Unmarshal(out, &build); err != nil {
+		return "", errors.Wrap(err, "invalid build")
+	}
+
+	dependencies := make([]string, 0, len(build.Dependencies))
+
+	for _, name := range build.Dependencies {
+		value, err := r.resolve("builds."+name+".hash", func() (any, error) {
+			value, ok := r.builds[name]
+			if !ok {
+				return nil, errors.Errorf("missing build dependency `%s`", name)
+			}
+
+			return r.hash(value)
+		})
+		if err != nil {
+			return "", err
+		}
+
+		dependencies = append(dependencies, value.(string)) //nolint:forcetypeassert
+	}
+
+	return build.Hash(dependencies...)
+}
+
+// resolve returns the cached value of the reference or resolves it, detecting reference cycles
+func (r *unitRefs) resolve(ref string, fn func() (any, error)) (any, error) {
+	if value, ok := r.cache[ref]; ok {
+		return value, nil
+	}
+
+	if slices.Contains(r.stack, ref) {
+		return nil, errors.Errorf("unit reference cycle `%s`", strings.Join(append(r.stack, ref), " -> "))
+	}
+
+	r.stack = append(r.stack, ref)
+	defer func() {
+		r.stack = r.stack[:len(r.stack)-1]
+	}()
+
+	value, err := fn()
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache[ref] = value
+
+	return value, nil
+}
+
+func (r *unitRefs) unit(squadron, unit string) (map[string]any, error) {
+	units, _ := r.squadrons[squadron].(map[string]any)
+
+	value, ok := units[unit]
+	if !ok {
+		return nil, errors.Errorf("unknown unit `%s/%s`", squadron, unit)
+	}
+
+	ret, _ := value.(map[string]any)
+
+	return ret, nil
+}
+
+// field returns the rendered string field of the unit
+func (r *unitRefs) field(squadron, unit, name string) (string, error) {
+	u, err := r.unit(squadron, unit)
+	if err != nil {
+		return "", err
+	}
+
+	value, err := r.render(u[name])
+	if err != nil {
+		return "", err
+	}
+
+	if value == nil {
+		return "", nil
+	}
+
+	ret, ok := value.(string)
+	if !ok {
+		return "", errors.Errorf("invalid %s of unit `%s/%s`", name, squadron, unit)
+	}
+
+	return ret, nil
+}
+
+// render executes the templates of all strings of the value
+func (r *unitRefs) render(value any) (any, error) {
+	switch v := value.(type) {
+	case string:
+		if !strings.Contains(v, "<%") {
+			return v, nil
+		}
+
+		out, err := templatex.ExecuteFileTemplate(r.ctx, v, r.vars, r.errorOnMissing, r.FuncMap())
+		if err != nil {
+			return nil, err
+		}
+
+		// keep the type the value would have in the rendered yaml
+		var ret any
+		if err := yaml.Unmarshal(out, &ret); err != nil {
+			return string(out), nil //nolint:nilerr
+		}
+
+		return ret, nil
+	case map[string]any:
+		ret := make(map[string]any, len(v))
+		for key, item := range v {
+			value, err := r.render(item)
+			if err != nil {
+				return nil, err
+			}
+
+			ret[key] = value
+		}
+
+		return ret, nil
+	case []any:
+		ret := make([]any, len(v))
+		for i, item := range v {
+			value, err := r.render(item)
+			if err != nil {
+				return nil, err
+			}
+
+			ret[i] = value
+		}
+
+		return ret, nil
+	default:
+		return value, nil
+	}
+}
@@ -0,0 +1,82 @@
+package squadron_test
+
+import (
+	"path"
+	"testing"
+
+	testingx "github.com/foomo/go/testing"
+	tagx "github.com/foomo/go/testing/tag"
+	"github.com/foomo/squadron"
+	"github.com/foomo/squadron/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSquadron_RenderConfig_unitRefs(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+	t.Setenv("PROJECT_ROOT", ".")
+
+	var cwd string
+
+	ctx := t.Context()
+	require.NoError(t, util.ValidatePath(".", &cwd))
+
+	t.Run("resolved", func(t *testing.T) {
+		sq := squadron.New(cwd, "squadron-{{.Squadron}}", []string{path.Join("testdata", "unitrefs", "squadron.yaml")})
+		require.NoError(t, sq.MergeConfigFiles(ctx))
+		require.NoError(t, sq.RenderConfig(ctx))
+
+		values := sq.Config().Squadrons["checkout"]["frontend"].Values
+		assert.Equal(t, map[string]any{"host": "checkout-api.checkout-dev.svc", "port": 8080}, values["backend"])
+		assert.Equal(t, "checkout-frontend/squadron-checkout", values["frontend"])
+	})
+
+	t.Run("filtered", func(t *testing.T) {
+		sq := squadron.New(cwd, "squadron-{{.Squadron}}", []string{path.Join("testdata", "unitrefs", "squadron.yaml")})
+		require.NoError(t, sq.MergeConfigFiles(ctx))
+		require.NoError(t, sq.FilterConfig(ctx, "checkout", []string{"frontend"}, nil))
+		require.NoError(t, sq.RenderConfig(ctx))
+
+		assert.NotContains(t, sq.Config().Squadrons["checkout"], "backend")
+
+		values := sq.Config().Squadrons["checkout"]["frontend"].Values
+		assert.Equal(t, map[string]any{"host": "checkout-api.checkout-dev.svc", "port": 8080}, values["backend"])
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		sq := squadron.New(cwd, "default", []string{path.Join("testdata", "unitrefs-cycle", "squadron.yaml")})
+		require.NoError(t, sq.MergeConfigFiles(ctx))
+
+		err := sq.RenderConfig(ctx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unit reference cycle `checkout/frontend.values.b -> checkout/backend.values.a -> checkout/frontend.values.b`")
+	})
+}
+
+func TestSquadron_RenderConfig_unitBuildHash(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+	t.Setenv("PROJECT_ROOT", ".")
+
+	var cwd string
+
+	ctx := t.Context()
+	require.NoError(t, util.ValidatePath(".", &cwd))
+
+	sq := squadron.New(cwd, "default", []string{path.Join("testdata", "buildhash", "squadron.yaml")})
+	require.NoError(t, sq.MergeConfigFiles(ctx))
+	require.NoError(t, sq.RenderConfig(ctx))
+
+	c := sq.Config()
+	base := c.Builds["base"]
+
+	dependency, err := base.Hash()
+	require.NoError(t, err)
+
+	build := c.Squadrons["site"]["backend"].Builds["default"]
+
+	hash, err := build.Hash(dependency)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"backend:" + hash[:12]}, build.Tag)
+	assert.Equal(t, map[string]any{"tag": hash[:12]}, c.Squadrons["site"]["backend"].Values["image"])
+}
@@ -0,0 +1,28 @@
+package squadron
+
+type UnitStatus struct {
+	// Squadron name
+	Squadron string `json:"squadron" yaml:"squadron"`
+	// Unit name
+	Unit string `json:"unit" yaml:"unit"`
+	// Helm release name
+	Name string `json:"name" yaml:"name"`
+	// Helm release namespace
+	Namespace string `json:"namespace" yaml:"namespace"`
+	// Helm release revision
+	Revision int `json:"revision" yaml:"revision"`
+	// Helm release status
+	Status string `json:"status" yaml:"status"`
+	// User that deployed the release
+	User string `json:"user,omitempty" yaml:"user,omitempty"`
+	// Git branch or tag the release was deployed from
+	Branch string `json:"branch,omitempty" yaml:"branch,omitempty"`
+	// Git commit the release was deployed from
+	Commit string `json:"commit,omitempty" yaml:"commit,omitempty"`
+	// Squadron version used to deploy the release
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	// Last deployment time in RFC3339 format
+	LastDeployed string `json:"lastDeployed,omitempty" yaml:"lastDeployed,omitempty"`
+	// Helm release description if not deployed by squadron
+	Notes string `json:"notes,omitempty" yaml:"notes,omitempty"`
+}
@@ -0,0 +1,30 @@
+package squadron
+
+import (
+	"strings"
+)
+
+type UnitTemplate struct {
+	// Squadron name
+	Squadron string `json:"squadron" yaml:"squadron"`
+	// Unit name
+	Unit string `json:"unit" yaml:"unit"`
+	// Helm release name
+	Name string `json:"name" yaml:"name"`
+	// Helm release namespace
+	Namespace string `json:"namespace" yaml:"namespace"`
+	// Rendered chart manifest
+	Manifest string `json:"manifest" yaml:"manifest"`
+}
+
+type UnitTemplates []UnitTemplate
+
+// String returns all manifests concatenated
+func (t UnitTemplates) String() string {
+	var ret strings.Builder
+	for _, item := range t {
+		ret.WriteString(item.Manifest)
+	}
+
+	return ret.String()
+}
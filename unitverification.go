@@ -0,0 +1,78 @@
+package squadron
+
+import (
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	VerificationVerified = "verified"
+	VerificationFailed   = "failed"
+	VerificationReverted = "reverted"
+)
+
+type UnitVerification struct {
+	// Squadron name
+	Squadron string `json:"squadron" yaml:"squadron"`
+	// Unit name
+	Unit string `json:"unit" yaml:"unit"`
+	// Helm release name
+	Name string `json:"name" yaml:"name"`
+	// Helm release namespace
+	Namespace string `json:"namespace" yaml:"namespace"`
+	// Verified helm release revision
+	Revision int `json:"revision" yaml:"revision"`
+	// Verification result (verified, failed, reverted)
+	Status string `json:"status" yaml:"status"`
+	// Verified workloads (format: kind/name)
+	Workloads []string `json:"workloads,omitempty" yaml:"workloads,omitempty"`
+	// Verification or rollback error
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// unitVerifications collects the verifications of concurrently verified units
+type unitVerifications struct {
+	m     sync.Mutex
+	items []UnitVerification
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private methods
+// ------------------------------------------------------------------------------------------------
+
+func (v *unitVerifications) add(item UnitVerification) {
+	v.m.Lock()
+	defer v.m.Unlock()
+
+	v.items = append(v.items, item)
+}
+
+// failed returns the number of units of the given ids (format: squadron/unit) that did not verify
+func (v *unitVerifications) failed(ids []string) int {
+	v.m.Lock()
+	defer v.m.Unlock()
+
+	var ret int
+
+	for _, item := range v.items {
+		if item.Status != VerificationVerified && slices.Contains(ids, item.Squadron+"/"+item.Unit) {
+			ret++
+		}
+	}
+
+	return ret
+}
+
+// sorted returns the verifications sorted by squadron and unit
+func (v *unitVerifications) sorted() []UnitVerification {
+	v.m.Lock()
+	defer v.m.Unlock()
+
+	ret := slices.Clone(v.items)
+	slices.SortFunc(ret, func(a, b UnitVerification) int {
+		return strings.Compare(a.Squadron+"/"+a.Unit, b.Squadron+"/"+b.Unit)
+	})
+
+	return ret
+}
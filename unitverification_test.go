@@ -0,0 +1,139 @@
+package squadron_test
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	testingx "github.com/foomo/go/testing"
+	tagx "github.com/foomo/go/testing/tag"
+	"github.com/foomo/squadron"
+	"github.com/foomo/squadron/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeHelm reports revision 2 of a release with a single deployment and records upgrades and
+// rollbacks
+const fakeHelm = `#!/bin/sh
+case "$1" in
+upgrade)
+  touch "$FAKE_STORE/upgrade-$2"
+  ;;
+status)
+  echo '{"name":"'$2'","version":2,"namespace":"demo","info":{"status":"deployed"}}'
+  ;;
+get)
+  printf 'apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: frontend\n'
+  ;;
+rollback)
+  touch "$FAKE_STORE/rollback-$2"
+  ;;
+esac
+`
+
+// fakeRollout fails the rollout status if `FAKE_ROLLOUT_FAIL` is set
+const fakeRollout = `#!/bin/sh
+echo "$@" >> "$FAKE_STORE/kubectl"
+if [ -n "$FAKE_ROLLOUT_FAIL" ]; then
+  echo 'error: timed out waiting for the condition' >&2
+  exit 1
+fi
+echo 'deployment "frontend" successfully rolled out'
+`
+
+func TestSquadron_Verify(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+	t.Setenv("PROJECT_ROOT", ".")
+
+	bin := t.TempDir()
+	require.NoError(t, os.WriteFile(path.Join(bin, "helm"), []byte(fakeHelm), 0700))       //nolint:gosec
+	require.NoError(t, os.WriteFile(path.Join(bin, "kubectl"), []byte(fakeRollout), 0700)) //nolint:gosec
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("SQUADRON_HELM_BACKEND", "cli")
+
+	var cwd string
+
+	ctx := t.Context()
+	require.NoError(t, util.ValidatePath(".", &cwd))
+
+	sq := squadron.New(cwd, "demo", []string{path.Join("testdata", "simple", "squadron.yaml")})
+	require.NoError(t, sq.MergeConfigFiles(ctx))
+
+	t.Run("verified", func(t *testing.T) {
+		store := t.TempDir()
+		t.Setenv("FAKE_STORE", store)
+
+		items, err := sq.Verify(ctx, squadron.HelmOptions{}, 1)
+		require.NoError(t, err)
+		require.Len(t, items, 1)
+		assert.Equal(t, squadron.VerificationVerified, items[0].Status)
+		assert.Equal(t, 2, items[0].Revision)
+		assert.Equal(t, []string{"deployment/frontend"}, items[0].Workloads)
+
+		args, err := os.ReadFile(path.Join(store, "kubectl"))
+		require.NoError(t, err)
+		assert.Contains(t, string(args), "--namespace demo rollout status deployment/frontend -w --timeout 5m0s")
+		assert.NoFileExists(t, path.Join(store, "rollback-storefinder-frontend"))
+	})
+
+	t.Run("reverted", func(t *testing.T) {
+		store := t.TempDir()
+		t.Setenv("FAKE_STORE", store)
+		t.Setenv("FAKE_ROLLOUT_FAIL", "1")
+
+		items, err := sq.Verify(ctx, squadron.HelmOptions{}, 1)
+		require.NoError(t, err)
+		require.Len(t, items, 1)
+		assert.Equal(t, squadron.VerificationReverted, items[0].Status)
+		assert.Contains(t, items[0].Error, "rollout of deployment/frontend failed: error: timed out waiting for the condition")
+		assert.FileExists(t, path.Join(store, "rollback-storefinder-frontend"))
+	})
+}
+
+func TestSquadron_UpAndVerify(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+	t.Setenv("PROJECT_ROOT", ".")
+
+	bin := t.TempDir()
+	require.NoError(t, os.WriteFile(path.Join(bin, "helm"), []byte(fakeHelm), 0700))       //nolint:gosec
+	require.NoError(t, os.WriteFile(path.Join(bin, "kubectl"), []byte(fakeRollout), 0700)) //nolint:gosec
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("SQUADRON_HELM_BACKEND", "cli")
+
+	var cwd string
+
+	ctx := t.Context()
+	require.NoError(t, util.ValidatePath(".", &cwd))
+
+	sq := squadron.New(cwd, "demo", []string{path.Join("testdata", "verify", "squadron.yaml")}, squadron.WithObserver(squadron.ObserverFunc(func(event squadron.Event) {})))
+	require.NoError(t, sq.MergeConfigFiles(ctx))
+	require.NoError(t, sq.RenderConfig(ctx))
+
+	t.Run("verified", func(t *testing.T) {
+		store := t.TempDir()
+		t.Setenv("FAKE_STORE", store)
+
+		_, items, err := sq.UpAndVerify(ctx, squadron.HelmOptions{}, squadron.Status{}, 1)
+		require.NoError(t, err)
+		require.Len(t, items, 2)
+		assert.Equal(t, squadron.VerificationVerified, items[0].Status)
+		assert.Equal(t, squadron.VerificationVerified, items[1].Status)
+		assert.FileExists(t, path.Join(store, "upgrade-storefinder-frontend"))
+	})
+
+	t.Run("reverted", func(t *testing.T) {
+		store := t.TempDir()
+		t.Setenv("FAKE_STORE", store)
+		t.Setenv("FAKE_ROLLOUT_FAIL", "1")
+
+		_, items, err := sq.UpAndVerify(ctx, squadron.HelmOptions{}, squadron.Status{}, 1)
+		require.EqualError(t, err, "1 of 1 units failed verification")
+		require.Len(t, items, 1)
+		assert.Equal(t, "backend", items[0].Unit)
+		assert.Equal(t, squadron.VerificationReverted, items[0].Status)
+		assert.FileExists(t, path.Join(store, "rollback-storefinder-backend"))
+		// the dependent unit must not be rolled out on top of the reverted dependency
+		assert.NoFileExists(t, path.Join(store, "upgrade-storefinder-frontend"))
+	})
+}
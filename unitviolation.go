@@ -0,0 +1,36 @@
+package squadron
+
+import (
+	"strings"
+)
+
+type UnitViolation struct {
+	// Squadron name, empty for config violations outside of units
+	Squadron string `json:"squadron,omitempty" yaml:"squadron,omitempty"`
+	// Unit name, empty for config violations outside of units
+	Unit string `json:"unit,omitempty" yaml:"unit,omitempty"`
+	// Schema the value was validated against
+	Schema string `json:"schema" yaml:"schema"`
+	// JSON pointer to the invalid value, relative to the unit or the config
+	Path string `json:"path" yaml:"path"`
+	// Violation message
+	Message string `json:"message" yaml:"message"`
+}
+
+// String returns the violation as `squadron/unit/path: message`
+func (v UnitViolation) String() string {
+	var ret strings.Builder
+	if v.Squadron != "" {
+		ret.WriteString(v.Squadron + "/" + v.Unit)
+	}
+
+	if v.Path != "" {
+		ret.WriteString(v.Path)
+	} else if ret.Len() == 0 {
+		ret.WriteString("/")
+	}
+
+	ret.WriteString(": " + v.Message)
+
+	return ret.String()
+}
@@ -0,0 +1,240 @@
+package squadron
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/foomo/squadron/config"
+	"github.com/foomo/squadron/internal/helm"
+	"github.com/foomo/squadron/internal/jsonschema"
+	ptermx "github.com/foomo/squadron/internal/pterm"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaURL is the id of the embedded squadron config schema
+const SchemaURL = "https://raw.githubusercontent.com/foomo/squadron/refs/heads/main/squadron.schema.json"
+
+//go:embed squadron.schema.json
+var schema []byte
+
+// ------------------------------------------------------------------------------------------------
+// ~ Public methods
+// ------------------------------------------------------------------------------------------------
+
+// Validate validates the merged config files against the squadron schema and the rendered values of
+// all units against the values schemas of their charts and subcharts
+func (sq *Squadron) Validate(ctx context.Context, parallel int) ([]UnitViolation, error) {
+	ret, err := sq.validateConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	var m sync.Mutex
+
+	wg, wgCtx := errgroup.WithContext(ctx)
+	wg.SetLimit(parallel)
+
+	printer := sq.newProgress(OperationValidate)
+	defer printer.Stop()
+
+	_ = sq.Config().Squadrons.Iterate(wgCtx, func(ctx context.Context, key string, value config.Map[*config.Unit]) error {
+		return value.Iterate(ctx, func(ctx context.Context, k string, v *config.Unit) error {
+			if v.Chart.Name == "" {
+				return nil
+			}
+
+			wg.Go(func() error {
+				spinner := printer.NewTask(key, k, "", fmt.Sprintf("🔍 | %s/%s", key, k))
+				spinner.Start()
+				spinner.Play()
+
+				ctx := ptermx.ContextWithSpinner(ctx, spinner)
+				if err := ctx.Err(); err != nil {
+					spinner.Warning(err.Error())
+					return err
+				}
+
+				items, err := sq.validateUnit(ctx, key, k, v)
+				if err != nil {
+					spinner.Fail(err.Error())
+					return errors.Wrapf(err, "failed to validate %s/%s", key, k)
+				}
+
+				m.Lock()
+				ret = append(ret, items...)
+				m.Unlock()
+
+				if len(items) > 0 {
+					spinner.Fail(fmt.Sprintf("%d violations", len(items)))
+				} else {
+					spinner.Success()
+				}
+
+				return nil
+			})
+
+			return nil
+		})
+	})
+
+	if err := wg.Wait(); err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(ret, func(a, b UnitViolation) int {
+		return strings.Compare(a.Squadron+"/"+a.Unit, b.Squadron+"/"+b.Unit)
+	})
+
+	return ret, nil
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private methods
+// ------------------------------------------------------------------------------------------------
+
+// validateConfig validates the merged config files against the embedded squadron schema, skipping
+// values that are still templates and units that have been filtered
+func (sq *Squadron) validateConfig() ([]UnitViolation, error) {
+	var doc any
+	if err := yaml.Unmarshal(sq.source, &doc); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal config")
+	}
+
+	items, err := jsonschema.Validate(SchemaURL, schema, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []UnitViolation
+
+	for _, item := range items {
+		tokens := pointerTokens(item.Path)
+
+		if value, ok := lookup(doc, tokens).(string); ok && strings.Contains(value, "<%") {
+			continue
+		}
+
+		violation := UnitViolation{
+			Schema:  SchemaURL,
+			Path:    item.Path,
+			Message: item.Message,
+		}
+
+		if len(tokens) >= 3 && tokens[0] == "squadron" {
+			if _, ok := sq.c.Squadrons[tokens[1]][tokens[2]]; !ok {
+				continue
+			}
+
+			violation.Squadron = tokens[1]
+			violation.Unit = tokens[2]
+			violation.Path = jsonschema.Pointer(tokens[3:]...)
+		}
+
+		ret = append(ret, violation)
+	}
+
+	return ret, nil
+}
+
+// validateUnit validates the rendered values of the unit against its chart schemas
+func (sq *Squadron) validateUnit(ctx context.Context, key, k string, v *config.Unit) ([]UnitViolation, error) {
+	name := sq.getReleaseName(key, k, v)
+
+	namespace, err := sq.Namespace(ctx, key, k, v)
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := sq.release(key, k, v, name, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	schemas, err := helm.NewSDKBackend().ValuesSchemas(ctx, release)
+	if err != nil {
+		return nil, err
+	}
+
+	// an explicit schema overrides the schema of the chart
+	if v.Chart.Schema != "" {
+		if schemas[0].Schema, err = jsonschema.Load(ctx, v.Chart.Schema); err != nil {
+			return nil, errors.Wrapf(err, "failed to load schema `%s`", v.Chart.Schema)
+		}
+	}
+
+	var ret []UnitViolation
+
+	for i, item := range schemas {
+		if len(item.Schema) == 0 {
+			continue
+		}
+
+		url := v.Chart.Schema
+		if i > 0 || url == "" {
+			url = item.Chart + "/values.schema.json"
+		}
+
+		violations, err := jsonschema.Validate("file:///"+url, item.Schema, item.Values)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, violation := range violations {
+			ret = append(ret, UnitViolation{
+				Squadron: key,
+				Unit:     k,
+				Schema:   url,
+				Path:     "/values" + item.Path + violation.Path,
+				Message:  violation.Message,
+			})
+		}
+	}
+
+	return ret, nil
+}
+
+// ------------------------------------------------------------------------------------------------
+// ~ Private functions
+// ------------------------------------------------------------------------------------------------
+
+// pointerTokens returns the unescaped tokens of the JSON pointer
+func pointerTokens(pointer string) []string {
+	if pointer == "" {
+		return nil
+	}
+
+	ret := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i, token := range ret {
+		ret[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+
+	return ret
+}
+
+// lookup returns the value at the given tokens
+func lookup(doc any, tokens []string) any {
+	for _, token := range tokens {
+		switch value := doc.(type) {
+		case map[string]any:
+			doc = value[token]
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(value) {
+				return nil
+			}
+
+			doc = value[i]
+		default:
+			return nil
+		}
+	}
+
+	return doc
+}
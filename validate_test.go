@@ -0,0 +1,64 @@
+package squadron_test
+
+import (
+	"path"
+	"testing"
+
+	testingx "github.com/foomo/go/testing"
+	tagx "github.com/foomo/go/testing/tag"
+	"github.com/foomo/squadron"
+	"github.com/foomo/squadron/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSquadron_Validate(t *testing.T) {
+	testingx.Tags(t, tagx.Short)
+	t.Setenv("PROJECT_ROOT", ".")
+
+	var cwd string
+
+	ctx := t.Context()
+	require.NoError(t, util.ValidatePath(".", &cwd))
+
+	sq := squadron.New(cwd, "default", []string{path.Join("testdata", "validate", "squadron.yaml")})
+	require.NoError(t, sq.MergeConfigFiles(ctx))
+	require.NoError(t, sq.RenderConfig(ctx))
+
+	t.Run("violations", func(t *testing.T) {
+		items, err := sq.Validate(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []squadron.UnitViolation{
+			{
+				Squadron: "storefinder",
+				Unit:     "backend",
+				Schema:   squadron.SchemaURL,
+				Path:     "",
+				Message:  "additional properties 'tag' not allowed",
+			},
+			{
+				Squadron: "storefinder",
+				Unit:     "backend",
+				Schema:   "testdata/validate/chart/values.schema.json",
+				Path:     "/values",
+				Message:  "additional properties 'replicaCount' not allowed",
+			},
+			{
+				Squadron: "storefinder",
+				Unit:     "backend",
+				Schema:   "testdata/validate/chart/values.schema.json",
+				Path:     "/values/image/tag",
+				Message:  "got number, want string",
+			},
+		}, items)
+		assert.Equal(t, "storefinder/backend/values/image/tag: got number, want string", items[2].String())
+	})
+
+	t.Run("filtered", func(t *testing.T) {
+		require.NoError(t, sq.FilterConfig(ctx, "storefinder", []string{"frontend"}, nil))
+
+		items, err := sq.Validate(ctx, 1)
+		require.NoError(t, err)
+		assert.Empty(t, items)
+	})
+}